@@ -42,6 +42,14 @@ type ChainConfig struct {
 
 	// EIP3009Version is the EIP-3009 domain parameter "version" (empty for non-EVM chains).
 	EIP3009Version string
+
+	// ChainID is the EIP-155 chain ID used in the EIP-712 domain (zero for non-EVM chains).
+	ChainID uint64
+}
+
+// IsEVM reports whether the chain is an EVM chain.
+func (c ChainConfig) IsEVM() bool {
+	return c.ChainID != 0
 }
 
 // USDCRequirementConfig is the configuration for creating a USDC PaymentRequirement.
@@ -97,6 +105,88 @@ var (
 	}
 )
 
+// EVM chain configurations.
+// EIP-3009 "name" and "version" are the USDC contract's EIP-712 domain parameters.
+var (
+	// BaseMainnet is the configuration for Base mainnet (chain ID 8453).
+	BaseMainnet = ChainConfig{
+		NetworkID:      "base",
+		USDCAddress:    "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		Decimals:       USDCDecimals,
+		EIP3009Name:    "USD Coin",
+		EIP3009Version: "2",
+		ChainID:        8453,
+	}
+
+	// BaseSepolia is the configuration for Base Sepolia testnet (chain ID 84532).
+	BaseSepolia = ChainConfig{
+		NetworkID:      "base-sepolia",
+		USDCAddress:    "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+		Decimals:       USDCDecimals,
+		EIP3009Name:    "USDC",
+		EIP3009Version: "2",
+		ChainID:        84532,
+	}
+
+	// PolygonMainnet is the configuration for Polygon PoS mainnet (chain ID 137).
+	PolygonMainnet = ChainConfig{
+		NetworkID:      "polygon",
+		USDCAddress:    "0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359",
+		Decimals:       USDCDecimals,
+		EIP3009Name:    "USD Coin",
+		EIP3009Version: "2",
+		ChainID:        137,
+	}
+
+	// PolygonAmoy is the configuration for Polygon Amoy testnet (chain ID 80002).
+	PolygonAmoy = ChainConfig{
+		NetworkID:      "polygon-amoy",
+		USDCAddress:    "0x41E94Eb019C0762f9Bfcf9Fb1E58725BfB0e7582",
+		Decimals:       USDCDecimals,
+		EIP3009Name:    "USDC",
+		EIP3009Version: "2",
+		ChainID:        80002,
+	}
+
+	// AvalancheMainnet is the configuration for Avalanche C-Chain mainnet (chain ID 43114).
+	AvalancheMainnet = ChainConfig{
+		NetworkID:      "avalanche",
+		USDCAddress:    "0xB97EF9Ef8734C71904D8002F8b6Bc66Dd9c48a6E",
+		Decimals:       USDCDecimals,
+		EIP3009Name:    "USD Coin",
+		EIP3009Version: "2",
+		ChainID:        43114,
+	}
+
+	// AvalancheFuji is the configuration for Avalanche Fuji testnet (chain ID 43113).
+	AvalancheFuji = ChainConfig{
+		NetworkID:      "avalanche-fuji",
+		USDCAddress:    "0x5425890298aed601595a70AB815c96711a31Bc65",
+		Decimals:       USDCDecimals,
+		EIP3009Name:    "USD Coin",
+		EIP3009Version: "2",
+		ChainID:        43113,
+	}
+)
+
+// chainsByNetwork indexes the built-in chain configurations by network identifier.
+var chainsByNetwork = map[string]ChainConfig{
+	SolanaMainnet.NetworkID:    SolanaMainnet,
+	SolanaDevnet.NetworkID:     SolanaDevnet,
+	BaseMainnet.NetworkID:      BaseMainnet,
+	BaseSepolia.NetworkID:      BaseSepolia,
+	PolygonMainnet.NetworkID:   PolygonMainnet,
+	PolygonAmoy.NetworkID:      PolygonAmoy,
+	AvalancheMainnet.NetworkID: AvalancheMainnet,
+	AvalancheFuji.NetworkID:    AvalancheFuji,
+}
+
+// GetChainConfig returns the built-in chain configuration for a network identifier.
+func GetChainConfig(networkID string) (ChainConfig, bool) {
+	chain, ok := chainsByNetwork[networkID]
+	return chain, ok
+}
+
 // NewUSDCTokenConfig creates a TokenConfig for USDC on the given chain with the specified priority.
 // This is a convenience helper for USDC. For other tokens, construct TokenConfig directly.
 // The returned TokenConfig has:
@@ -193,18 +283,18 @@ func NewUSDCPaymentRequirement(config USDCRequirementConfig) (PaymentRequirement
 // Supported networks:
 //   - solana
 //   - solana-devnet
+//   - base
+//   - base-sepolia
+//   - polygon
+//   - polygon-amoy
+//   - avalanche
+//   - avalanche-fuji
 func ValidateNetwork(networkID string) error {
 	if networkID == "" {
 		return errors.New("networkID: cannot be empty")
 	}
 
-	// Supported networks
-	supportedNetworks := map[string]bool{
-		"solana":        true,
-		"solana-devnet": true,
-	}
-
-	if !supportedNetworks[networkID] {
+	if _, ok := GetChainConfig(networkID); !ok {
 		return fmt.Errorf("networkID: unsupported network '%s'", networkID)
 	}
 
@@ -95,6 +95,48 @@ const (
 
 	// ErrCodeUnsupportedScheme indicates unsupported payment scheme or network.
 	ErrCodeUnsupportedScheme ErrorCode = "UNSUPPORTED_SCHEME"
+
+	// ErrCodeInvalidPayment indicates a malformed payment header or payload.
+	ErrCodeInvalidPayment ErrorCode = "INVALID_PAYMENT"
+
+	// ErrCodeInvalidSignature indicates the payment signature is invalid.
+	ErrCodeInvalidSignature ErrorCode = "INVALID_SIGNATURE"
+
+	// ErrCodeInsufficientFunds indicates the payer cannot cover the payment.
+	ErrCodeInsufficientFunds ErrorCode = "INSUFFICIENT_FUNDS"
+
+	// ErrCodeExpired indicates the payment is outside its validity window.
+	ErrCodeExpired ErrorCode = "EXPIRED"
+
+	// ErrCodeNetworkMismatch indicates the payment is for another network.
+	ErrCodeNetworkMismatch ErrorCode = "NETWORK_MISMATCH"
+
+	// ErrCodeRecipientMismatch indicates the payment is to another recipient.
+	ErrCodeRecipientMismatch ErrorCode = "RECIPIENT_MISMATCH"
+
+	// ErrCodeAmountMismatch indicates the payment amount does not match the price.
+	ErrCodeAmountMismatch ErrorCode = "AMOUNT_MISMATCH"
+
+	// ErrCodePaymentReplayed indicates the payment has already been used.
+	ErrCodePaymentReplayed ErrorCode = "PAYMENT_REPLAYED"
+
+	// ErrCodePaymentInFlight indicates the same payment is being processed.
+	ErrCodePaymentInFlight ErrorCode = "PAYMENT_IN_FLIGHT"
+
+	// ErrCodeVerificationFailed indicates the facilitator rejected the payment for another reason.
+	ErrCodeVerificationFailed ErrorCode = "VERIFICATION_FAILED"
+
+	// ErrCodeSettlementFailed indicates the payment could not be settled.
+	ErrCodeSettlementFailed ErrorCode = "SETTLEMENT_FAILED"
+
+	// ErrCodeFacilitatorError indicates the facilitator rejected the request.
+	ErrCodeFacilitatorError ErrorCode = "FACILITATOR_ERROR"
+
+	// ErrCodeFacilitatorUnavailable indicates the facilitator could not be reached.
+	ErrCodeFacilitatorUnavailable ErrorCode = "FACILITATOR_UNAVAILABLE"
+
+	// ErrCodeInternal indicates a server-side error unrelated to the payment.
+	ErrCodeInternal ErrorCode = "INTERNAL_ERROR"
 )
 
 // Error implements the error interface.
@@ -6,6 +6,7 @@ package main
 import (
 	"errors"
 	"log"
+	"log/slog"
 	"os"
 	"time"
 
@@ -20,19 +21,6 @@ import (
 	"github.com/shopspring/decimal"
 )
 
-// SimpleLogger implements x402 Logger interface with standard log package.
-type SimpleLogger struct{}
-
-// Printf logs informational messages.
-func (*SimpleLogger) Printf(format string, v ...any) {
-	log.Printf(format, v...)
-}
-
-// Errorf logs error messages.
-func (*SimpleLogger) Errorf(format string, v ...any) {
-	log.Printf("[ERROR] "+format, v...)
-}
-
 const (
 	httpStatusOK         = 200
 	httpStatusBadRequest = 400
@@ -176,7 +164,8 @@ func getConfig() (*localx402.Config, error) {
 		PricingStrategy:  pricing.NewFixed(decimal.RequireFromString("0.3")),
 		SchemaProvider:   schemaProvider,   // Add schema provider
 		ResourceProvider: resourceProvider, // Add resource provider
-		Logger:           &SimpleLogger{},  // Enable detailed logging
+		// Enable detailed logging
+		Slog: slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})),
 	}, nil
 }
 
@@ -0,0 +1,57 @@
+package common
+
+import (
+	"context"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+// redeemAccessToken serves a request presenting a valid access token without
+// payment. Rejected tokens fall back to payment, so the client can pay again.
+func (h *Handler) redeemAccessToken(ctx context.Context, resource localx402.Resource) (PaymentResult, bool) {
+	if resource.AccessToken == "" || h.config.AccessTokens == nil {
+		return PaymentResult{}, false
+	}
+
+	claims, next, err := h.middleware.RedeemAccessToken(ctx, resource, resource.AccessToken)
+	if err != nil {
+		if localx402.IsAccessTokenError(err) {
+			h.logger(ctx).Debug("Access token rejected, payment required", "error", err)
+		} else {
+			h.logger(ctx).Error("Failed to redeem access token", "error", err)
+		}
+		return PaymentResult{}, false
+	}
+
+	h.logger(ctx).Debug("Access token accepted", "payer", claims.Subject, "calls", claims.Calls)
+	return PaymentResult{
+		RequirementNeeded: false,
+		Payer:             claims.Subject,
+		AccessToken:       next,
+		AccessClaims:      claims,
+	}, true
+}
+
+// issueAccessToken issues an access token for a settled payment, or returns
+// "" if access tokens are disabled or issuing failed.
+func (h *Handler) issueAccessToken(
+	ctx context.Context,
+	resource localx402.Resource,
+	payer string,
+	settlement *x402.SettlementResponse,
+) string {
+	if h.config.AccessTokens == nil || settlement == nil {
+		return ""
+	}
+	if payer == "" {
+		payer = settlement.Payer
+	}
+
+	token, err := h.middleware.IssueAccessToken(ctx, resource, payer, settlement.Network)
+	if err != nil {
+		h.logger(ctx).Error("Failed to issue access token", "error", err)
+		return ""
+	}
+	return token
+}
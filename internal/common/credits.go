@@ -0,0 +1,103 @@
+package common
+
+import (
+	"context"
+	"errors"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+	"github.com/shopspring/decimal"
+)
+
+// payFromCredits serves a request carrying a credit authorization by debiting
+// the signer's balance in one of the accepted networks and assets. Requests
+// no balance covers, and rejected authorizations, fall back to payment.
+func (h *Handler) payFromCredits(
+	ctx context.Context,
+	resource localx402.Resource,
+	requirements []x402.PaymentRequirement,
+	info *localx402.PaymentInfo,
+) (PaymentResult, bool) {
+	if resource.CreditAuthorization == "" || h.config.Credits == nil || h.middleware.IsTopUp(resource) {
+		return PaymentResult{}, false
+	}
+
+	debit, err := h.middleware.PayFromCredits(ctx, resource, resource.CreditAuthorization, requirements, info.Amount)
+	switch {
+	case errors.Is(err, localx402.ErrInsufficientBalance):
+		h.logger(ctx).Debug("Credit balance insufficient, payment required")
+	case errors.Is(err, localx402.ErrInvalidCreditAuthorization):
+		h.logger(ctx).Info("Credit authorization rejected, payment required", "error", err)
+	case err != nil:
+		h.logger(ctx).Error("Failed to pay from credits", "error", err)
+	}
+	if err != nil {
+		return PaymentResult{}, false
+	}
+
+	info = h.middleware.PaymentInfoFor(debit.Amount, debit.Requirement)
+	result := PaymentResult{
+		RequirementNeeded: false,
+		PaymentInfo:       info,
+		Payer:             debit.Account,
+		creditDebit:       debit,
+		resource:          resource,
+	}
+
+	// Keep the debit pending until the handler has run, if configured
+	if h.config.SettleAfterHandler {
+		result.SettlementPending = true
+		return result, true
+	}
+	return h.commitCredits(ctx, result), true
+}
+
+// commitCredits completes a request paid from credits.
+func (h *Handler) commitCredits(ctx context.Context, result PaymentResult) PaymentResult {
+	debit := result.creditDebit
+	h.logger(ctx).Debug("Paid from credits",
+		"account", debit.Account, "network", debit.Requirement.Network, "balance", debit.Balance.String())
+	h.record(ctx, localx402.RecordCreditDebited, paymentStep{resource: result.resource, info: result.PaymentInfo, payer: debit.Account})
+
+	result.SettlementPending = false
+	result.CreditBalance = &debit.Balance
+	return result
+}
+
+// refundCredits returns the credits debited for a request whose handler failed.
+func (h *Handler) refundCredits(ctx context.Context, debit *localx402.CreditDebit) {
+	balance, err := h.middleware.RefundCredits(ctx, debit)
+	if err != nil {
+		h.logger(ctx).Error("Failed to refund credits", "account", debit.Account, "error", err)
+		return
+	}
+	h.logger(ctx).Info("Credits refunded", "account", debit.Account, "amount", debit.Amount.String(), "balance", balance.String())
+}
+
+// topUpCredits credits a settled payment for a top-up route to the payer's
+// balance in the network and asset of requirement and returns the new
+// balance, or nil if the route is not a top-up route.
+func (h *Handler) topUpCredits(
+	ctx context.Context,
+	resource localx402.Resource,
+	payer string,
+	requirement *x402.PaymentRequirement,
+	info *localx402.PaymentInfo,
+	settlement *x402.SettlementResponse,
+) *decimal.Decimal {
+	if settlement == nil || !h.middleware.IsTopUp(resource) {
+		return nil
+	}
+	if payer == "" {
+		payer = settlement.Payer
+	}
+
+	balance, err := h.middleware.TopUpCredits(ctx, payer, *requirement, info.Amount, settlement.Transaction)
+	if err != nil {
+		// The payment is settled; the ledger has the transaction to credit it later
+		h.logger(ctx).Error("Failed to credit top-up", "payer", payer, "tx", settlement.Transaction, "error", err)
+		return nil
+	}
+	h.logger(ctx).Info("Credits topped up", "payer", payer, "amount", info.Amount.String(), "balance", balance.String())
+	return &balance
+}
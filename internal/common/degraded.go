@@ -0,0 +1,63 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+// unavailable reports whether the facilitator is down, either because its
+// circuit breaker is open or because err says so, and when to retry.
+func (h *Handler) unavailable(err error) (time.Duration, bool) {
+	if retryAfter, down := h.middleware.Unavailable(); down {
+		return retryAfter, true
+	}
+	var openErr *localx402.CircuitOpenError
+	if errors.As(err, &openErr) {
+		return openErr.RetryAfter, true
+	}
+	return 0, localx402.IsUnavailable(err)
+}
+
+// degrade applies Config.Degraded while the facilitator is unavailable: the
+// resource is served free if allowlisted, otherwise the request fails with 503.
+func (h *Handler) degrade(
+	ctx context.Context,
+	resource localx402.Resource,
+	paymentInfo *localx402.PaymentInfo,
+	retryAfter time.Duration,
+	err error,
+) PaymentResult {
+	if h.config.Degraded.ServesFree(resource) {
+		h.logger(ctx).Warn("Facilitator unavailable, serving without payment")
+		return PaymentResult{
+			RequirementNeeded: false,
+			PaymentInfo:       paymentInfo,
+			Degraded:          true,
+		}
+	}
+
+	h.logger(ctx).Error("Facilitator unavailable, rejecting request", "error", err)
+	if retryAfter <= 0 {
+		retryAfter = h.config.Degraded.RetryAfter
+	}
+	failure := paymentFailure(http.StatusServiceUnavailable, x402.ErrCodeFacilitatorUnavailable,
+		"Payment facilitator unavailable", err)
+	failure.RetryAfter = retryAfter
+	return *failure
+}
+
+// RetryAfterHeader returns the Retry-After header value in whole seconds,
+// or an empty string if the result has no retry time.
+func (r *PaymentResult) RetryAfterHeader() string {
+	if r.RetryAfter <= 0 {
+		return ""
+	}
+	return strconv.Itoa(int(math.Ceil(r.RetryAfter.Seconds())))
+}
@@ -0,0 +1,84 @@
+package common
+
+import (
+	"errors"
+	"net/http"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+// ErrorResponse returns the JSON body for a failed result. Payment requirements
+// are included in `accepts` for 402 responses so the client can pay again.
+func (r *PaymentResult) ErrorResponse() localx402.ErrorResponse {
+	return localx402.NewErrorResponse(r.paymentError(), r.accepts()...)
+}
+
+// WriteError writes the JSON error response for a failed result, with a
+// Retry-After header if the result has a retry time.
+func (r *PaymentResult) WriteError(w http.ResponseWriter) error {
+	if retryAfter := r.RetryAfterHeader(); retryAfter != "" {
+		w.Header().Set("Retry-After", retryAfter)
+	}
+	return localx402.WritePaymentError(w, r.StatusCode, r.paymentError(), r.accepts()...)
+}
+
+// paymentError returns the result's payment error, or an internal error if unset.
+func (r *PaymentResult) paymentError() *x402.PaymentError {
+	if r.PaymentError != nil {
+		return r.PaymentError
+	}
+	return x402.NewPaymentError(x402.ErrCodeInternal, r.ErrorMessage, r.Error)
+}
+
+// accepts returns the requirements to advertise with the error.
+func (r *PaymentResult) accepts() []x402.PaymentRequirement {
+	if r.StatusCode != http.StatusPaymentRequired {
+		return nil
+	}
+	return r.Requirements
+}
+
+// paymentFailure creates a failed result carrying a structured payment error.
+func paymentFailure(status int, code x402.ErrorCode, message string, err error) *PaymentResult {
+	return &PaymentResult{
+		Error:        err,
+		ErrorMessage: message,
+		StatusCode:   status,
+		PaymentError: x402.NewPaymentError(code, message, err),
+	}
+}
+
+// rejectionFailure creates a 402 result for a payment the facilitator rejected,
+// classifying the facilitator's reason into an error code.
+func rejectionFailure(message, reason string, fallback x402.ErrorCode, err error) *PaymentResult {
+	if reason != "" {
+		message += ": " + reason
+	}
+	failure := paymentFailure(http.StatusPaymentRequired, x402.ErrorCodeForReason(reason, fallback), message, err)
+	failure.PaymentError.WithDetails("reason", reason)
+	return failure
+}
+
+// facilitatorFailure creates a result for a facilitator request that failed.
+// A 4xx response whose reason identifies a payment problem is reported as a
+// rejection; anything else means the facilitator could not process the request.
+func facilitatorFailure(message string, err error) *PaymentResult {
+	var statusErr *localx402.StatusError
+	if !errors.As(err, &statusErr) {
+		return paymentFailure(http.StatusServiceUnavailable, x402.ErrCodeFacilitatorUnavailable, message, err)
+	}
+	if statusErr.StatusCode >= http.StatusInternalServerError {
+		failure := paymentFailure(http.StatusServiceUnavailable, x402.ErrCodeFacilitatorUnavailable, message, err)
+		failure.PaymentError.WithDetails("status", statusErr.StatusCode)
+		return failure
+	}
+
+	code := x402.ErrorCodeForReason(statusErr.Reason, x402.ErrCodeFacilitatorError)
+	if code != x402.ErrCodeFacilitatorError {
+		return rejectionFailure(message, statusErr.Reason, code, err)
+	}
+	failure := paymentFailure(http.StatusServiceUnavailable, code, message, err)
+	failure.PaymentError.WithDetails("status", statusErr.StatusCode)
+	return failure
+}
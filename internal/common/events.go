@@ -0,0 +1,35 @@
+package common
+
+import (
+	"time"
+
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+// emit dispatches the event of a payment step to the configured EventDispatcher,
+// if any. Failed steps emit EventPaymentFailed naming the failed endpoint.
+func (h *Handler) emit(eventType localx402.EventType, endpoint string, step paymentStep) {
+	if h.config.Events == nil {
+		return
+	}
+	event := localx402.Event{
+		Type:        eventType,
+		Time:        time.Now().UTC(),
+		RequestID:   step.resource.RequestID,
+		Route:       step.resource.Path,
+		Method:      step.resource.Method,
+		PaymentInfo: step.info,
+		Payer:       step.payer,
+		Settlement:  step.settlement,
+	}
+	if perr := failureError(step.failure); perr != nil {
+		event.Type = localx402.EventPaymentFailed
+		event.Step = endpoint
+		event.ErrorCode = perr.Code
+		event.ErrorMessage = perr.Message
+	}
+	if event.Payer == "" && step.settlement != nil {
+		event.Payer = step.settlement.Payer
+	}
+	h.config.Events.Dispatch(event)
+}
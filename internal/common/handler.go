@@ -3,22 +3,32 @@ package common
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"time"
 
 	x402 "github.com/dexfra-fun/x402-go"
 	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // PaymentResult represents the outcome of payment processing.
 type PaymentResult struct {
 	// RequirementNeeded indicates if a 402 Payment Required response should be sent
 	RequirementNeeded bool
-	// Requirement contains the payment requirement (if needed)
+	// Requirement contains the preferred payment requirement when payment is needed,
+	// or the requirement the payment was matched against after settlement
 	Requirement *x402.PaymentRequirement
+	// Requirements contains all accepted payment requirements (if needed)
+	Requirements []x402.PaymentRequirement
 	// Error indicates if an error occurred
 	Error error
 	// ErrorMessage is the user-facing error message
 	ErrorMessage string
+	// PaymentError classifies the error with a machine-readable code
+	PaymentError *x402.PaymentError
 	// StatusCode is the HTTP status code to return on error
 	StatusCode int
 	// PaymentInfo contains metadata about the payment
@@ -27,6 +37,47 @@ type PaymentResult struct {
 	Payer string
 	// Settlement contains the settlement response (if payment was settled)
 	Settlement *x402.SettlementResponse
+	// Degraded indicates the request is served without payment because the
+	// facilitator is unavailable and the route is in Config.Degraded.FreeRoutes
+	Degraded bool
+	// RetryAfter is when the client should retry a 503 response (if known)
+	RetryAfter time.Duration
+	// SettlementPending indicates the payment was verified but must be settled
+	// with Settle after the protected handler succeeds (see Config.SettleAfterHandler)
+	SettlementPending bool
+	// Payment contains the decoded payment (if settlement is pending)
+	Payment *x402.PaymentPayload
+	// AccessToken is the access token to return in the X-Payment-Token header:
+	// issued after settlement, or the next token of a counted stateless token
+	AccessToken string
+	// AccessClaims contains the claims of the access token that paid for the
+	// request instead of a payment (if any)
+	AccessClaims *localx402.AccessClaims
+	// CreditBalance is the payer's credit balance after the request was paid
+	// from it or topped it up (if credits are configured)
+	CreditBalance *decimal.Decimal
+
+	// nonceKey is the reserved replay-protection key (if a NonceStore is configured)
+	nonceKey string
+	// creditDebit is the debit that paid for the request instead of a payment (if any)
+	creditDebit *localx402.CreditDebit
+	// resource is the paid resource (if settlement is pending)
+	resource localx402.Resource
+	// verifiedAt is when the payment was verified (if settlement is pending)
+	verifiedAt time.Time
+}
+
+// ResponseHeaders returns the headers to add to the response of a successful
+// result: the access token to use next and the remaining credit balance.
+func (r *PaymentResult) ResponseHeaders() map[string]string {
+	headers := make(map[string]string)
+	if r.AccessToken != "" {
+		headers[localx402.HeaderAccessToken] = r.AccessToken
+	}
+	if r.CreditBalance != nil {
+		headers[localx402.HeaderCreditBalance] = r.CreditBalance.String()
+	}
+	return headers
 }
 
 // Handler encapsulates common payment processing logic.
@@ -55,51 +106,7 @@ func (h *Handler) ProcessPayment(
 	resource localx402.Resource,
 	r *http.Request,
 ) PaymentResult {
-	// Step 1: Get payment requirement
-	requirement, paymentInfo, err := h.middleware.ProcessRequest(ctx, resource)
-	if err != nil {
-		h.config.Logger.Errorf("[x402-common] Failed to process payment: %v", err)
-		return PaymentResult{
-			Error:        err,
-			ErrorMessage: "Payment processing error",
-			StatusCode:   http.StatusInternalServerError,
-		}
-	}
-
-	// Step 2: Check if payment is required
-	if requirement == nil {
-		// Free endpoint - no payment required
-		return PaymentResult{
-			RequirementNeeded: false,
-			PaymentInfo:       paymentInfo,
-		}
-	}
-
-	// Step 3: Check if payment header exists
-	paymentHeader := r.Header.Get(localx402.HeaderPayment)
-	if paymentHeader == "" {
-		// No payment provided - return 402 with requirement
-		h.config.Logger.Printf("[x402-common] No payment header provided")
-		return PaymentResult{
-			RequirementNeeded: true,
-			Requirement:       requirement,
-			PaymentInfo:       paymentInfo,
-		}
-	}
-
-	// Step 4: Decode and validate payment header
-	payment, err := localx402.DecodePaymentPayload(paymentHeader)
-	if err != nil {
-		// Invalid/malformed payment header - return 400 Bad Request
-		h.config.Logger.Printf("[x402-common] Invalid payment header: %v", err)
-		return PaymentResult{
-			Error:        err,
-			ErrorMessage: "Invalid payment header",
-			StatusCode:   http.StatusBadRequest,
-		}
-	}
-
-	return h.verifyAndSettle(ctx, payment, requirement, paymentInfo)
+	return h.ProcessPaymentWithHeader(ctx, resource, r.Header.Get(localx402.HeaderPayment))
 }
 
 // ProcessPaymentWithHeader performs payment processing with payment header string.
@@ -109,19 +116,43 @@ func (h *Handler) ProcessPaymentWithHeader(
 	resource localx402.Resource,
 	paymentHeader string,
 ) PaymentResult {
-	// Step 1: Get payment requirement
-	requirement, paymentInfo, err := h.middleware.ProcessRequest(ctx, resource)
+	ctx, span := h.middleware.Tracer().Start(ctx, "x402.ProcessPayment", trace.WithAttributes(
+		localx402.AttrRoute.String(resource.Path),
+		attribute.String("http.request.method", resource.Method),
+	))
+	defer span.End()
+
+	result := h.processPayment(ctx, resource, paymentHeader)
+	annotate(span, result)
+	return result
+}
+
+// processPayment runs the payment pipeline of ProcessPaymentWithHeader.
+func (h *Handler) processPayment(
+	ctx context.Context,
+	resource localx402.Resource,
+	paymentHeader string,
+) PaymentResult {
+	ctx = h.withLogger(ctx, "route", resource.Path, "method", resource.Method)
+	ctx = localx402.TrackFacilitator(ctx)
+
+	// Step 1: Serve requests presenting a valid access token without payment
+	if result, ok := h.redeemAccessToken(ctx, resource); ok {
+		return result
+	}
+
+	// Step 2: Get payment requirements
+	requirements, paymentInfo, err := h.middleware.ProcessRequest(ctx, resource)
 	if err != nil {
-		h.config.Logger.Errorf("[x402-common] Failed to process payment: %v", err)
-		return PaymentResult{
-			Error:        err,
-			ErrorMessage: "Payment processing error",
-			StatusCode:   http.StatusInternalServerError,
+		if retryAfter, down := h.unavailable(err); down {
+			return h.degrade(ctx, resource, paymentInfo, retryAfter, err)
 		}
+		h.logger(ctx).Error("Failed to process payment", "error", err)
+		return *paymentFailure(http.StatusInternalServerError, x402.ErrCodeInternal, "Payment processing error", err)
 	}
 
-	// Step 2: Check if payment is required
-	if requirement == nil {
+	// Step 3: Check if payment is required
+	if len(requirements) == 0 {
 		// Free endpoint - no payment required
 		return PaymentResult{
 			RequirementNeeded: false,
@@ -129,66 +160,138 @@ func (h *Handler) ProcessPaymentWithHeader(
 		}
 	}
 
-	// Step 3: Check if payment was provided
+	// Step 4: Pay from the credit balance of the account that signed the request
+	if result, ok := h.payFromCredits(ctx, resource, requirements, paymentInfo); ok {
+		return result
+	}
+
+	// Step 5: Fail fast while the facilitator is known to be down
+	if retryAfter, down := h.unavailable(nil); down {
+		return h.degrade(ctx, resource, paymentInfo, retryAfter, localx402.ErrCircuitOpen)
+	}
+
+	// Step 6: Check if payment header exists
 	if paymentHeader == "" {
+		// No payment provided - return 402 with requirement
+		h.logger(ctx).Debug("No payment header provided")
+		h.recordIssued(ctx, resource, requirements, paymentInfo)
 		return PaymentResult{
 			RequirementNeeded: true,
-			Requirement:       requirement,
+			Requirement:       &requirements[0],
+			Requirements:      requirements,
 			PaymentInfo:       paymentInfo,
 		}
 	}
 
-	// Step 4: Decode payment
+	// Step 7: Decode and validate payment header
 	payment, err := localx402.DecodePaymentPayload(paymentHeader)
 	if err != nil {
 		// Invalid/malformed payment header - return 400 Bad Request
-		h.config.Logger.Printf("[x402-common] Invalid payment header: %v", err)
-		return PaymentResult{
-			Error:        err,
-			ErrorMessage: "Invalid payment header",
-			StatusCode:   http.StatusBadRequest,
-		}
+		h.logger(ctx).Info("Invalid payment header", "error", err)
+		return *paymentFailure(http.StatusBadRequest, x402.ErrCodeInvalidPayment, "Invalid payment header", err)
 	}
 
-	return h.verifyAndSettle(ctx, payment, requirement, paymentInfo)
+	return h.verifyAndSettle(ctx, resource, payment, requirements, paymentInfo)
 }
 
 // verifyAndSettle performs payment verification and settlement.
 func (h *Handler) verifyAndSettle(
 	ctx context.Context,
+	resource localx402.Resource,
 	payment *x402.PaymentPayload,
-	requirement *x402.PaymentRequirement,
+	requirements []x402.PaymentRequirement,
 	paymentInfo *localx402.PaymentInfo,
 ) PaymentResult {
-	// Step 1: Basic validation
-	if !localx402.BasicPaymentCheck(*payment, *requirement) {
-		h.config.Logger.Errorf("[x402-common] Payment does not match requirement")
-		return PaymentResult{
-			Error:        localx402.ErrPaymentVerificationFailed,
-			ErrorMessage: "Invalid payment",
-			StatusCode:   http.StatusBadRequest,
+	// Step 1: Match payment to one of the accepted requirements
+	requirement, failure := h.matchPayment(ctx, payment, requirements)
+	if failure != nil {
+		h.recordVerify(ctx, paymentStep{
+			resource: resource,
+			info:     &localx402.PaymentInfo{Amount: paymentInfo.Amount, Network: reportedNetwork(payment.Network, requirements)},
+			failure:  failure,
+		})
+		return *failure
+	}
+	paymentInfo = h.middleware.PaymentInfoFor(paymentInfo.Amount, *requirement)
+	ctx = h.withLogger(ctx, paymentAttrs(paymentInfo)...)
+
+	// Step 2: Reserve the payment nonce so replays are rejected before verification
+	step := paymentStep{resource: resource, info: paymentInfo, requirement: requirement}
+	nonceKey, failure := h.reserveNonce(ctx, payment, requirement)
+	if failure != nil {
+		step.failure = failure
+		h.recordVerify(ctx, step)
+		return *failure
+	}
+
+	// Step 3: Verify payment with facilitator
+	payer, failure := h.verifyPayment(ctx, payment, requirement)
+	step.payer, step.verifiedAt, step.failure = payer, time.Now().UTC(), failure
+	h.recordVerify(ctx, step)
+	if failure != nil {
+		h.releaseNonce(ctx, nonceKey)
+		if retryAfter, down := h.unavailable(failure.Error); down {
+			return h.degrade(ctx, resource, paymentInfo, retryAfter, failure.Error)
 		}
+		failure.Requirements = requirements
+		return *failure
 	}
 
-	// Step 2: Verify payment with facilitator
-	payer, err := h.verifyPayment(ctx, payment, requirement)
-	if err != nil {
-		return *err
+	// Step 4: Defer settlement until the handler has run, if configured
+	if h.config.SettleAfterHandler {
+		return PaymentResult{
+			RequirementNeeded: false,
+			Requirement:       requirement,
+			Requirements:      requirements,
+			PaymentInfo:       paymentInfo,
+			Payer:             payer,
+			SettlementPending: true,
+			Payment:           payment,
+			nonceKey:          nonceKey,
+			resource:          resource,
+			verifiedAt:        step.verifiedAt,
+		}
 	}
 
-	// Step 3: Settle payment SYNCHRONOUSLY
-	settlement, err := h.settlePayment(ctx, payment, requirement, payer)
-	if err != nil {
-		return *err
+	// Step 5: Settle payment SYNCHRONOUSLY
+	settlement, failure := h.settleAndCommit(ctx, payment, requirement, payer, nonceKey)
+	step.settlement, step.failure = settlement, failure
+	h.recordSettle(ctx, step)
+	if failure != nil {
+		failure.Requirements = requirements
+		return *failure
 	}
 
-	// Step 4: Return success with settlement info
+	// Step 6: Return success with settlement info
 	return PaymentResult{
 		RequirementNeeded: false,
+		Requirement:       requirement,
 		PaymentInfo:       paymentInfo,
 		Payer:             payer,
 		Settlement:        settlement,
+		AccessToken:       h.issueAccessToken(ctx, resource, payer, settlement),
+		CreditBalance:     h.topUpCredits(ctx, resource, payer, requirement, paymentInfo, settlement),
+	}
+}
+
+// matchPayment selects the requirement a payment was made against and binds
+// it to the payment's fee payer.
+func (h *Handler) matchPayment(
+	ctx context.Context,
+	payment *x402.PaymentPayload,
+	requirements []x402.PaymentRequirement,
+) (*x402.PaymentRequirement, *PaymentResult) {
+	requirement, err := localx402.MatchRequirement(*payment, requirements)
+	if err != nil {
+		h.logger(ctx).Info("Payment does not match requirement", "network", payment.Network, "error", err)
+		code := x402.ErrCodeInvalidPayment
+		if errors.Is(err, localx402.ErrNoMatchingRequirement) {
+			code = x402.ErrCodeUnsupportedScheme
+		}
+		return nil, paymentFailure(http.StatusBadRequest, code, "Invalid payment", localx402.ErrPaymentVerificationFailed)
 	}
+	h.middleware.BindFeePayer(ctx, *payment, requirement)
+	return requirement, nil
 }
 
 // verifyPayment verifies payment with the facilitator.
@@ -197,29 +300,28 @@ func (h *Handler) verifyPayment(
 	payment *x402.PaymentPayload,
 	requirement *x402.PaymentRequirement,
 ) (string, *PaymentResult) {
-	isValid, invalidReason, payer, err := h.middleware.GetFacilitator().Verify(
-		ctx, *payment, *requirement,
-	)
+	ctx, span := h.middleware.Tracer().Start(ctx, "x402.Verify", trace.WithAttributes(requirementAttributes(requirement)...))
+	verification, err := h.middleware.GetFacilitator().Verify(ctx, *payment, *requirement)
 	if err != nil {
-		h.config.Logger.Errorf("[x402-common] Failed to verify payment: %v", err)
-		return "", &PaymentResult{
-			Error:        err,
-			ErrorMessage: "Payment verification failed",
-			StatusCode:   http.StatusServiceUnavailable,
-		}
+		h.logger(ctx).Error("Failed to verify payment", "error", err)
+		failure := facilitatorFailure("Payment verification failed", err)
+		endSpan(span, failure)
+		return "", failure
 	}
 
-	if !isValid {
-		h.config.Logger.Errorf("[x402-common] Payment verification failed: %s", invalidReason)
-		return "", &PaymentResult{
-			Error:        localx402.ErrPaymentVerificationFailed,
-			ErrorMessage: "Payment verification failed: " + invalidReason,
-			StatusCode:   http.StatusPaymentRequired,
-		}
+	span.SetAttributes(localx402.AttrPayer.String(verification.Payer))
+	if !verification.IsValid {
+		h.logger(ctx).Info("Payment verification failed",
+			"payer", verification.Payer, "invalidReason", verification.InvalidReason)
+		failure := rejectionFailure("Payment verification failed", verification.InvalidReason,
+			x402.ErrCodeVerificationFailed, localx402.ErrPaymentVerificationFailed)
+		endSpan(span, failure)
+		return "", failure
 	}
 
-	h.config.Logger.Printf("[x402-common] Payment verified: payer=%s", payer)
-	return payer, nil
+	h.logger(ctx).Info("Payment verified", "payer", verification.Payer)
+	endSpan(span, nil)
+	return verification.Payer, nil
 }
 
 // settlePayment settles payment with the facilitator.
@@ -229,30 +331,195 @@ func (h *Handler) settlePayment(
 	requirement *x402.PaymentRequirement,
 	payer string,
 ) (*x402.SettlementResponse, *PaymentResult) {
-	h.config.Logger.Printf("[x402-common] Settling payment: payer=%s", payer)
+	h.logger(ctx).Debug("Settling payment", "payer", payer)
+	ctx, span := h.middleware.Tracer().Start(ctx, "x402.Settle", trace.WithAttributes(
+		append(requirementAttributes(requirement), localx402.AttrPayer.String(payer))...))
 	settlement, err := h.middleware.GetFacilitator().Settle(ctx, *payment, *requirement)
 	if err != nil {
-		h.config.Logger.Errorf("[x402-common] Failed to settle payment: %v", err)
-		return nil, &PaymentResult{
-			Error:        err,
-			ErrorMessage: "Payment settlement failed",
-			StatusCode:   http.StatusServiceUnavailable,
-		}
+		h.logger(ctx).Error("Failed to settle payment", "payer", payer, "error", err)
+		failure := facilitatorFailure("Payment settlement failed", err)
+		endSpan(span, failure)
+		return nil, failure
 	}
 
+	span.SetAttributes(localx402.AttrTransaction.String(settlement.Transaction))
 	if !settlement.Success {
-		h.config.Logger.Errorf("[x402-common] Settlement failed: %s", settlement.ErrorReason)
-		return nil, &PaymentResult{
-			Error:        localx402.ErrPaymentVerificationFailed,
-			ErrorMessage: "Settlement failed: " + settlement.ErrorReason,
-			StatusCode:   http.StatusPaymentRequired,
-		}
+		h.logger(ctx).Warn("Settlement failed", "payer", payer, "errorReason", settlement.ErrorReason)
+		failure := rejectionFailure("Settlement failed", settlement.ErrorReason,
+			x402.ErrCodeSettlementFailed, localx402.ErrPaymentVerificationFailed)
+		endSpan(span, failure)
+		return nil, failure
 	}
 
-	h.config.Logger.Printf("[x402-common] Payment settled successfully: tx=%s", settlement.Transaction)
+	h.logger(ctx).Info("Payment settled", "payer", payer, "tx", settlement.Transaction)
+	endSpan(span, nil)
 	return settlement, nil
 }
 
+// reserveNonce reserves the payment's replay-protection key in the configured NonceStore.
+// Duplicate submissions get 409 while the original is in flight and 402 once it has settled.
+func (h *Handler) reserveNonce(
+	ctx context.Context,
+	payment *x402.PaymentPayload,
+	requirement *x402.PaymentRequirement,
+) (string, *PaymentResult) {
+	if h.config.NonceStore == nil {
+		return "", nil
+	}
+
+	key, err := localx402.PaymentNonceKey(*payment, *requirement)
+	if err != nil {
+		h.logger(ctx).Info("Failed to derive payment nonce", "error", err)
+		return "", paymentFailure(http.StatusBadRequest, x402.ErrCodeInvalidPayment, "Invalid payment", err)
+	}
+
+	timeout := requirement.MaxTimeoutSeconds
+	if timeout <= 0 {
+		timeout = x402.DefaultMaxTimeoutSeconds
+	}
+
+	err = h.config.NonceStore.Reserve(ctx, key, time.Duration(timeout)*time.Second)
+	switch {
+	case err == nil:
+		return key, nil
+	case errors.Is(err, localx402.ErrNonceInFlight):
+		h.logger(ctx).Info("Duplicate payment in flight", "nonce", key)
+		return "", paymentFailure(http.StatusConflict, x402.ErrCodePaymentInFlight,
+			"Payment is already being processed", err)
+	case errors.Is(err, localx402.ErrNonceUsed):
+		h.logger(ctx).Info("Payment already used", "nonce", key)
+		return "", paymentFailure(http.StatusPaymentRequired, x402.ErrCodePaymentReplayed,
+			"Payment has already been used", err)
+	default:
+		h.logger(ctx).Error("Failed to reserve payment nonce", "nonce", key, "error", err)
+		return "", paymentFailure(http.StatusServiceUnavailable, x402.ErrCodeInternal, "Payment processing error", err)
+	}
+}
+
+// releaseNonce releases a reservation so the payment may be retried.
+func (h *Handler) releaseNonce(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+	if err := h.config.NonceStore.Release(ctx, key); err != nil {
+		h.logger(ctx).Error("Failed to release payment nonce", "nonce", key, "error", err)
+	}
+}
+
+// settleAndCommit settles a payment and records the outcome in the NonceStore.
+// The nonce is committed on success and released when the facilitator rejects
+// the settlement. If the outcome is unknown (facilitator unreachable), the
+// reservation is kept until it expires.
+func (h *Handler) settleAndCommit(
+	ctx context.Context,
+	payment *x402.PaymentPayload,
+	requirement *x402.PaymentRequirement,
+	payer string,
+	nonceKey string,
+) (*x402.SettlementResponse, *PaymentResult) {
+	settlement, failure := h.settlePayment(ctx, payment, requirement, payer)
+	if nonceKey == "" {
+		return settlement, failure
+	}
+
+	switch {
+	case failure == nil:
+		if err := h.config.NonceStore.Commit(ctx, nonceKey); err != nil {
+			h.logger(ctx).Error("Failed to commit payment nonce", "nonce", nonceKey, "error", err)
+		}
+	case failure.StatusCode == http.StatusPaymentRequired:
+		h.releaseNonce(ctx, nonceKey)
+	}
+	return settlement, failure
+}
+
+// ShouldSettle reports whether a deferred payment should be settled for a handler
+// that responded with status.
+func (h *Handler) ShouldSettle(status int) bool {
+	return status < h.config.SettleStatusThreshold
+}
+
+// Settle settles a payment left pending by ProcessPayment.
+// On success the returned result carries the settlement; otherwise it carries the error.
+// A request paid from credits has nothing to settle; its debit is kept.
+func (h *Handler) Settle(ctx context.Context, pending PaymentResult) PaymentResult {
+	if pending.creditDebit != nil {
+		return h.commitCredits(ctx, pending)
+	}
+	ctx = h.withLogger(ctx, append([]any{"route", pending.resource.Path}, paymentAttrs(pending.PaymentInfo)...)...)
+	ctx = localx402.TrackFacilitator(ctx)
+	settlement, failure := h.settleAndCommit(ctx, pending.Payment, pending.Requirement, pending.Payer, pending.nonceKey)
+	h.recordSettle(ctx, paymentStep{
+		resource:    pending.resource,
+		info:        pending.PaymentInfo,
+		requirement: pending.Requirement,
+		payer:       pending.Payer,
+		settlement:  settlement,
+		verifiedAt:  pending.verifiedAt,
+		failure:     failure,
+	})
+	if failure != nil {
+		failure.Requirements = pending.Requirements
+		return *failure
+	}
+
+	result := pending
+	result.SettlementPending = false
+	result.Settlement = settlement
+	result.AccessToken = h.issueAccessToken(ctx, pending.resource, pending.Payer, settlement)
+	result.CreditBalance = h.topUpCredits(ctx, pending.resource, pending.Payer, pending.Requirement, pending.PaymentInfo, settlement)
+	return result
+}
+
+// Abandon releases a pending payment that will not be settled so the client may
+// retry it. A pending debit from credits is refunded.
+func (h *Handler) Abandon(ctx context.Context, pending PaymentResult) {
+	if pending.creditDebit != nil {
+		h.refundCredits(ctx, pending.creditDebit)
+		return
+	}
+	h.releaseNonce(ctx, pending.nonceKey)
+}
+
+// CompleteDeferred finishes a request whose payment was left pending by
+// ProcessPayment, once the protected handler has written to buffered.
+//
+// If the handler's status passes ShouldSettle, the payment is settled and the
+// buffered response is sent to w with the X-Payment-Response header. Otherwise
+// the buffered response is sent without settling. If settlement fails, nothing
+// is written and the returned result carries the error for the adapter to report.
+func (h *Handler) CompleteDeferred(
+	ctx context.Context,
+	w http.ResponseWriter,
+	buffered *BufferedResponseWriter,
+	pending PaymentResult,
+) PaymentResult {
+	if !h.ShouldSettle(buffered.Status()) {
+		h.logger(ctx).Info("Handler failed, payment not settled",
+			"route", pending.resource.Path, "status", buffered.Status(), "payer", pending.Payer)
+		h.Abandon(ctx, pending)
+		buffered.CopyTo(w)
+		pending.SettlementPending = false
+		return pending
+	}
+
+	result := h.Settle(ctx, pending)
+	if result.Error != nil {
+		return result
+	}
+
+	if result.Settlement != nil {
+		if err := localx402.SetPaymentResponseHeader(w, *result.Settlement); err != nil {
+			h.logger(ctx).Error("Failed to set payment response header", "error", err)
+		}
+	}
+	for key, value := range result.ResponseHeaders() {
+		w.Header().Set(key, value)
+	}
+	buffered.CopyTo(w)
+	return result
+}
+
 // GetConfig returns the handler configuration.
 func (h *Handler) GetConfig() *localx402.Config {
 	return h.config
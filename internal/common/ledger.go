@@ -0,0 +1,56 @@
+package common
+
+import (
+	"context"
+	"time"
+
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+// record writes a payment step to the configured PaymentRecorder, if any.
+// Failures are logged; the ledger never fails a request.
+func (h *Handler) record(ctx context.Context, kind localx402.RecordKind, step paymentStep) {
+	if h.config.Recorder == nil {
+		return
+	}
+	record := newPaymentRecord(kind, step)
+	record.Facilitator = localx402.UsedFacilitator(ctx)
+	if err := h.config.Recorder.Record(ctx, record); err != nil {
+		h.logger(ctx).Error("Failed to record payment", "kind", string(kind), "error", err)
+	}
+}
+
+// newPaymentRecord describes a payment step as a ledger entry.
+func newPaymentRecord(kind localx402.RecordKind, step paymentStep) localx402.PaymentRecord {
+	record := localx402.PaymentRecord{
+		Kind:       kind,
+		Time:       time.Now().UTC(),
+		RequestID:  step.resource.RequestID,
+		Route:      step.resource.Path,
+		Method:     step.resource.Method,
+		Payer:      step.payer,
+		VerifiedAt: step.verifiedAt,
+	}
+	if info := step.info; info != nil {
+		record.Price = info.Amount
+		record.Asset = info.Asset
+		record.Currency = info.Currency
+		record.Network = info.Network
+		record.PayTo = info.Recipient
+	}
+	if requirement := step.requirement; requirement != nil {
+		record.Amount = requirement.MaxAmountRequired
+		record.Scheme = requirement.Scheme
+	}
+	if settlement := step.settlement; settlement != nil {
+		record.Transaction = settlement.Transaction
+		if record.Payer == "" {
+			record.Payer = settlement.Payer
+		}
+	}
+	if perr := failureError(step.failure); perr != nil {
+		record.ErrorCode = perr.Code
+		record.ErrorMessage = perr.Message
+	}
+	return record
+}
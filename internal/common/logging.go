@@ -0,0 +1,36 @@
+package common
+
+import (
+	"context"
+	"log/slog"
+
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+// loggerKey is the context key of the request logger.
+type loggerKey struct{}
+
+// logger returns the request's logger, carrying the attributes known so far.
+func (h *Handler) logger(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return h.config.Slog
+}
+
+// withLogger returns ctx with attributes added to the request logger.
+func (h *Handler) withLogger(ctx context.Context, args ...any) context.Context {
+	return context.WithValue(ctx, loggerKey{}, h.logger(ctx).With(args...))
+}
+
+// paymentAttrs returns the log attributes of a payment.
+func paymentAttrs(info *localx402.PaymentInfo) []any {
+	if info == nil {
+		return nil
+	}
+	asset := info.Currency
+	if asset == "" {
+		asset = info.Asset
+	}
+	return []any{"network", info.Network, "asset", asset, "amount", info.Amount.String()}
+}
@@ -0,0 +1,76 @@
+package common
+
+import (
+	"context"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+// unknownNetwork is reported for payments on networks that are not accepted.
+const unknownNetwork = "unknown"
+
+// paymentStep describes the outcome of a payment step for metrics, the
+// payment ledger and payment events.
+type paymentStep struct {
+	resource    localx402.Resource
+	info        *localx402.PaymentInfo
+	requirement *x402.PaymentRequirement // nil if the payment matched no requirement
+	payer       string
+	settlement  *x402.SettlementResponse
+	verifiedAt  time.Time
+	failure     *PaymentResult // nil on success
+}
+
+// recordIssued reports a 402 response to a request without payment.
+func (h *Handler) recordIssued(
+	ctx context.Context,
+	resource localx402.Resource,
+	requirements []x402.PaymentRequirement,
+	info *localx402.PaymentInfo,
+) {
+	h.config.Metrics.RequirementsIssued(resource, requirements)
+	h.record(ctx, localx402.RecordRequirementIssued, paymentStep{
+		resource:    resource,
+		info:        info,
+		requirement: &requirements[0],
+	})
+}
+
+// recordVerify reports the outcome of a payment attempt up to verification.
+func (h *Handler) recordVerify(ctx context.Context, step paymentStep) {
+	h.config.Metrics.PaymentVerified(localx402.NewPaymentEvent(step.resource, step.info, failureError(step.failure)))
+	h.record(ctx, localx402.RecordVerified, step)
+	h.emit(localx402.EventPaymentVerified, localx402.EndpointVerify, step)
+}
+
+// recordSettle reports the outcome of a settlement.
+func (h *Handler) recordSettle(ctx context.Context, step paymentStep) {
+	h.config.Metrics.PaymentSettled(localx402.NewPaymentEvent(step.resource, step.info, failureError(step.failure)))
+	h.record(ctx, localx402.RecordSettled, step)
+	h.emit(localx402.EventPaymentSettled, localx402.EndpointSettle, step)
+}
+
+// reportedNetwork returns the network of a payment to report, if it is one of
+// the accepted networks. The payment's network is client input and would make
+// metric labels unbounded.
+func reportedNetwork(network string, requirements []x402.PaymentRequirement) string {
+	for _, requirement := range requirements {
+		if requirement.Network == network {
+			return network
+		}
+	}
+	return unknownNetwork
+}
+
+// failureError returns the payment error of a failed step, or nil on success.
+func failureError(failure *PaymentResult) *x402.PaymentError {
+	if failure == nil {
+		return nil
+	}
+	if failure.PaymentError == nil {
+		return x402.NewPaymentError(x402.ErrCodeInternal, failure.ErrorMessage, failure.Error)
+	}
+	return failure.PaymentError
+}
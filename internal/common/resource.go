@@ -11,9 +11,12 @@ import (
 // ExtractResource creates a Resource from an HTTP request.
 func ExtractResource(r *http.Request) x402.Resource {
 	resource := x402.Resource{
-		Path:   r.URL.Path,
-		Method: r.Method,
-		Params: make(map[string]string),
+		Path:                r.URL.Path,
+		Method:              r.Method,
+		Params:              make(map[string]string),
+		RequestID:           r.Header.Get(x402.HeaderRequestID),
+		AccessToken:         r.Header.Get(x402.HeaderAccessToken),
+		CreditAuthorization: r.Header.Get(x402.HeaderCreditAuthorization),
 	}
 
 	// Extract query parameters
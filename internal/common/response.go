@@ -0,0 +1,66 @@
+package common
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// BufferedResponseWriter is an http.ResponseWriter that holds the response in
+// memory so it can be inspected before being sent to the client.
+type BufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// NewBufferedResponseWriter creates an empty buffered response writer.
+func NewBufferedResponseWriter() *BufferedResponseWriter {
+	return &BufferedResponseWriter{header: make(http.Header)}
+}
+
+// Header implements http.ResponseWriter.
+func (b *BufferedResponseWriter) Header() http.Header {
+	return b.header
+}
+
+// WriteHeader implements http.ResponseWriter. Only the first call takes effect.
+func (b *BufferedResponseWriter) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+// Write implements http.ResponseWriter.
+func (b *BufferedResponseWriter) Write(data []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(data)
+}
+
+// Status returns the buffered status code (200 if none was written).
+func (b *BufferedResponseWriter) Status() int {
+	if b.status == 0 {
+		return http.StatusOK
+	}
+	return b.status
+}
+
+// Written reports whether a status or body has been written.
+func (b *BufferedResponseWriter) Written() bool {
+	return b.status != 0
+}
+
+// Body returns the buffered response body.
+func (b *BufferedResponseWriter) Body() []byte {
+	return b.body.Bytes()
+}
+
+// CopyTo writes the buffered headers, status and body to w,
+// keeping headers already set on w unless the handler overwrote them.
+func (b *BufferedResponseWriter) CopyTo(w http.ResponseWriter) {
+	dst := w.Header()
+	for key, values := range b.header {
+		dst[key] = values
+	}
+	w.WriteHeader(b.Status())
+	_, _ = w.Write(b.body.Bytes())
+}
@@ -0,0 +1,61 @@
+package common
+
+import (
+	x402 "github.com/dexfra-fun/x402-go"
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// requirementAttributes describes the requirement a payment is made against.
+func requirementAttributes(requirement *x402.PaymentRequirement) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		localx402.AttrNetwork.String(requirement.Network),
+		localx402.AttrScheme.String(requirement.Scheme),
+		localx402.AttrAsset.String(requirement.Asset),
+		attribute.String("x402.amount.atomic", requirement.MaxAmountRequired),
+	}
+}
+
+// endSpan annotates span with the outcome of a payment step and ends it.
+// failure is nil on success.
+func endSpan(span trace.Span, failure *PaymentResult) {
+	if failure != nil {
+		annotate(span, *failure)
+	}
+	span.End()
+}
+
+// annotate sets the attributes and status of a payment result on span.
+func annotate(span trace.Span, result PaymentResult) {
+	if info := result.PaymentInfo; info != nil {
+		span.SetAttributes(
+			localx402.AttrNetwork.String(info.Network),
+			localx402.AttrAmount.String(info.Amount.String()),
+		)
+	}
+	if result.Requirement != nil {
+		span.SetAttributes(localx402.AttrScheme.String(result.Requirement.Scheme))
+	}
+	if result.Payer != "" {
+		span.SetAttributes(localx402.AttrPayer.String(result.Payer))
+	}
+	if result.Settlement != nil {
+		span.SetAttributes(localx402.AttrTransaction.String(result.Settlement.Transaction))
+	}
+	if result.RequirementNeeded {
+		span.AddEvent("payment required")
+	}
+	if result.Degraded {
+		span.SetAttributes(attribute.Bool("x402.degraded", true))
+	}
+	if result.Error == nil {
+		return
+	}
+	if result.PaymentError != nil {
+		span.SetAttributes(localx402.AttrErrorCode.String(string(result.PaymentError.Code)))
+	}
+	span.RecordError(result.Error)
+	span.SetStatus(codes.Error, result.ErrorMessage)
+}
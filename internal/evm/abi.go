@@ -0,0 +1,79 @@
+package evm
+
+import (
+	"math/big"
+)
+
+// Contract function selectors.
+var (
+	// transferWithAuthorizationSelector is the EIP-3009 transferWithAuthorization(v, r, s) selector.
+	transferWithAuthorizationSelector = selector(
+		"transferWithAuthorization(address,address,uint256,uint256,uint256,bytes32,uint8,bytes32,bytes32)",
+	)
+
+	// authorizationStateSelector is the EIP-3009 authorizationState selector.
+	authorizationStateSelector = selector("authorizationState(address,bytes32)")
+
+	// balanceOfSelector is the ERC-20 balanceOf selector.
+	balanceOfSelector = selector("balanceOf(address)")
+)
+
+// selector returns the 4-byte function selector of a signature.
+func selector(signature string) []byte {
+	return Keccak256([]byte(signature))[:4]
+}
+
+// TransferWithAuthorizationCall encodes a transferWithAuthorization call from
+// an authorization and its [R || S || V] signature.
+func TransferWithAuthorizationCall(auth TransferAuthorization, sig []byte) ([]byte, error) {
+	if len(sig) != SignatureLength {
+		return nil, ErrInvalidSignature
+	}
+	v := sig[SignatureLength-1]
+	if v < recoveryIDOffset {
+		v += recoveryIDOffset
+	}
+
+	return concat(
+		transferWithAuthorizationSelector,
+		addressWord(auth.From),
+		addressWord(auth.To),
+		uint256Word(auth.Value),
+		uint256Word(auth.ValidAfter),
+		uint256Word(auth.ValidBefore),
+		auth.Nonce[:],
+		uint256Word(big.NewInt(int64(v))),
+		sig[:wordSize],
+		sig[wordSize:2*wordSize],
+	), nil
+}
+
+// AuthorizationStateCall encodes an authorizationState(authorizer, nonce) call.
+func AuthorizationStateCall(authorizer [AddressLength]byte, nonce [NonceLength]byte) []byte {
+	return concat(authorizationStateSelector, addressWord(authorizer), nonce[:])
+}
+
+// BalanceOfCall encodes a balanceOf(owner) call.
+func BalanceOfCall(owner [AddressLength]byte) []byte {
+	return concat(balanceOfSelector, addressWord(owner))
+}
+
+// DecodeUint256 decodes a single uint256 return value.
+func DecodeUint256(result []byte) (*big.Int, bool) {
+	if len(result) < wordSize {
+		return nil, false
+	}
+	return new(big.Int).SetBytes(result[:wordSize]), true
+}
+
+func concat(parts ...[]byte) []byte {
+	var n int
+	for _, p := range parts {
+		n += len(p)
+	}
+	out := make([]byte, 0, n)
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+// Package evm provides minimal EVM primitives used by x402: Keccak-256 hashing
+// and EIP-55 checksummed addresses.
+package evm
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// AddressLength is the length of an EVM address in bytes.
+	AddressLength = 20
+
+	// checksumThreshold is the nibble value at or above which a hex letter is uppercased.
+	checksumThreshold = 8
+)
+
+var (
+	// ErrInvalidAddress indicates a malformed hex address.
+	ErrInvalidAddress = errors.New("evm: invalid address")
+
+	// ErrInvalidChecksum indicates a mixed-case address with a wrong EIP-55 checksum.
+	ErrInvalidChecksum = errors.New("evm: invalid address checksum")
+)
+
+// Keccak256 returns the legacy Keccak-256 hash of the concatenated inputs.
+func Keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// ParseAddress decodes a 0x-prefixed hex address and validates its EIP-55 checksum.
+// All-lowercase and all-uppercase addresses carry no checksum and are accepted.
+func ParseAddress(s string) ([AddressLength]byte, error) {
+	var addr [AddressLength]byte
+
+	hasPrefix := strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X")
+	if !hasPrefix || len(s) != 2+2*AddressLength {
+		return addr, ErrInvalidAddress
+	}
+	digits := s[2:]
+
+	raw, err := hex.DecodeString(digits)
+	if err != nil {
+		return addr, ErrInvalidAddress
+	}
+	copy(addr[:], raw)
+
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) {
+		if ChecksumAddress(addr) != "0x"+digits {
+			return addr, ErrInvalidChecksum
+		}
+	}
+	return addr, nil
+}
+
+// DecodeHex decodes a hex string with an optional 0x or 0X prefix.
+func DecodeHex(s string) ([]byte, error) {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		s = s[2:]
+	}
+	return hex.DecodeString(s)
+}
+
+// ChecksumAddress returns the EIP-55 mixed-case encoding of an address.
+func ChecksumAddress(addr [AddressLength]byte) string {
+	lower := hex.EncodeToString(addr[:])
+	hash := Keccak256([]byte(lower))
+
+	out := []byte(lower)
+	for i, c := range out {
+		if c < 'a' {
+			continue
+		}
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if nibble&0x0f >= checksumThreshold {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
@@ -0,0 +1,90 @@
+package evm
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestKeccak256(t *testing.T) {
+	got := hex.EncodeToString(Keccak256(nil))
+	want := "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"
+	if got != want {
+		t.Errorf("Keccak256(\"\") = %s, want %s", got, want)
+	}
+}
+
+func TestChecksumAddress(t *testing.T) {
+	// Test vectors from EIP-55
+	vectors := []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	}
+
+	for _, want := range vectors {
+		t.Run(want, func(t *testing.T) {
+			addr, err := ParseAddress(strings.ToLower(want))
+			if err != nil {
+				t.Fatalf("ParseAddress() error = %v", err)
+			}
+			if got := ChecksumAddress(addr); got != want {
+				t.Errorf("ChecksumAddress() = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		wantErr error
+	}{
+		{"valid checksum", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", nil},
+		{"all lowercase", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", nil},
+		{"all uppercase", "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", nil},
+		{"bad checksum", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", ErrInvalidChecksum},
+		{"missing prefix", "5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", ErrInvalidAddress},
+		{"too short", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA", ErrInvalidAddress},
+		{"not hex", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAzz", ErrInvalidAddress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseAddress(tt.address)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseAddress() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeHex(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"0xabcd", "abcd", false},
+		{"0XABCD", "abcd", false},
+		{"abcd", "abcd", false},
+		{"0x", "", false},
+		{"0xabc", "", true},
+		{"0xzz", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := DecodeHex(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeHex() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && hex.EncodeToString(got) != tt.want {
+				t.Errorf("DecodeHex() = %x, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package evm
+
+import (
+	"math/big"
+)
+
+const (
+	// wordSize is the size of an ABI-encoded word in bytes.
+	wordSize = 32
+
+	// NonceLength is the length of an EIP-3009 authorization nonce in bytes.
+	NonceLength = 32
+
+	// Uint256Bits is the bit width of a Solidity uint256.
+	Uint256Bits = 256
+)
+
+var (
+	// domainTypeHash is keccak256 of the EIP-712 domain type.
+	domainTypeHash = Keccak256([]byte(
+		"EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)",
+	))
+
+	// transferWithAuthorizationTypeHash is keccak256 of the EIP-3009 struct type.
+	transferWithAuthorizationTypeHash = Keccak256([]byte(
+		"TransferWithAuthorization(address from,address to,uint256 value," +
+			"uint256 validAfter,uint256 validBefore,bytes32 nonce)",
+	))
+)
+
+// Domain is the EIP-712 domain of an EIP-3009 token contract.
+type Domain struct {
+	Name              string
+	Version           string
+	ChainID           uint64
+	VerifyingContract [AddressLength]byte
+}
+
+// TransferAuthorization holds the EIP-3009 transferWithAuthorization parameters.
+type TransferAuthorization struct {
+	From        [AddressLength]byte
+	To          [AddressLength]byte
+	Value       *big.Int
+	ValidAfter  *big.Int
+	ValidBefore *big.Int
+	Nonce       [NonceLength]byte
+}
+
+// Separator returns the EIP-712 domain separator.
+func (d Domain) Separator() []byte {
+	return Keccak256(
+		domainTypeHash,
+		Keccak256([]byte(d.Name)),
+		Keccak256([]byte(d.Version)),
+		uint256Word(new(big.Int).SetUint64(d.ChainID)),
+		addressWord(d.VerifyingContract),
+	)
+}
+
+// StructHash returns the EIP-712 struct hash of the authorization.
+func (a TransferAuthorization) StructHash() []byte {
+	return Keccak256(
+		transferWithAuthorizationTypeHash,
+		addressWord(a.From),
+		addressWord(a.To),
+		uint256Word(a.Value),
+		uint256Word(a.ValidAfter),
+		uint256Word(a.ValidBefore),
+		a.Nonce[:],
+	)
+}
+
+// TypedDataHash returns the EIP-712 digest that is signed for the authorization.
+func TypedDataHash(domain Domain, auth TransferAuthorization) []byte {
+	return Keccak256([]byte{0x19, 0x01}, domain.Separator(), auth.StructHash())
+}
+
+// uint256Word left-pads a non-negative integer to a 32-byte word.
+func uint256Word(v *big.Int) []byte {
+	word := make([]byte, wordSize)
+	if v != nil {
+		v.FillBytes(word)
+	}
+	return word
+}
+
+// addressWord left-pads an address to a 32-byte word.
+func addressWord(addr [AddressLength]byte) []byte {
+	word := make([]byte, wordSize)
+	copy(word[wordSize-AddressLength:], addr[:])
+	return word
+}
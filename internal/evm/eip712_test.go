@@ -0,0 +1,99 @@
+package evm
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// Vectors produced by go-ethereum's apitypes.TypedDataAndHash and crypto.Sign.
+const (
+	testPrivateKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testFrom       = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+	testDigest     = "df139d9e5acc993ba1b80910dd1061d714e507e23be1c00a03181fb568b52109"
+	testSignature  = "24f19c39270120f52785aa8e46f2dc2e42d6f3bc45a95b954857e960d67b62be" +
+		"2c4079b621509ebcac489861674e7f45da5513867e1985a80d52ff8dd2c958a51b"
+)
+
+func testAuthorization(t *testing.T) (Domain, TransferAuthorization) {
+	t.Helper()
+
+	contract, err := ParseAddress("0x036CbD53842c5426634e7929541eC2318f3dCF7e")
+	if err != nil {
+		t.Fatalf("parse contract: %v", err)
+	}
+	from, err := ParseAddress(testFrom)
+	if err != nil {
+		t.Fatalf("parse from: %v", err)
+	}
+	to, err := ParseAddress("0x209693Bc6afc0C5328bA36FaF03C514EF312287C")
+	if err != nil {
+		t.Fatalf("parse to: %v", err)
+	}
+
+	var nonce [NonceLength]byte
+	for i := range nonce {
+		nonce[i] = byte(i + 1)
+	}
+
+	domain := Domain{Name: "USDC", Version: "2", ChainID: 84532, VerifyingContract: contract}
+	auth := TransferAuthorization{
+		From:        from,
+		To:          to,
+		Value:       big.NewInt(10000),
+		ValidAfter:  big.NewInt(1700000000),
+		ValidBefore: big.NewInt(1700000300),
+		Nonce:       nonce,
+	}
+	return domain, auth
+}
+
+func TestTypedDataHash(t *testing.T) {
+	domain, auth := testAuthorization(t)
+
+	got := hex.EncodeToString(TypedDataHash(domain, auth))
+	if got != testDigest {
+		t.Errorf("TypedDataHash() = %s, want %s", got, testDigest)
+	}
+}
+
+func TestSignAndRecover(t *testing.T) {
+	raw, _ := hex.DecodeString(testPrivateKey)
+	key, err := ParsePrivateKey(raw)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey() error = %v", err)
+	}
+	if got := ChecksumAddress(key.Address()); got != testFrom {
+		t.Errorf("Address() = %s, want %s", got, testFrom)
+	}
+
+	digest, _ := hex.DecodeString(testDigest)
+	sig := key.Sign(digest)
+	if got := hex.EncodeToString(sig); got != testSignature {
+		t.Errorf("Sign() = %s, want %s", got, testSignature)
+	}
+
+	recovered, err := Recover(digest, sig)
+	if err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+	if recovered != key.Address() {
+		t.Errorf("Recover() = %s, want %s", ChecksumAddress(recovered), testFrom)
+	}
+
+	// Tampered digest recovers a different address
+	digest[0] ^= 0xff
+	other, err := Recover(digest, sig)
+	if err == nil && other == key.Address() {
+		t.Error("expected tampered digest to recover a different address")
+	}
+}
+
+func TestParsePrivateKey_Invalid(t *testing.T) {
+	if _, err := ParsePrivateKey([]byte{1, 2, 3}); err == nil {
+		t.Error("expected error for short key")
+	}
+	if _, err := ParsePrivateKey(make([]byte, PrivateKeyLength)); err == nil {
+		t.Error("expected error for zero key")
+	}
+}
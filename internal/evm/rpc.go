@@ -0,0 +1,198 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+const (
+	// defaultRPCTimeout is the default timeout for JSON-RPC requests.
+	defaultRPCTimeout = 10 * time.Second
+)
+
+// RPCClient is a minimal Ethereum JSON-RPC client.
+type RPCClient struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewRPCClient creates a JSON-RPC client for the given endpoint.
+// If httpClient is nil, a client with a default timeout is used.
+func NewRPCClient(url string, httpClient *http.Client) *RPCClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultRPCTimeout}
+	}
+	return &RPCClient{url: url, httpClient: httpClient}
+}
+
+// RPCError is an error returned by the JSON-RPC endpoint.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("evm rpc: %d %s", e.Code, e.Message)
+}
+
+// Call performs a JSON-RPC call and decodes the result into out.
+func (c *RPCClient) Call(ctx context.Context, method string, params []any, out any) error {
+	body, err := sonic.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("http request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+		Error  *RPCError       `json:"error"`
+	}
+	if err := sonic.ConfigDefault.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("decode json: %w", err)
+	}
+	if envelope.Error != nil {
+		return envelope.Error
+	}
+	if out == nil {
+		return nil
+	}
+	if err := sonic.Unmarshal(envelope.Result, out); err != nil {
+		return fmt.Errorf("decode result: %w", err)
+	}
+	return nil
+}
+
+// CallContract executes a read-only call against the latest block.
+func (c *RPCClient) CallContract(ctx context.Context, to [AddressLength]byte, data []byte) ([]byte, error) {
+	var result string
+	params := []any{map[string]any{"to": encodeHex(to[:]), "data": encodeHex(data)}, "latest"}
+	if err := c.Call(ctx, "eth_call", params, &result); err != nil {
+		return nil, err
+	}
+	return DecodeHex(result)
+}
+
+// PendingNonce returns the next nonce for an account, including pending transactions.
+func (c *RPCClient) PendingNonce(ctx context.Context, account [AddressLength]byte) (uint64, error) {
+	n, err := c.quantity(ctx, "eth_getTransactionCount", encodeHex(account[:]), "pending")
+	if err != nil {
+		return 0, err
+	}
+	return n.Uint64(), nil
+}
+
+// EstimateGas estimates the gas used by a call from an account.
+func (c *RPCClient) EstimateGas(ctx context.Context, from, to [AddressLength]byte, data []byte) (uint64, error) {
+	n, err := c.quantity(ctx, "eth_estimateGas", map[string]any{
+		"from": encodeHex(from[:]),
+		"to":   encodeHex(to[:]),
+		"data": encodeHex(data),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n.Uint64(), nil
+}
+
+// SuggestFees returns a priority fee and a fee cap of twice the latest base fee plus the priority fee.
+func (c *RPCClient) SuggestFees(ctx context.Context) (*big.Int, *big.Int, error) {
+	tip, err := c.quantity(ctx, "eth_maxPriorityFeePerGas")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var block struct {
+		BaseFeePerGas string `json:"baseFeePerGas"`
+	}
+	if err := c.Call(ctx, "eth_getBlockByNumber", []any{"latest", false}, &block); err != nil {
+		return nil, nil, err
+	}
+	baseFee, ok := parseQuantity(block.BaseFeePerGas)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid base fee %q", block.BaseFeePerGas)
+	}
+
+	feeCap := new(big.Int).Add(new(big.Int).Lsh(baseFee, 1), tip)
+	return tip, feeCap, nil
+}
+
+// SendRawTransaction submits a signed transaction and returns its hash.
+func (c *RPCClient) SendRawTransaction(ctx context.Context, raw []byte) (string, error) {
+	var hash string
+	if err := c.Call(ctx, "eth_sendRawTransaction", []any{encodeHex(raw)}, &hash); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// TransactionStatus returns whether a transaction has been mined and whether it succeeded.
+func (c *RPCClient) TransactionStatus(ctx context.Context, hash string) (bool, bool, error) {
+	var receipt *struct {
+		Status string `json:"status"`
+	}
+	if err := c.Call(ctx, "eth_getTransactionReceipt", []any{hash}, &receipt); err != nil {
+		return false, false, err
+	}
+	if receipt == nil {
+		return false, false, nil
+	}
+	return true, receipt.Status == "0x1", nil
+}
+
+// quantity calls a method returning a hex-encoded quantity.
+func (c *RPCClient) quantity(ctx context.Context, method string, params ...any) (*big.Int, error) {
+	var result string
+	if params == nil {
+		params = []any{}
+	}
+	if err := c.Call(ctx, method, params, &result); err != nil {
+		return nil, err
+	}
+	n, ok := parseQuantity(result)
+	if !ok {
+		return nil, fmt.Errorf("%s: invalid quantity %q", method, result)
+	}
+	return n, nil
+}
+
+// parseQuantity decodes a 0x-prefixed hex quantity.
+func parseQuantity(s string) (*big.Int, bool) {
+	return new(big.Int).SetString(strings.TrimPrefix(s, "0x"), 16) //nolint:mnd // hexadecimal
+}
+
+// encodeHex returns the 0x-prefixed hex encoding of b.
+func encodeHex(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
@@ -0,0 +1,104 @@
+package evm
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+const (
+	// SignatureLength is the length of an Ethereum [R || S || V] signature.
+	SignatureLength = 65
+
+	// PrivateKeyLength is the length of a secp256k1 private key in bytes.
+	PrivateKeyLength = 32
+
+	// recoveryIDOffset is added to the recovery ID to form V.
+	recoveryIDOffset = 27
+)
+
+var (
+	// ErrInvalidSignature indicates a malformed or unrecoverable signature.
+	ErrInvalidSignature = errors.New("evm: invalid signature")
+
+	// ErrInvalidPrivateKey indicates a malformed private key.
+	ErrInvalidPrivateKey = errors.New("evm: invalid private key")
+)
+
+// PrivateKey is a secp256k1 private key.
+type PrivateKey struct {
+	key *secp256k1.PrivateKey
+}
+
+// ParsePrivateKey creates a private key from 32 raw bytes.
+func ParsePrivateKey(raw []byte) (*PrivateKey, error) {
+	if len(raw) != PrivateKeyLength {
+		return nil, ErrInvalidPrivateKey
+	}
+	key := secp256k1.PrivKeyFromBytes(raw)
+	if key.Key.IsZero() {
+		return nil, ErrInvalidPrivateKey
+	}
+	return &PrivateKey{key: key}, nil
+}
+
+// Address returns the EVM address of the key.
+func (k *PrivateKey) Address() [AddressLength]byte {
+	return publicKeyAddress(k.key.PubKey())
+}
+
+// Sign signs a 32-byte hash and returns an Ethereum [R || S || V] signature with V in {27, 28}.
+func (k *PrivateKey) Sign(hash []byte) []byte {
+	compact := ecdsa.SignCompact(k.key, hash, false)
+
+	// Compact format is [V || R || S]; Ethereum expects [R || S || V]
+	sig := make([]byte, SignatureLength)
+	copy(sig, compact[1:])
+	sig[SignatureLength-1] = compact[0]
+	return sig
+}
+
+// Recover returns the address that produced sig over hash.
+// V may be encoded as {0, 1} or {27, 28}.
+func Recover(hash, sig []byte) ([AddressLength]byte, error) {
+	var addr [AddressLength]byte
+	if len(sig) != SignatureLength {
+		return addr, ErrInvalidSignature
+	}
+
+	v := sig[SignatureLength-1]
+	if v < recoveryIDOffset {
+		v += recoveryIDOffset
+	}
+	if v != recoveryIDOffset && v != recoveryIDOffset+1 {
+		return addr, ErrInvalidSignature
+	}
+
+	compact := make([]byte, SignatureLength)
+	compact[0] = v
+	copy(compact[1:], sig[:SignatureLength-1])
+
+	pub, _, err := ecdsa.RecoverCompact(compact, hash)
+	if err != nil {
+		return addr, ErrInvalidSignature
+	}
+	return publicKeyAddress(pub), nil
+}
+
+// PersonalMessageHash returns the EIP-191 hash of a message signed with
+// personal_sign: keccak256("\x19Ethereum Signed Message:\n" + len(message) + message).
+func PersonalMessageHash(message []byte) []byte {
+	prefix := "\x19Ethereum Signed Message:\n" + strconv.Itoa(len(message))
+	return Keccak256([]byte(prefix), message)
+}
+
+// publicKeyAddress derives the EVM address from a public key.
+func publicKeyAddress(pub *secp256k1.PublicKey) [AddressLength]byte {
+	var addr [AddressLength]byte
+	uncompressed := pub.SerializeUncompressed()
+	hash := Keccak256(uncompressed[1:])
+	copy(addr[:], hash[len(hash)-AddressLength:])
+	return addr
+}
@@ -0,0 +1,88 @@
+package evm
+
+import (
+	"math/big"
+)
+
+const (
+	// dynamicFeeTxType is the EIP-2718 type of EIP-1559 transactions.
+	dynamicFeeTxType = 0x02
+
+	// rlpShortLimit is the largest payload length encoded in the prefix byte.
+	rlpShortLimit = 55
+
+	// rlpStringOffset and rlpListOffset are the RLP prefixes of strings and lists.
+	rlpStringOffset = 0x80
+	rlpListOffset   = 0xc0
+)
+
+// DynamicFeeTx is an EIP-1559 transaction.
+type DynamicFeeTx struct {
+	ChainID   uint64
+	Nonce     uint64
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+	Gas       uint64
+	To        [AddressLength]byte
+	Value     *big.Int
+	Data      []byte
+}
+
+// Sign signs the transaction and returns its raw encoding and hash.
+func (tx *DynamicFeeTx) Sign(key *PrivateKey) ([]byte, []byte) {
+	fields := [][]byte{
+		rlpUint(new(big.Int).SetUint64(tx.ChainID)),
+		rlpUint(new(big.Int).SetUint64(tx.Nonce)),
+		rlpUint(tx.GasTipCap),
+		rlpUint(tx.GasFeeCap),
+		rlpUint(new(big.Int).SetUint64(tx.Gas)),
+		rlpBytes(tx.To[:]),
+		rlpUint(tx.Value),
+		rlpBytes(tx.Data),
+		rlpList(), // empty access list
+	}
+
+	sigHash := Keccak256([]byte{dynamicFeeTxType}, rlpList(fields...))
+	sig := key.Sign(sigHash)
+
+	yParity := new(big.Int).SetUint64(uint64(sig[SignatureLength-1] - recoveryIDOffset))
+	fields = append(fields,
+		rlpUint(yParity),
+		rlpUint(new(big.Int).SetBytes(sig[:wordSize])),
+		rlpUint(new(big.Int).SetBytes(sig[wordSize:2*wordSize])),
+	)
+
+	raw := append([]byte{dynamicFeeTxType}, rlpList(fields...)...)
+	return raw, Keccak256(raw)
+}
+
+// rlpUint encodes a non-negative integer as a minimal big-endian RLP string.
+func rlpUint(v *big.Int) []byte {
+	if v == nil {
+		return rlpBytes(nil)
+	}
+	return rlpBytes(v.Bytes())
+}
+
+// rlpBytes encodes a byte string.
+func rlpBytes(b []byte) []byte {
+	if len(b) == 1 && b[0] < rlpStringOffset {
+		return []byte{b[0]}
+	}
+	return append(rlpHeader(rlpStringOffset, len(b)), b...)
+}
+
+// rlpList encodes a list of already encoded items.
+func rlpList(items ...[]byte) []byte {
+	payload := concat(items...)
+	return append(rlpHeader(rlpListOffset, len(payload)), payload...)
+}
+
+// rlpHeader returns the prefix for a string or list payload of length n.
+func rlpHeader(offset byte, n int) []byte {
+	if n <= rlpShortLimit {
+		return []byte{offset + byte(n)}
+	}
+	length := big.NewInt(int64(n)).Bytes()
+	return append([]byte{offset + rlpShortLimit + byte(len(length))}, length...)
+}
@@ -0,0 +1,118 @@
+package evm
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestRLP(t *testing.T) {
+	long := strings.Repeat("a", 56)
+	tests := []struct {
+		name string
+		got  []byte
+		want string
+	}{
+		{"empty string", rlpBytes(nil), "80"},
+		{"single byte", rlpBytes([]byte{0x0f}), "0f"},
+		{"short string", rlpBytes([]byte("dog")), "83646f67"},
+		{"long string", rlpBytes([]byte(long)), "b838" + hex.EncodeToString([]byte(long))},
+		{"zero", rlpUint(big.NewInt(0)), "80"},
+		{"integer", rlpUint(big.NewInt(1024)), "820400"},
+		{"empty list", rlpList(), "c0"},
+		{"list", rlpList(rlpBytes([]byte("cat")), rlpBytes([]byte("dog"))), "c88363617483646f67"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hex.EncodeToString(tt.got); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectors(t *testing.T) {
+	tests := []struct {
+		name string
+		got  []byte
+		want string
+	}{
+		{"transferWithAuthorization", transferWithAuthorizationSelector, "e3ee160e"},
+		{"authorizationState", authorizationStateSelector, "e94a0102"},
+		{"balanceOf", balanceOfSelector, "70a08231"},
+	}
+
+	for _, tt := range tests {
+		if got := hex.EncodeToString(tt.got); got != tt.want {
+			t.Errorf("%s selector = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTransferWithAuthorizationCall(t *testing.T) {
+	_, auth := testAuthorization(t)
+	sig, _ := hex.DecodeString(testSignature)
+
+	data, err := TransferWithAuthorizationCall(auth, sig)
+	if err != nil {
+		t.Fatalf("TransferWithAuthorizationCall() error = %v", err)
+	}
+	if len(data) != 4+9*wordSize {
+		t.Fatalf("calldata length = %d, want %d", len(data), 4+9*wordSize)
+	}
+	if v := data[4+7*wordSize-1]; v != 27 {
+		t.Errorf("v = %d, want 27", v)
+	}
+	if !bytes.Equal(data[4+7*wordSize:], sig[:2*wordSize]) {
+		t.Error("r and s not copied from signature")
+	}
+}
+
+func TestDynamicFeeTx_Sign(t *testing.T) {
+	raw, _ := hex.DecodeString(testPrivateKey)
+	key, err := ParsePrivateKey(raw)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey() error = %v", err)
+	}
+
+	tx := &DynamicFeeTx{
+		ChainID:   84532,
+		Nonce:     7,
+		GasTipCap: big.NewInt(1_000_000),
+		GasFeeCap: big.NewInt(2_000_000),
+		Gas:       90_000,
+		Value:     new(big.Int),
+		Data:      []byte{0xe3, 0xee, 0x16, 0x0e},
+	}
+	encoded, hash := tx.Sign(key)
+
+	if encoded[0] != dynamicFeeTxType {
+		t.Fatalf("type = %#x, want 0x02", encoded[0])
+	}
+	if !bytes.Equal(hash, Keccak256(encoded)) {
+		t.Error("hash is not keccak256 of the encoding")
+	}
+
+	// The encoding ends with yParity and 32-byte r and s strings.
+	tail := encoded[len(encoded)-1-2*(1+wordSize):]
+	sig := make([]byte, 0, SignatureLength)
+	sig = append(sig, tail[2:2+wordSize]...)
+	sig = append(sig, tail[3+wordSize:]...)
+	sig = append(sig, tail[0]&1) // yParity is 0x80 (zero) or 0x01
+
+	unsigned := encoded[:len(encoded)-len(tail)]
+	headerLength := 1 + int(encoded[1]-rlpListOffset-rlpShortLimit)
+	fields := unsigned[1+headerLength:]
+	sigHash := Keccak256([]byte{dynamicFeeTxType}, rlpList(fields))
+
+	signer, err := Recover(sigHash, sig)
+	if err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+	if got := ChecksumAddress(signer); got != testFrom {
+		t.Errorf("signer = %s, want %s", got, testFrom)
+	}
+}
@@ -0,0 +1,42 @@
+// Package sqltest provides in-memory SQLite databases and a manual clock for
+// tests of the SQL-backed stores.
+package sqltest
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	// Register the pure Go "sqlite" driver.
+	_ "modernc.org/sqlite"
+)
+
+// Open returns an empty in-memory SQLite database, closed when the test ends.
+func Open(tb testing.TB) *sql.DB {
+	tb.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		tb.Fatalf("sqltest: open sqlite: %v", err)
+	}
+	// Each connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+	tb.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+// Clock is a manually advanced time source.
+type Clock struct {
+	now time.Time
+}
+
+// NewClock returns a clock at a fixed time.
+func NewClock() *Clock {
+	return &Clock{now: time.Unix(1_700_000_000, 0)}
+}
+
+// Now returns the clock's time.
+func (c *Clock) Now() time.Time { return c.now }
+
+// Advance moves the clock forward by d.
+func (c *Clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
@@ -0,0 +1,178 @@
+// Package svm provides minimal Solana primitives used by the x402 SVM signer:
+// public keys, program derived addresses, instructions and legacy transactions.
+package svm
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/mr-tron/base58"
+)
+
+const (
+	// PublicKeyLength is the length of a Solana public key in bytes.
+	PublicKeyLength = 32
+
+	// maxSeeds is the maximum number of seeds for a program derived address.
+	maxSeeds = 16
+
+	// maxSeedLength is the maximum length of a single seed.
+	maxSeedLength = 32
+
+	// pdaMarker is appended to PDA seeds before hashing.
+	pdaMarker = "ProgramDerivedAddress"
+)
+
+var (
+	// ErrInvalidPublicKey indicates a malformed public key.
+	ErrInvalidPublicKey = errors.New("svm: invalid public key")
+
+	// ErrNoProgramAddress indicates no valid bump seed was found.
+	ErrNoProgramAddress = errors.New("svm: unable to find a viable program address")
+
+	// ErrInvalidSeeds indicates too many or too long seeds.
+	ErrInvalidSeeds = errors.New("svm: invalid program address seeds")
+)
+
+// PublicKey is a 32-byte Solana account address.
+type PublicKey [PublicKeyLength]byte
+
+// ParsePublicKey decodes a base58 public key.
+func ParsePublicKey(s string) (PublicKey, error) {
+	var key PublicKey
+	raw, err := base58.Decode(s)
+	if err != nil {
+		return key, fmt.Errorf("%w: %w", ErrInvalidPublicKey, err)
+	}
+	if len(raw) != PublicKeyLength {
+		return key, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidPublicKey, PublicKeyLength, len(raw))
+	}
+	copy(key[:], raw)
+	return key, nil
+}
+
+// MustPublicKey decodes a base58 public key and panics on error.
+// Intended for well-known program IDs.
+func MustPublicKey(s string) PublicKey {
+	key, err := ParsePublicKey(s)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// String returns the base58 encoding of the key.
+func (k PublicKey) String() string {
+	return base58.Encode(k[:])
+}
+
+// IsZero reports whether the key is all zeros.
+func (k PublicKey) IsZero() bool {
+	return k == PublicKey{}
+}
+
+// CreateProgramAddress derives a program address from seeds and a program ID.
+// Returns an error if the resulting address lies on the ed25519 curve.
+func CreateProgramAddress(seeds [][]byte, programID PublicKey) (PublicKey, error) {
+	if len(seeds) > maxSeeds {
+		return PublicKey{}, ErrInvalidSeeds
+	}
+
+	h := sha256.New()
+	for _, seed := range seeds {
+		if len(seed) > maxSeedLength {
+			return PublicKey{}, ErrInvalidSeeds
+		}
+		h.Write(seed)
+	}
+	h.Write(programID[:])
+	h.Write([]byte(pdaMarker))
+
+	var key PublicKey
+	copy(key[:], h.Sum(nil))
+	if isOnCurve(key) {
+		return PublicKey{}, ErrNoProgramAddress
+	}
+	return key, nil
+}
+
+// FindProgramAddress finds a valid program derived address and its bump seed.
+func FindProgramAddress(seeds [][]byte, programID PublicKey) (PublicKey, uint8, error) {
+	bumpSeeds := make([][]byte, len(seeds), len(seeds)+1)
+	copy(bumpSeeds, seeds)
+	bumpSeeds = append(bumpSeeds, []byte{0})
+
+	for bump := 255; bump >= 0; bump-- {
+		bumpSeeds[len(seeds)][0] = uint8(bump)
+		key, err := CreateProgramAddress(bumpSeeds, programID)
+		if err == nil {
+			return key, uint8(bump), nil
+		}
+		if !errors.Is(err, ErrNoProgramAddress) {
+			return PublicKey{}, 0, err
+		}
+	}
+	return PublicKey{}, 0, ErrNoProgramAddress
+}
+
+// FindAssociatedTokenAddress derives the associated token account for owner and mint.
+func FindAssociatedTokenAddress(owner, mint, tokenProgram PublicKey) (PublicKey, error) {
+	key, _, err := FindProgramAddress(
+		[][]byte{owner[:], tokenProgram[:], mint[:]},
+		AssociatedTokenProgramID,
+	)
+	return key, err
+}
+
+// Edwards25519 curve parameters for the on-curve check.
+var (
+	curveP = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	curveD = func() *big.Int {
+		// d = -121665 / 121666 mod p
+		num := new(big.Int).Neg(big.NewInt(121665))
+		den := new(big.Int).ModInverse(big.NewInt(121666), curveP)
+		d := num.Mul(num, den)
+		return d.Mod(d, curveP)
+	}()
+	legendreExp = new(big.Int).Rsh(new(big.Int).Sub(curveP, big.NewInt(1)), 1)
+)
+
+// isOnCurve reports whether the compressed point decompresses to a valid curve point.
+// It mirrors curve25519-dalek's CompressedEdwardsY::decompress used by the Solana runtime.
+func isOnCurve(key PublicKey) bool {
+	// Little-endian y coordinate with the sign bit cleared
+	le := key
+	le[31] &= 0x7f
+	be := make([]byte, PublicKeyLength)
+	for i := range le {
+		be[PublicKeyLength-1-i] = le[i]
+	}
+	y := new(big.Int).SetBytes(be)
+	y.Mod(y, curveP)
+
+	// x^2 = (y^2 - 1) / (d*y^2 + 1)
+	y2 := new(big.Int).Mul(y, y)
+	y2.Mod(y2, curveP)
+
+	u := new(big.Int).Sub(y2, big.NewInt(1))
+	u.Mod(u, curveP)
+
+	v := new(big.Int).Mul(curveD, y2)
+	v.Add(v, big.NewInt(1))
+	v.Mod(v, curveP)
+
+	vInv := new(big.Int).ModInverse(v, curveP)
+	if vInv == nil {
+		return false
+	}
+	x2 := u.Mul(u, vInv)
+	x2.Mod(x2, curveP)
+	if x2.Sign() == 0 {
+		return true
+	}
+
+	// x^2 must be a quadratic residue
+	return new(big.Int).Exp(x2, legendreExp, curveP).Cmp(big.NewInt(1)) == 0
+}
@@ -0,0 +1,72 @@
+package svm
+
+import (
+	"crypto/ed25519"
+	"testing"
+)
+
+func testOwner() (ed25519.PrivateKey, PublicKey) {
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i + 1)
+	}
+	key := ed25519.NewKeyFromSeed(seed)
+
+	var pub PublicKey
+	copy(pub[:], key.Public().(ed25519.PublicKey))
+	return key, pub
+}
+
+func TestFindAssociatedTokenAddress(t *testing.T) {
+	_, owner := testOwner()
+	mint := MustPublicKey("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+
+	tests := []struct {
+		name  string
+		owner PublicKey
+		want  string
+	}{
+		{"generated owner", owner, "FjCjyojZLVYVQ2dEdDKQx76msks96TdH9xqvc8BQ9UUx"},
+		{"facilitator owner", MustPublicKey("2wKupLR9q6wXYppw8Gr2NvWxKBUqm4PPJKkQfoxHDBg4"), "3XZXfFJHF5ox3yPop16oqYfSWxLpkjsEuvTe2S67G2rj"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindAssociatedTokenAddress(tt.owner, mint, TokenProgramID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIsOnCurve(t *testing.T) {
+	_, owner := testOwner()
+	if !isOnCurve(owner) {
+		t.Error("expected ed25519 public key to be on curve")
+	}
+
+	ata := MustPublicKey("FjCjyojZLVYVQ2dEdDKQx76msks96TdH9xqvc8BQ9UUx")
+	if isOnCurve(ata) {
+		t.Error("expected program derived address to be off curve")
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	if _, err := ParsePublicKey("not-base58-0OIl"); err == nil {
+		t.Error("expected error for invalid base58")
+	}
+	if _, err := ParsePublicKey("abc"); err == nil {
+		t.Error("expected error for short key")
+	}
+	key, err := ParsePublicKey("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key.String() != "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v" {
+		t.Errorf("round trip mismatch: %s", key)
+	}
+}
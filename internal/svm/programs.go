@@ -0,0 +1,157 @@
+package svm
+
+import "encoding/binary"
+
+// Well-known program IDs.
+var (
+	// SystemProgramID is the Solana system program.
+	SystemProgramID = PublicKey{}
+
+	// TokenProgramID is the SPL Token program.
+	TokenProgramID = MustPublicKey("TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA")
+
+	// Token2022ProgramID is the SPL Token-2022 program.
+	Token2022ProgramID = MustPublicKey("TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb")
+
+	// AssociatedTokenProgramID is the SPL Associated Token Account program.
+	AssociatedTokenProgramID = MustPublicKey("ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL")
+
+	// ComputeBudgetProgramID is the compute budget program.
+	ComputeBudgetProgramID = MustPublicKey("ComputeBudget111111111111111111111111111111")
+)
+
+// Instruction discriminators.
+const (
+	// computeBudgetSetUnitLimit is the SetComputeUnitLimit discriminator.
+	computeBudgetSetUnitLimit = 2
+
+	// computeBudgetSetUnitPrice is the SetComputeUnitPrice discriminator.
+	computeBudgetSetUnitPrice = 3
+
+	// tokenTransferChecked is the SPL Token TransferChecked discriminator.
+	tokenTransferChecked = 12
+)
+
+// AccountMeta describes an account referenced by an instruction.
+type AccountMeta struct {
+	PublicKey  PublicKey
+	IsSigner   bool
+	IsWritable bool
+}
+
+// Instruction is a single program invocation.
+type Instruction struct {
+	ProgramID PublicKey
+	Accounts  []AccountMeta
+	Data      []byte
+}
+
+// NewSetComputeUnitLimitInstruction sets the transaction compute unit limit.
+func NewSetComputeUnitLimitInstruction(units uint32) Instruction {
+	data := make([]byte, 1+4)
+	data[0] = computeBudgetSetUnitLimit
+	binary.LittleEndian.PutUint32(data[1:], units)
+	return Instruction{ProgramID: ComputeBudgetProgramID, Data: data}
+}
+
+// NewSetComputeUnitPriceInstruction sets the compute unit price in micro-lamports.
+func NewSetComputeUnitPriceInstruction(microLamports uint64) Instruction {
+	data := make([]byte, 1+8)
+	data[0] = computeBudgetSetUnitPrice
+	binary.LittleEndian.PutUint64(data[1:], microLamports)
+	return Instruction{ProgramID: ComputeBudgetProgramID, Data: data}
+}
+
+// TransferCheckedParams are the inputs to an SPL TransferChecked instruction.
+type TransferCheckedParams struct {
+	TokenProgram PublicKey
+	Source       PublicKey
+	Mint         PublicKey
+	Destination  PublicKey
+	Owner        PublicKey
+	Amount       uint64
+	Decimals     uint8
+}
+
+// NewTransferCheckedInstruction creates an SPL Token TransferChecked instruction.
+func NewTransferCheckedInstruction(p TransferCheckedParams) Instruction {
+	data := make([]byte, 1+8+1)
+	data[0] = tokenTransferChecked
+	binary.LittleEndian.PutUint64(data[1:], p.Amount)
+	data[9] = p.Decimals
+
+	return Instruction{
+		ProgramID: p.TokenProgram,
+		Accounts: []AccountMeta{
+			{PublicKey: p.Source, IsWritable: true},
+			{PublicKey: p.Mint},
+			{PublicKey: p.Destination, IsWritable: true},
+			{PublicKey: p.Owner, IsSigner: true},
+		},
+		Data: data,
+	}
+}
+
+// ComputeBudget is the compute budget requested by a message.
+type ComputeBudget struct {
+	UnitLimit uint32
+	UnitPrice uint64
+}
+
+// IsComputeBudget reports whether the instruction invokes the compute budget program.
+func (m *Message) IsComputeBudget(ix CompiledInstruction) bool {
+	return m.AccountKeys[ix.ProgramIDIndex] == ComputeBudgetProgramID
+}
+
+// ComputeBudget returns the unit limit and price set by the message's compute
+// budget instructions. It reports false if any of them is not a well-formed
+// SetComputeUnitLimit or SetComputeUnitPrice instruction.
+func (m *Message) ComputeBudget() (ComputeBudget, bool) {
+	const (
+		limitLength = 1 + 4
+		priceLength = 1 + 8
+	)
+	var budget ComputeBudget
+	for _, ix := range m.Instructions {
+		if !m.IsComputeBudget(ix) {
+			continue
+		}
+		switch {
+		case len(ix.Data) == limitLength && ix.Data[0] == computeBudgetSetUnitLimit:
+			budget.UnitLimit = binary.LittleEndian.Uint32(ix.Data[1:])
+		case len(ix.Data) == priceLength && ix.Data[0] == computeBudgetSetUnitPrice:
+			budget.UnitPrice = binary.LittleEndian.Uint64(ix.Data[1:])
+		default:
+			return ComputeBudget{}, false
+		}
+	}
+	return budget, true
+}
+
+// FindTransferChecked returns the parameters of the first SPL Token or Token-2022
+// TransferChecked instruction in the message.
+func (m *Message) FindTransferChecked() (TransferCheckedParams, bool) {
+	const (
+		dataLength   = 1 + 8 + 1
+		accountCount = 4
+	)
+	for _, ix := range m.Instructions {
+		program := m.AccountKeys[ix.ProgramIDIndex]
+		if program != TokenProgramID && program != Token2022ProgramID {
+			continue
+		}
+		if len(ix.Data) != dataLength || ix.Data[0] != tokenTransferChecked || len(ix.Accounts) < accountCount {
+			continue
+		}
+		return TransferCheckedParams{
+			TokenProgram: program,
+			Source:       m.AccountKeys[ix.Accounts[0]],
+			Mint:         m.AccountKeys[ix.Accounts[1]],
+			Destination:  m.AccountKeys[ix.Accounts[2]],
+			Owner:        m.AccountKeys[ix.Accounts[3]],
+			Amount:       binary.LittleEndian.Uint64(ix.Data[1:]),
+			Decimals:     ix.Data[9],
+		}, true
+	}
+	return TransferCheckedParams{}, false
+}
@@ -0,0 +1,142 @@
+package svm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+const (
+	// defaultRPCTimeout is the default timeout for JSON-RPC requests.
+	defaultRPCTimeout = 10 * time.Second
+)
+
+// RPCClient is a minimal Solana JSON-RPC client.
+type RPCClient struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewRPCClient creates a JSON-RPC client for the given endpoint.
+// If httpClient is nil, a client with a default timeout is used.
+func NewRPCClient(url string, httpClient *http.Client) *RPCClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultRPCTimeout}
+	}
+	return &RPCClient{url: url, httpClient: httpClient}
+}
+
+// RPCError is an error returned by the JSON-RPC endpoint.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("svm rpc: %d %s", e.Code, e.Message)
+}
+
+// Call performs a JSON-RPC call and decodes the result into out.
+func (c *RPCClient) Call(ctx context.Context, method string, params []any, out any) error {
+	body, err := sonic.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("http request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+		Error  *RPCError       `json:"error"`
+	}
+	if err := sonic.ConfigDefault.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("decode json: %w", err)
+	}
+	if envelope.Error != nil {
+		return envelope.Error
+	}
+	if out == nil {
+		return nil
+	}
+	if err := sonic.Unmarshal(envelope.Result, out); err != nil {
+		return fmt.Errorf("decode result: %w", err)
+	}
+	return nil
+}
+
+// GetLatestBlockhash returns the latest finalized blockhash in base58.
+func (c *RPCClient) GetLatestBlockhash(ctx context.Context) (string, error) {
+	var result struct {
+		Value struct {
+			Blockhash string `json:"blockhash"`
+		} `json:"value"`
+	}
+	params := []any{map[string]any{"commitment": "finalized"}}
+	if err := c.Call(ctx, "getLatestBlockhash", params, &result); err != nil {
+		return "", err
+	}
+	return result.Value.Blockhash, nil
+}
+
+// SendTransaction submits a signed transaction and returns its base58 signature.
+func (c *RPCClient) SendTransaction(ctx context.Context, raw []byte) (string, error) {
+	var signature string
+	params := []any{
+		base64.StdEncoding.EncodeToString(raw),
+		map[string]any{"encoding": "base64", "preflightCommitment": "confirmed"},
+	}
+	if err := c.Call(ctx, "sendTransaction", params, &signature); err != nil {
+		return "", err
+	}
+	return signature, nil
+}
+
+// SignatureStatus is the processing status of a transaction.
+type SignatureStatus struct {
+	ConfirmationStatus string `json:"confirmationStatus"`
+	Err                any    `json:"err"`
+}
+
+// GetSignatureStatus returns the status of a transaction. The status is empty
+// if the transaction is not yet known to the node.
+func (c *RPCClient) GetSignatureStatus(ctx context.Context, signature string) (SignatureStatus, error) {
+	var result struct {
+		Value []*SignatureStatus `json:"value"`
+	}
+	params := []any{[]string{signature}, map[string]any{"searchTransactionHistory": false}}
+	if err := c.Call(ctx, "getSignatureStatuses", params, &result); err != nil {
+		return SignatureStatus{}, err
+	}
+	if len(result.Value) == 0 || result.Value[0] == nil {
+		return SignatureStatus{}, nil
+	}
+	return *result.Value[0], nil
+}
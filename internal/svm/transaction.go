@@ -0,0 +1,442 @@
+package svm
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/mr-tron/base58"
+)
+
+const (
+	// SignatureLength is the length of an ed25519 signature in bytes.
+	SignatureLength = 64
+
+	// HashLength is the length of a blockhash in bytes.
+	HashLength = 32
+)
+
+var (
+	// ErrSignerNotFound indicates the signing key is not a required signer of the message.
+	ErrSignerNotFound = errors.New("svm: key is not a required signer")
+
+	// ErrTooManyAccounts indicates the message references more accounts than can be encoded.
+	ErrTooManyAccounts = errors.New("svm: too many accounts in message")
+
+	// ErrMalformedTransaction indicates the wire bytes are not a valid legacy transaction.
+	ErrMalformedTransaction = errors.New("svm: malformed transaction")
+)
+
+// Signature is an ed25519 transaction signature.
+type Signature [SignatureLength]byte
+
+// IsZero reports whether the signature is unset.
+func (s Signature) IsZero() bool {
+	return s == Signature{}
+}
+
+// String returns the base58 encoding of the signature.
+func (s Signature) String() string {
+	return base58.Encode(s[:])
+}
+
+// MessageHeader describes the signer and read-only layout of the account keys.
+type MessageHeader struct {
+	NumRequiredSignatures       uint8
+	NumReadonlySignedAccounts   uint8
+	NumReadonlyUnsignedAccounts uint8
+}
+
+// CompiledInstruction is an instruction referencing accounts by index.
+type CompiledInstruction struct {
+	ProgramIDIndex uint8
+	Accounts       []uint8
+	Data           []byte
+}
+
+// Message is a legacy Solana transaction message.
+type Message struct {
+	Header          MessageHeader
+	AccountKeys     []PublicKey
+	RecentBlockhash [HashLength]byte
+	Instructions    []CompiledInstruction
+}
+
+// Transaction is a legacy Solana transaction.
+type Transaction struct {
+	Signatures []Signature
+	Message    Message
+}
+
+// accountEntry tracks the merged permissions of an account during compilation.
+type accountEntry struct {
+	key      PublicKey
+	signer   bool
+	writable bool
+}
+
+// NewTransaction compiles instructions into an unsigned legacy transaction.
+// The fee payer is always the first account and a writable signer.
+func NewTransaction(
+	instructions []Instruction,
+	feePayer PublicKey,
+	recentBlockhash [HashLength]byte,
+) (*Transaction, error) {
+	entries := []accountEntry{{key: feePayer, signer: true, writable: true}}
+	index := map[PublicKey]int{feePayer: 0}
+
+	add := func(meta AccountMeta) {
+		if i, ok := index[meta.PublicKey]; ok {
+			entries[i].signer = entries[i].signer || meta.IsSigner
+			entries[i].writable = entries[i].writable || meta.IsWritable
+			return
+		}
+		index[meta.PublicKey] = len(entries)
+		entries = append(entries, accountEntry{
+			key:      meta.PublicKey,
+			signer:   meta.IsSigner,
+			writable: meta.IsWritable,
+		})
+	}
+
+	// Instruction accounts come first, followed by the invoked programs
+	for _, ix := range instructions {
+		for _, meta := range ix.Accounts {
+			add(meta)
+		}
+	}
+	for _, ix := range instructions {
+		add(AccountMeta{PublicKey: ix.ProgramID})
+	}
+
+	const maxAccounts = 256
+	if len(entries) > maxAccounts {
+		return nil, ErrTooManyAccounts
+	}
+
+	ordered, header := orderAccounts(entries)
+
+	keys := make([]PublicKey, len(ordered))
+	position := make(map[PublicKey]uint8, len(ordered))
+	for i, entry := range ordered {
+		keys[i] = entry.key
+		position[entry.key] = uint8(i) //nolint:gosec // bounded by maxAccounts
+	}
+
+	compiled := make([]CompiledInstruction, len(instructions))
+	for i, ix := range instructions {
+		accounts := make([]uint8, len(ix.Accounts))
+		for j, meta := range ix.Accounts {
+			accounts[j] = position[meta.PublicKey]
+		}
+		compiled[i] = CompiledInstruction{
+			ProgramIDIndex: position[ix.ProgramID],
+			Accounts:       accounts,
+			Data:           ix.Data,
+		}
+	}
+
+	return &Transaction{
+		Signatures: make([]Signature, header.NumRequiredSignatures),
+		Message: Message{
+			Header:          header,
+			AccountKeys:     keys,
+			RecentBlockhash: recentBlockhash,
+			Instructions:    compiled,
+		},
+	}, nil
+}
+
+// orderAccounts sorts accounts into writable signers, read-only signers,
+// writable non-signers and read-only non-signers, keeping insertion order.
+func orderAccounts(entries []accountEntry) ([]accountEntry, MessageHeader) {
+	groups := make([][]accountEntry, 4)
+	for _, entry := range entries {
+		switch {
+		case entry.signer && entry.writable:
+			groups[0] = append(groups[0], entry)
+		case entry.signer:
+			groups[1] = append(groups[1], entry)
+		case entry.writable:
+			groups[2] = append(groups[2], entry)
+		default:
+			groups[3] = append(groups[3], entry)
+		}
+	}
+
+	ordered := make([]accountEntry, 0, len(entries))
+	for _, group := range groups {
+		ordered = append(ordered, group...)
+	}
+
+	//nolint:gosec // bounded by maxAccounts
+	return ordered, MessageHeader{
+		NumRequiredSignatures:       uint8(len(groups[0]) + len(groups[1])),
+		NumReadonlySignedAccounts:   uint8(len(groups[1])),
+		NumReadonlyUnsignedAccounts: uint8(len(groups[3])),
+	}
+}
+
+// Sign signs the message with key and stores the signature in the signer's slot.
+func (tx *Transaction) Sign(key ed25519.PrivateKey) error {
+	var pub PublicKey
+	copy(pub[:], key.Public().(ed25519.PublicKey))
+
+	for i := 0; i < int(tx.Message.Header.NumRequiredSignatures); i++ {
+		if tx.Message.AccountKeys[i] != pub {
+			continue
+		}
+		message, err := tx.Message.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		copy(tx.Signatures[i][:], ed25519.Sign(key, message))
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrSignerNotFound, pub)
+}
+
+// VerifySignature reports whether the signature in slot i is a valid signature
+// of the message by the corresponding account key.
+func (tx *Transaction) VerifySignature(i int) bool {
+	if i < 0 || i >= len(tx.Signatures) || i >= len(tx.Message.AccountKeys) {
+		return false
+	}
+	message, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return false
+	}
+	key := tx.Message.AccountKeys[i]
+	return ed25519.Verify(key[:], message, tx.Signatures[i][:])
+}
+
+// MarshalBinary serializes the message in the Solana wire format.
+func (m *Message) MarshalBinary() ([]byte, error) {
+	buf := []byte{
+		m.Header.NumRequiredSignatures,
+		m.Header.NumReadonlySignedAccounts,
+		m.Header.NumReadonlyUnsignedAccounts,
+	}
+
+	buf = appendCompactU16(buf, len(m.AccountKeys))
+	for _, key := range m.AccountKeys {
+		buf = append(buf, key[:]...)
+	}
+
+	buf = append(buf, m.RecentBlockhash[:]...)
+
+	buf = appendCompactU16(buf, len(m.Instructions))
+	for _, ix := range m.Instructions {
+		buf = append(buf, ix.ProgramIDIndex)
+		buf = appendCompactU16(buf, len(ix.Accounts))
+		buf = append(buf, ix.Accounts...)
+		buf = appendCompactU16(buf, len(ix.Data))
+		buf = append(buf, ix.Data...)
+	}
+
+	return buf, nil
+}
+
+// MarshalBinary serializes the transaction in the Solana wire format.
+func (tx *Transaction) MarshalBinary() ([]byte, error) {
+	message, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := appendCompactU16(nil, len(tx.Signatures))
+	for _, sig := range tx.Signatures {
+		buf = append(buf, sig[:]...)
+	}
+	return append(buf, message...), nil
+}
+
+// UnmarshalBinary decodes a transaction from the Solana wire format.
+// Only legacy (unversioned) messages are supported.
+func (tx *Transaction) UnmarshalBinary(data []byte) error {
+	r := &reader{buf: data}
+
+	count, err := r.compactU16()
+	if err != nil {
+		return err
+	}
+	signatures := make([]Signature, count)
+	for i := range signatures {
+		raw, err := r.bytes(SignatureLength)
+		if err != nil {
+			return err
+		}
+		copy(signatures[i][:], raw)
+	}
+
+	var message Message
+	if err := message.decode(r); err != nil {
+		return err
+	}
+	if r.remaining() != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrMalformedTransaction, r.remaining())
+	}
+	if len(signatures) != int(message.Header.NumRequiredSignatures) {
+		return fmt.Errorf("%w: %d signatures for %d required signers",
+			ErrMalformedTransaction, len(signatures), message.Header.NumRequiredSignatures)
+	}
+
+	tx.Signatures = signatures
+	tx.Message = message
+	return nil
+}
+
+// decode reads a legacy message and checks that all indexes are in range.
+func (m *Message) decode(r *reader) error {
+	const versionPrefix = 0x80
+
+	header, err := r.bytes(3) //nolint:mnd // three header bytes
+	if err != nil {
+		return err
+	}
+	if header[0]&versionPrefix != 0 {
+		return fmt.Errorf("%w: versioned messages are not supported", ErrMalformedTransaction)
+	}
+	m.Header = MessageHeader{
+		NumRequiredSignatures:       header[0],
+		NumReadonlySignedAccounts:   header[1],
+		NumReadonlyUnsignedAccounts: header[2],
+	}
+
+	numKeys, err := r.compactU16()
+	if err != nil {
+		return err
+	}
+	if numKeys < int(m.Header.NumRequiredSignatures)+int(m.Header.NumReadonlyUnsignedAccounts) {
+		return fmt.Errorf("%w: header does not match account count", ErrMalformedTransaction)
+	}
+	m.AccountKeys = make([]PublicKey, numKeys)
+	for i := range m.AccountKeys {
+		raw, err := r.bytes(PublicKeyLength)
+		if err != nil {
+			return err
+		}
+		copy(m.AccountKeys[i][:], raw)
+	}
+
+	blockhash, err := r.bytes(HashLength)
+	if err != nil {
+		return err
+	}
+	copy(m.RecentBlockhash[:], blockhash)
+
+	numInstructions, err := r.compactU16()
+	if err != nil {
+		return err
+	}
+	m.Instructions = make([]CompiledInstruction, numInstructions)
+	for i := range m.Instructions {
+		ix, err := decodeInstruction(r, numKeys)
+		if err != nil {
+			return err
+		}
+		m.Instructions[i] = ix
+	}
+	return nil
+}
+
+// decodeInstruction reads a compiled instruction referencing up to numKeys accounts.
+func decodeInstruction(r *reader, numKeys int) (CompiledInstruction, error) {
+	var ix CompiledInstruction
+
+	programIndex, err := r.bytes(1)
+	if err != nil {
+		return ix, err
+	}
+	ix.ProgramIDIndex = programIndex[0]
+
+	numAccounts, err := r.compactU16()
+	if err != nil {
+		return ix, err
+	}
+	accounts, err := r.bytes(numAccounts)
+	if err != nil {
+		return ix, err
+	}
+	ix.Accounts = append([]uint8(nil), accounts...)
+
+	dataLen, err := r.compactU16()
+	if err != nil {
+		return ix, err
+	}
+	data, err := r.bytes(dataLen)
+	if err != nil {
+		return ix, err
+	}
+	ix.Data = append([]byte(nil), data...)
+
+	if int(ix.ProgramIDIndex) >= numKeys {
+		return ix, fmt.Errorf("%w: program index out of range", ErrMalformedTransaction)
+	}
+	for _, index := range ix.Accounts {
+		if int(index) >= numKeys {
+			return ix, fmt.Errorf("%w: account index out of range", ErrMalformedTransaction)
+		}
+	}
+	return ix, nil
+}
+
+// reader consumes Solana wire-format bytes.
+type reader struct {
+	buf []byte
+	pos int
+}
+
+// remaining returns the number of unread bytes.
+func (r *reader) remaining() int {
+	return len(r.buf) - r.pos
+}
+
+// bytes returns the next n bytes.
+func (r *reader) bytes(n int) ([]byte, error) {
+	if n < 0 || r.remaining() < n {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrMalformedTransaction)
+	}
+	b := r.buf[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+// compactU16 reads a compact-u16 (shortvec) length.
+func (r *reader) compactU16() (int, error) {
+	const (
+		lowBits      = 0x7f
+		continuation = 0x80
+		shift        = 7
+		maxBytes     = 3
+	)
+	n := 0
+	for i := range maxBytes {
+		b, err := r.bytes(1)
+		if err != nil {
+			return 0, err
+		}
+		n |= int(b[0]&lowBits) << (shift * i)
+		if b[0]&continuation == 0 {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: invalid compact-u16", ErrMalformedTransaction)
+}
+
+// appendCompactU16 appends n using Solana's compact-u16 (shortvec) encoding.
+func appendCompactU16(buf []byte, n int) []byte {
+	const (
+		lowBits      = 0x7f
+		continuation = 0x80
+		shift        = 7
+	)
+	for {
+		b := byte(n & lowBits)
+		n >>= shift
+		if n == 0 {
+			return append(buf, b)
+		}
+		buf = append(buf, b|continuation)
+	}
+}
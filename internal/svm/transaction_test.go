@@ -0,0 +1,102 @@
+package svm
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func testTransferTransaction(t *testing.T) *Transaction {
+	t.Helper()
+
+	key, owner := testOwner()
+	mint := MustPublicKey("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	feePayer := MustPublicKey("L54zkaPQFeTn1UsEqieEXBqWrPShiaZEPD7mS5WXfQg")
+
+	tx, err := NewTransaction([]Instruction{
+		NewSetComputeUnitLimitInstruction(20_000),
+		NewTransferCheckedInstruction(TransferCheckedParams{
+			TokenProgram: TokenProgramID,
+			Source:       MustPublicKey("FjCjyojZLVYVQ2dEdDKQx76msks96TdH9xqvc8BQ9UUx"),
+			Mint:         mint,
+			Destination:  MustPublicKey("3XZXfFJHF5ox3yPop16oqYfSWxLpkjsEuvTe2S67G2rj"),
+			Owner:        owner,
+			Amount:       1000,
+			Decimals:     6,
+		}),
+	}, feePayer, [HashLength]byte{1, 2, 3})
+	if err != nil {
+		t.Fatalf("NewTransaction() error = %v", err)
+	}
+	if err := tx.Sign(key); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	return tx
+}
+
+func TestTransaction_UnmarshalBinary(t *testing.T) {
+	tx := testTransferTransaction(t)
+
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+
+	var decoded Transaction
+	if err := decoded.UnmarshalBinary(raw); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+
+	again, err := decoded.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	if !bytes.Equal(raw, again) {
+		t.Error("round trip changed the transaction bytes")
+	}
+
+	transfer, ok := decoded.Message.FindTransferChecked()
+	if !ok {
+		t.Fatal("FindTransferChecked() found no transfer")
+	}
+	if transfer.Mint.String() != "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v" {
+		t.Errorf("mint = %s", transfer.Mint)
+	}
+	if transfer.Amount != 1000 || transfer.Decimals != 6 {
+		t.Errorf("amount = %d decimals = %d", transfer.Amount, transfer.Decimals)
+	}
+}
+
+func TestTransaction_UnmarshalBinaryMalformed(t *testing.T) {
+	tx := testTransferTransaction(t)
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+
+	tx.Message.Instructions[0].ProgramIDIndex = 0xff
+	badIndex, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"truncated", raw[:len(raw)-1]},
+		{"trailing bytes", append(bytes.Clone(raw), 0)},
+		{"versioned message", append(bytes.Clone(raw[:1+SignatureLength]), append([]byte{0x80}, raw[1+SignatureLength:]...)...)},
+		{"program index out of range", badIndex},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded Transaction
+			if err := decoded.UnmarshalBinary(tt.data); !errors.Is(err, ErrMalformedTransaction) {
+				t.Errorf("UnmarshalBinary() error = %v, want ErrMalformedTransaction", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+// Package ttlcache provides a generic in-memory cache whose entries expire
+// after a fixed TTL. Expired entries can be served for a grace period while
+// they are refreshed in the background, and concurrent loads of the same key
+// are deduplicated. Close stops all background work.
+package ttlcache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Loader loads the value of a missing or expired key.
+type Loader[V any] func(ctx context.Context) (V, error)
+
+// Cache is a concurrency-safe TTL cache.
+type Cache[K comparable, V any] struct {
+	ttl      time.Duration
+	staleTTL time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[K]entry[V]
+	calls   map[K]*call[V]
+
+	ctx         context.Context //nolint:containedctx // canceled by Close to stop background work
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	cleanupOnce sync.Once
+	closeOnce   sync.Once
+}
+
+// entry is a cached value and when it was stored.
+type entry[V any] struct {
+	value    V
+	storedAt time.Time
+}
+
+// call is an in-flight load shared by all callers of the same key.
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// Option configures a Cache.
+type Option func(*options)
+
+type options struct {
+	staleTTL time.Duration
+	now      func() time.Time
+}
+
+// WithStaleTTL serves entries for up to staleTTL after they expire while a
+// single background load refreshes them (stale-while-revalidate).
+func WithStaleTTL(staleTTL time.Duration) Option {
+	return func(o *options) {
+		o.staleTTL = staleTTL
+	}
+}
+
+// WithClock sets the time source, for tests.
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// New creates a cache whose entries are fresh for ttl.
+func New[K comparable, V any](ttl time.Duration, opts ...Option) *Cache[K, V] {
+	o := options{now: time.Now}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Cache[K, V]{
+		ttl:      ttl,
+		staleTTL: o.staleTTL,
+		now:      o.now,
+		entries:  make(map[K]entry[V]),
+		calls:    make(map[K]*call[V]),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Get returns the value of key if it is fresh.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || c.now().Sub(e.storedAt) >= c.ttl {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Peek returns the value of key without loading it. fresh reports whether it
+// is within its TTL; stale values are found but not fresh.
+func (c *Cache[K, V]) Peek(key K) (value V, fresh, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	age := c.now().Sub(e.storedAt)
+	if !ok || age >= c.ttl+c.staleTTL {
+		return value, false, false
+	}
+	return e.value, age < c.ttl, true
+}
+
+// Set stores the value of key, replacing any previous entry.
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = entry[V]{value: value, storedAt: c.now()}
+}
+
+// Delete removes key.
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
+
+// Clear removes all entries.
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[K]entry[V])
+}
+
+// Len returns the number of entries, including expired ones not yet cleaned up.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
+// CleanupExpired removes entries past their TTL and stale period.
+func (c *Cache[K, V]) CleanupExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for key, e := range c.entries {
+		if now.Sub(e.storedAt) >= c.ttl+c.staleTTL {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// StartCleanup runs CleanupExpired every interval until Close is called.
+// Only the first call starts a cleanup goroutine.
+func (c *Cache[K, V]) StartCleanup(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	c.cleanupOnce.Do(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.ctx.Err() != nil {
+			return
+		}
+
+		ticker := time.NewTicker(interval)
+		c.wg.Go(func() {
+			defer ticker.Stop()
+			for {
+				select {
+				case <-c.ctx.Done():
+					return
+				case <-ticker.C:
+					c.CleanupExpired()
+				}
+			}
+		})
+	})
+}
+
+// GetOrLoad returns the value of key, loading it on a miss. Concurrent misses
+// of the same key share one load; callers stop waiting when ctx is done.
+// Within the stale period an expired value is returned at once and refreshed
+// in the background. Errors are not cached.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, load Loader[V]) (V, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	age := c.now().Sub(e.storedAt)
+	switch {
+	case ok && age < c.ttl:
+		c.mu.Unlock()
+		return e.value, nil
+	case ok && age < c.ttl+c.staleTTL && c.ctx.Err() == nil:
+		if _, loading := c.calls[key]; !loading {
+			cl := c.startCall(key)
+			c.wg.Go(func() {
+				c.finishCall(c.ctx, key, cl, load)
+			})
+		}
+		c.mu.Unlock()
+		return e.value, nil
+	}
+
+	cl, loading := c.calls[key]
+	if !loading {
+		cl = c.startCall(key)
+		c.mu.Unlock()
+		c.finishCall(ctx, key, cl, load)
+		return cl.value, cl.err
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.value, cl.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// loading reports whether a load of key is in flight.
+func (c *Cache[K, V]) loading(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.calls[key]
+	return ok
+}
+
+// startCall registers an in-flight load of key. Callers must hold c.mu.
+func (c *Cache[K, V]) startCall(key K) *call[V] {
+	cl := &call[V]{done: make(chan struct{})}
+	c.calls[key] = cl
+	return cl
+}
+
+// finishCall runs a load, stores a successful result and releases waiters.
+func (c *Cache[K, V]) finishCall(ctx context.Context, key K, cl *call[V], load Loader[V]) {
+	cl.value, cl.err = load(ctx)
+
+	c.mu.Lock()
+	if cl.err == nil {
+		c.entries[key] = entry[V]{value: cl.value, storedAt: c.now()}
+	}
+	delete(c.calls, key)
+	c.mu.Unlock()
+	close(cl.done)
+}
+
+// Close stops the cleanup goroutine and background refreshes and waits for
+// them to return. The cache stays usable; expired entries are then loaded
+// synchronously.
+func (c *Cache[K, V]) Close() {
+	c.closeOnce.Do(func() {
+		// Cancel under the lock so no background work starts after Wait
+		c.mu.Lock()
+		c.cancel()
+		c.mu.Unlock()
+		c.wg.Wait()
+	})
+}
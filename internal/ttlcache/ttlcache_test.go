@@ -0,0 +1,171 @@
+package ttlcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced time source.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestCache_Expiry(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	cache := New[string, int](time.Minute, WithClock(clock.Now))
+	defer cache.Close()
+
+	cache.Set("a", 1)
+	if v, ok := cache.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get() = %d, %v; want 1, true", v, ok)
+	}
+
+	clock.Advance(time.Minute)
+	if _, ok := cache.Get("a"); ok {
+		t.Error("expected entry to expire")
+	}
+	cache.CleanupExpired()
+	if cache.Len() != 0 {
+		t.Errorf("Len() = %d after cleanup, want 0", cache.Len())
+	}
+}
+
+func TestCache_StaleWhileRevalidate(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	cache := New[string, string](time.Minute, WithStaleTTL(time.Minute), WithClock(clock.Now))
+	defer cache.Close()
+	cache.Set("key", "old")
+	clock.Advance(90 * time.Second)
+	if v, fresh, found := cache.Peek("key"); !found || fresh || v != "old" {
+		t.Fatalf("Peek() = %q, %v, %v; want stale value", v, fresh, found)
+	}
+
+	release := make(chan struct{})
+	var loads atomic.Int32
+	load := func(context.Context) (string, error) {
+		loads.Add(1)
+		<-release
+		return "new", nil
+	}
+
+	for range 3 {
+		v, err := cache.GetOrLoad(context.Background(), "key", load)
+		if err != nil || v != "old" {
+			t.Fatalf("GetOrLoad() = %q, %v; want stale value without waiting", v, err)
+		}
+	}
+	close(release)
+	cache.Close()
+
+	if loads.Load() != 1 {
+		t.Errorf("loads = %d, want 1 background refresh", loads.Load())
+	}
+	if v, ok := cache.Get("key"); !ok || v != "new" {
+		t.Errorf("Get() = %q, %v; want refreshed value", v, ok)
+	}
+
+	// Past the stale period the value is loaded synchronously
+	clock.Advance(3 * time.Minute)
+	if _, _, found := cache.Peek("key"); found {
+		t.Error("Peek() found a value past the stale period")
+	}
+	v, err := cache.GetOrLoad(context.Background(), "key", func(context.Context) (string, error) {
+		return "", errors.New("down")
+	})
+	if err == nil {
+		t.Errorf("GetOrLoad() = %q, want load error", v)
+	}
+}
+
+func TestCache_SingleFlight(t *testing.T) {
+	cache := New[string, int](time.Minute)
+	defer cache.Close()
+
+	release := make(chan struct{})
+	var loads atomic.Int32
+	load := func(context.Context) (int, error) {
+		loads.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	const callers = 10
+	var started, wg sync.WaitGroup
+	started.Add(callers)
+	results := make([]int, callers)
+	for i := range callers {
+		wg.Go(func() {
+			started.Done()
+			results[i], _ = cache.GetOrLoad(context.Background(), "key", load)
+		})
+	}
+	started.Wait()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if loads.Load() != 1 {
+		t.Errorf("loads = %d, want 1", loads.Load())
+	}
+	for i, v := range results {
+		if v != 42 {
+			t.Errorf("caller %d got %d, want 42", i, v)
+		}
+	}
+}
+
+func TestCache_WaiterContext(t *testing.T) {
+	cache := New[string, int](time.Minute)
+	defer cache.Close()
+
+	release := make(chan struct{})
+	defer close(release)
+	go func() {
+		_, _ = cache.GetOrLoad(context.Background(), "key", func(context.Context) (int, error) {
+			<-release
+			return 1, nil
+		})
+	}()
+	for !cache.loading("key") {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := cache.GetOrLoad(ctx, "key", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetOrLoad() error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestCache_Close(t *testing.T) {
+	cache := New[string, int](time.Millisecond)
+	cache.StartCleanup(time.Millisecond)
+	cache.Set("a", 1)
+
+	cache.Close()
+	cache.Close()
+	cache.StartCleanup(time.Millisecond)
+
+	// The cache stays usable after Close
+	v, err := cache.GetOrLoad(context.Background(), "b", func(context.Context) (int, error) { return 2, nil })
+	if err != nil || v != 2 {
+		t.Errorf("GetOrLoad() after Close = %d, %v", v, err)
+	}
+}
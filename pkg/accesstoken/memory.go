@@ -0,0 +1,73 @@
+// Package accesstoken provides AccessTokenStore implementations that count
+// the calls of x402 pay-once access tokens.
+package accesstoken
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+// memoryEntry is the state of an issued token.
+type memoryEntry struct {
+	remaining int
+	expiresAt time.Time
+}
+
+// MemoryStore is an in-process AccessTokenStore. It only counts calls on a
+// single replica; use SQLStore to share counts between replicas.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]memoryEntry),
+		now:     time.Now,
+	}
+}
+
+var _ x402.AccessTokenStore = (*MemoryStore)(nil)
+
+// Issue implements x402.AccessTokenStore. Expired tokens are removed.
+func (s *MemoryStore) Issue(_ context.Context, id string, calls int, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+	s.entries[id] = memoryEntry{remaining: calls, expiresAt: expiresAt}
+	return nil
+}
+
+// Consume implements x402.AccessTokenStore.
+func (s *MemoryStore) Consume(_ context.Context, id string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok || entry.remaining <= 0 || !s.now().Before(entry.expiresAt) {
+		delete(s.entries, id)
+		return 0, x402.ErrAccessTokenExhausted
+	}
+	entry.remaining--
+	s.entries[id] = entry
+	return entry.remaining, nil
+}
+
+// Len returns the number of tokens held, including expired ones not yet removed.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.entries)
+}
@@ -0,0 +1,135 @@
+package accesstoken
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+// DefaultTable is the default table name used by SQLStore.
+const DefaultTable = "x402_access_tokens"
+
+// SQLStore is an AccessTokenStore backed by a SQL database, shared by all
+// replicas using the same table. Calls are consumed with a conditional
+// UPDATE, so a token is never used more often than it allows.
+//
+// Schema (see CreateTable):
+//
+//	CREATE TABLE x402_access_tokens (
+//	    token_id   VARCHAR(64) PRIMARY KEY,
+//	    remaining  INTEGER     NOT NULL,
+//	    expires_at BIGINT      NOT NULL
+//	);
+type SQLStore struct {
+	db          *sql.DB
+	table       string
+	placeholder func(n int) string
+	now         func() time.Time
+}
+
+// SQLOption configures a SQLStore.
+type SQLOption func(*SQLStore)
+
+// WithTable sets the table name.
+func WithTable(table string) SQLOption {
+	return func(s *SQLStore) {
+		s.table = table
+	}
+}
+
+// WithDollarPlaceholders uses $1, $2, ... placeholders (PostgreSQL) instead of ?.
+func WithDollarPlaceholders() SQLOption {
+	return func(s *SQLStore) {
+		s.placeholder = func(n int) string {
+			return "$" + strconv.Itoa(n)
+		}
+	}
+}
+
+// NewSQLStore creates a store using db. The table must exist; see CreateTable.
+func NewSQLStore(db *sql.DB, opts ...SQLOption) *SQLStore {
+	s := &SQLStore{
+		db:          db,
+		table:       DefaultTable,
+		placeholder: func(int) string { return "?" },
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateTable creates the access token table if it does not exist.
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+s.table+` (
+		token_id VARCHAR(64) PRIMARY KEY,
+		remaining INTEGER NOT NULL,
+		expires_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create access token table: %w", err)
+	}
+	return nil
+}
+
+var _ x402.AccessTokenStore = (*SQLStore)(nil)
+
+// Issue implements x402.AccessTokenStore.
+func (s *SQLStore) Issue(ctx context.Context, id string, calls int, expiresAt time.Time) error {
+	if _, err := s.db.ExecContext(ctx,
+		s.query("INSERT INTO %s (token_id, remaining, expires_at) VALUES (%s, %s, %s)"),
+		id, calls, expiresAt.UnixMilli(),
+	); err != nil {
+		return fmt.Errorf("issue access token: %w", err)
+	}
+	return nil
+}
+
+// Consume implements x402.AccessTokenStore.
+func (s *SQLStore) Consume(ctx context.Context, id string) (int, error) {
+	result, err := s.db.ExecContext(ctx,
+		s.query("UPDATE %s SET remaining = remaining - 1 WHERE token_id = %s AND remaining > 0 AND expires_at > %s"),
+		id, s.now().UnixMilli(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("consume access token: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err != nil {
+		return 0, fmt.Errorf("consume access token: %w", err)
+	} else if updated == 0 {
+		return 0, x402.ErrAccessTokenExhausted
+	}
+
+	// Concurrent uses may have lowered the count further; it is informational
+	var remaining int
+	err = s.db.QueryRowContext(ctx, s.query("SELECT remaining FROM %s WHERE token_id = %s"), id).Scan(&remaining)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("read access token: %w", err)
+	}
+	return remaining, nil
+}
+
+// DeleteExpired removes expired tokens. Call it periodically to bound table size.
+func (s *SQLStore) DeleteExpired(ctx context.Context) (int64, error) {
+	result, err := s.db.ExecContext(ctx, s.query("DELETE FROM %s WHERE expires_at <= %s"), s.now().UnixMilli())
+	if err != nil {
+		return 0, fmt.Errorf("delete expired access tokens: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// query formats a statement with the table name and numbered placeholders.
+func (s *SQLStore) query(format string) string {
+	args := []any{s.table}
+	for i := 1; i < strings.Count(format, "%s"); i++ {
+		args = append(args, s.placeholder(i))
+	}
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,112 @@
+package accesstoken
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dexfra-fun/x402-go/internal/sqltest"
+	"github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+// newStores returns an empty store of each kind using clock.
+func newStores(t *testing.T, clock *sqltest.Clock) map[string]x402.AccessTokenStore {
+	t.Helper()
+
+	memory := NewMemoryStore()
+	memory.now = clock.Now
+
+	sqlStore := NewSQLStore(sqltest.Open(t), WithTable("access_tokens"))
+	sqlStore.now = clock.Now
+	if err := sqlStore.CreateTable(context.Background()); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	return map[string]x402.AccessTokenStore{"memory": memory, "sql": sqlStore}
+}
+
+func TestStore_Consume(t *testing.T) {
+	tests := []struct {
+		name     string
+		calls    int
+		consumed int // calls used before the checked one
+		advance  time.Duration
+		id       string
+		want     int
+		wantErr  error
+	}{
+		{name: "first call", calls: 2, want: 1},
+		{name: "last call", calls: 2, consumed: 1, want: 0},
+		{name: "used up", calls: 2, consumed: 2, wantErr: x402.ErrAccessTokenExhausted},
+		{name: "unknown token", calls: 2, id: "other", wantErr: x402.ErrAccessTokenExhausted},
+		{name: "expired", calls: 2, advance: time.Minute, wantErr: x402.ErrAccessTokenExhausted},
+	}
+
+	for _, tt := range tests {
+		for _, kind := range []string{"memory", "sql"} {
+			t.Run(tt.name+"/"+kind, func(t *testing.T) {
+				ctx := context.Background()
+				clock := sqltest.NewClock()
+				store := newStores(t, clock)[kind]
+				if err := store.Issue(ctx, "token", tt.calls, clock.Now().Add(time.Minute)); err != nil {
+					t.Fatalf("Issue() error = %v", err)
+				}
+				for range tt.consumed {
+					if _, err := store.Consume(ctx, "token"); err != nil {
+						t.Fatalf("Consume() error = %v", err)
+					}
+				}
+
+				id := tt.id
+				if id == "" {
+					id = "token"
+				}
+				clock.Advance(tt.advance)
+				remaining, err := store.Consume(ctx, id)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Consume() error = %v, want %v", err, tt.wantErr)
+				}
+				if err == nil && remaining != tt.want {
+					t.Errorf("Consume() = %d, want %d", remaining, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestMemoryStore_RemovesExpired(t *testing.T) {
+	ctx := context.Background()
+	clock := sqltest.NewClock()
+	store := NewMemoryStore()
+	store.now = clock.Now
+
+	_ = store.Issue(ctx, "short", 1, clock.Now().Add(time.Second))
+	clock.Advance(time.Minute)
+	_ = store.Issue(ctx, "long", 1, clock.Now().Add(time.Hour))
+
+	if store.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", store.Len())
+	}
+}
+
+func TestSQLStore_DeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	clock := sqltest.NewClock()
+	store := NewSQLStore(sqltest.Open(t))
+	store.now = clock.Now
+	if err := store.CreateTable(ctx); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+
+	_ = store.Issue(ctx, "short", 1, clock.Now().Add(time.Second))
+	_ = store.Issue(ctx, "long", 1, clock.Now().Add(time.Hour))
+	clock.Advance(time.Minute)
+
+	deleted, err := store.DeleteExpired(ctx)
+	if err != nil {
+		t.Fatalf("DeleteExpired() error = %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("DeleteExpired() = %d, want 1", deleted)
+	}
+}
@@ -0,0 +1,84 @@
+package chi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+	"github.com/dexfra-fun/x402-go/pkg/x402test"
+	"github.com/go-chi/chi/v5"
+	"github.com/shopspring/decimal"
+)
+
+type fixedPrice decimal.Decimal
+
+func (p fixedPrice) GetPrice(context.Context, localx402.Resource) (decimal.Decimal, error) {
+	return decimal.Decimal(p), nil
+}
+
+func TestMiddleware_SettleAfterHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		handlerStatus int
+		settleError   string
+		wantStatus    int
+		wantSettled   int
+		wantHeader    bool
+		wantBody      string
+	}{
+		{"handler succeeds", http.StatusOK, "", http.StatusOK, 1, true, "content"},
+		{"handler fails", http.StatusInternalServerError, "", http.StatusInternalServerError, 0, false, "content"},
+		{"client error", http.StatusNotFound, "", http.StatusNotFound, 0, false, "content"},
+		{"settlement fails", http.StatusOK, "insufficient_funds", http.StatusPaymentRequired, 1, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := x402test.NewFacilitator(t, x402test.WithBehavior(x402test.Behavior{SettleErrorReason: tt.settleError}))
+
+			router := chi.NewRouter()
+			router.Use(NewMiddleware(&localx402.Config{
+				RecipientAddress:   "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+				Network:            "base-sepolia",
+				FacilitatorURL:     f.URL,
+				PricingStrategy:    fixedPrice(decimal.RequireFromString("0.01")),
+				SettleAfterHandler: true,
+			}))
+			router.Get("/api", func(w http.ResponseWriter, r *http.Request) {
+				if _, ok := GetPaymentInfo(r.Context()); !ok {
+					t.Error("expected payment info in handler context")
+				}
+				w.Header().Set("X-Handler", "yes")
+				w.WriteHeader(tt.handlerStatus)
+				_, _ = w.Write([]byte("content"))
+			})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api", nil))
+			requirements := x402test.PaymentRequirements(t, rec.Body)
+
+			req := httptest.NewRequest(http.MethodGet, "/api", nil)
+			req.Header.Set(localx402.HeaderPayment, x402test.PaymentHeader(t, requirements[0]))
+			rec = httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := f.Count(x402test.PathSettle); got != tt.wantSettled {
+				t.Errorf("settle calls = %d, want %d", got, tt.wantSettled)
+			}
+			if got := rec.Header().Get(localx402.HeaderPaymentResponse) != ""; got != tt.wantHeader {
+				t.Errorf("X-Payment-Response present = %v, want %v", got, tt.wantHeader)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+			if tt.wantBody == "" && rec.Header().Get("X-Handler") != "" {
+				t.Error("handler headers leaked into the settlement error response")
+			}
+		})
+	}
+}
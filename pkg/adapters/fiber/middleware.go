@@ -18,7 +18,7 @@ func NewMiddleware(config *localx402.Config) fiber.Handler {
 	// Create common handler
 	handler, err := common.NewHandler(config)
 	if err != nil {
-		config.Logger.Errorf("[x402-fiber] Failed to create middleware: %v", err)
+		config.Log().Error("Failed to create middleware", "adapter", "fiber", "error", err)
 		return func(c *fiber.Ctx) error {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Payment middleware configuration error",
@@ -29,9 +29,12 @@ func NewMiddleware(config *localx402.Config) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Extract resource from Fiber context
 		resource := localx402.Resource{
-			Path:   c.Path(),
-			Method: c.Method(),
-			Params: make(map[string]string),
+			Path:                c.Path(),
+			Method:              c.Method(),
+			Params:              make(map[string]string),
+			RequestID:           c.Get(localx402.HeaderRequestID),
+			AccessToken:         c.Get(localx402.HeaderAccessToken),
+			CreditAuthorization: c.Get(localx402.HeaderCreditAuthorization),
 		}
 
 		// Extract query parameters
@@ -47,10 +50,7 @@ func NewMiddleware(config *localx402.Config) fiber.Handler {
 
 		// Handle errors
 		if result.Error != nil {
-			return c.Status(result.StatusCode).JSON(fiber.Map{
-				"x402Version": 1,
-				"error":       result.ErrorMessage,
-			})
+			return writeError(c, &result)
 		}
 
 		// Handle payment required
@@ -59,7 +59,7 @@ func NewMiddleware(config *localx402.Config) fiber.Handler {
 			response := map[string]any{
 				"x402Version": 1,
 				"error":       "Payment required for this resource",
-				"accepts":     []any{result.Requirement},
+				"accepts":     result.Requirements,
 			}
 
 			c.Set("Content-Type", "application/json")
@@ -76,17 +76,71 @@ func NewMiddleware(config *localx402.Config) fiber.Handler {
 			c.Locals(settlementInfoKey, result.Settlement)
 			encoded, err := localx402.EncodeSettlement(*result.Settlement)
 			if err != nil {
-				config.Logger.Errorf("[x402-fiber] Failed to encode settlement: %v", err)
+				config.Slog.Error("Failed to encode settlement", "adapter", "fiber", "error", err)
 			} else {
 				c.Set("X-Payment-Response", encoded)
 			}
 		}
+		for key, value := range result.ResponseHeaders() {
+			c.Set(key, value)
+		}
+
+		// Settle after the handler succeeds (SettleAfterHandler mode)
+		if result.SettlementPending {
+			return serveAndSettle(c, handler, result)
+		}
 
 		// Payment verified (or free endpoint) - proceed with request
 		return c.Next()
 	}
 }
 
+// writeError writes the JSON error response for a failed result.
+func writeError(c *fiber.Ctx, result *common.PaymentResult) error {
+	if retryAfter := result.RetryAfterHeader(); retryAfter != "" {
+		c.Set("Retry-After", retryAfter)
+	}
+	return c.Status(result.StatusCode).JSON(result.ErrorResponse())
+}
+
+// serveAndSettle runs the remaining handlers and settles the pending payment only
+// if they succeeded. Fiber buffers responses, so the status can be inspected after c.Next.
+func serveAndSettle(c *fiber.Ctx, handler *common.Handler, pending common.PaymentResult) error {
+	if err := c.Next(); err != nil {
+		// Let the error handler produce the response; nothing is settled
+		handler.Abandon(c.Context(), pending)
+		return err
+	}
+
+	status := c.Response().StatusCode()
+	if !handler.ShouldSettle(status) {
+		handler.GetConfig().Slog.Info("Handler failed, payment not settled",
+			"adapter", "fiber", "route", c.Path(), "status", status, "payer", pending.Payer)
+		handler.Abandon(c.Context(), pending)
+		return nil
+	}
+
+	result := handler.Settle(c.Context(), pending)
+	if result.Error != nil {
+		c.Response().Reset()
+		return writeError(c, &result)
+	}
+
+	if result.Settlement != nil {
+		c.Locals(settlementInfoKey, result.Settlement)
+		encoded, err := localx402.EncodeSettlement(*result.Settlement)
+		if err != nil {
+			handler.GetConfig().Slog.Error("Failed to encode settlement", "adapter", "fiber", "error", err)
+		} else {
+			c.Set(localx402.HeaderPaymentResponse, encoded)
+		}
+	}
+	for key, value := range result.ResponseHeaders() {
+		c.Set(key, value)
+	}
+	return nil
+}
+
 // GetPaymentInfo retrieves payment information from the Fiber context.
 func GetPaymentInfo(c *fiber.Ctx) (*localx402.PaymentInfo, bool) {
 	if info := c.Locals(paymentInfoKey); info != nil {
@@ -0,0 +1,98 @@
+package fiber
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+	"github.com/dexfra-fun/x402-go/pkg/x402test"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+)
+
+type fixedPrice decimal.Decimal
+
+func (p fixedPrice) GetPrice(context.Context, localx402.Resource) (decimal.Decimal, error) {
+	return decimal.Decimal(p), nil
+}
+
+func TestMiddleware_SettleAfterHandler(t *testing.T) {
+	errHandler := errors.New("handler error")
+	tests := []struct {
+		name          string
+		handlerStatus int
+		handlerErr    error
+		settleError   string
+		wantStatus    int
+		wantSettled   int
+		wantHeader    bool
+		wantBody      string
+	}{
+		{"handler succeeds", http.StatusOK, nil, "", http.StatusOK, 1, true, "content"},
+		{"handler fails", http.StatusInternalServerError, nil, "", http.StatusInternalServerError, 0, false, "content"},
+		{"client error", http.StatusNotFound, nil, "", http.StatusNotFound, 0, false, "content"},
+		{"handler returns error", http.StatusOK, errHandler, "", http.StatusInternalServerError, 0, false, errHandler.Error()},
+		{"settlement fails", http.StatusOK, nil, "insufficient_funds", http.StatusPaymentRequired, 1, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := x402test.NewFacilitator(t, x402test.WithBehavior(x402test.Behavior{SettleErrorReason: tt.settleError}))
+
+			app := fiber.New()
+			app.Use(NewMiddleware(&localx402.Config{
+				RecipientAddress:   "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+				Network:            "base-sepolia",
+				FacilitatorURL:     f.URL,
+				PricingStrategy:    fixedPrice(decimal.RequireFromString("0.01")),
+				SettleAfterHandler: true,
+			}))
+			app.Get("/api", func(c *fiber.Ctx) error {
+				if _, ok := GetPaymentInfo(c); !ok {
+					t.Error("expected payment info in handler context")
+				}
+				if tt.handlerErr != nil {
+					return tt.handlerErr
+				}
+				c.Set("X-Handler", "yes")
+				return c.Status(tt.handlerStatus).SendString("content")
+			})
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api", nil))
+			if err != nil {
+				t.Fatalf("unpaid request: %v", err)
+			}
+			requirements := x402test.PaymentRequirements(t, resp.Body)
+			_ = resp.Body.Close()
+
+			req := httptest.NewRequest(http.MethodGet, "/api", nil)
+			req.Header.Set(localx402.HeaderPayment, x402test.PaymentHeader(t, requirements[0]))
+			resp, err = app.Test(req)
+			if err != nil {
+				t.Fatalf("paid request: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := f.Count(x402test.PathSettle); got != tt.wantSettled {
+				t.Errorf("settle calls = %d, want %d", got, tt.wantSettled)
+			}
+			if got := resp.Header.Get(localx402.HeaderPaymentResponse) != ""; got != tt.wantHeader {
+				t.Errorf("X-Payment-Response present = %v, want %v", got, tt.wantHeader)
+			}
+			if tt.wantBody != "" && string(body) != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+			if tt.wantBody == "" && resp.Header.Get("X-Handler") != "" {
+				t.Error("handler headers leaked into the settlement error response")
+			}
+		})
+	}
+}
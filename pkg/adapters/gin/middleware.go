@@ -20,7 +20,7 @@ func NewMiddleware(config *localx402.Config) gin.HandlerFunc {
 	// Create common handler
 	handler, err := common.NewHandler(config)
 	if err != nil {
-		config.Logger.Errorf("[x402-gin] Failed to create middleware: %v", err)
+		config.Log().Error("Failed to create middleware", "adapter", "gin", "error", err)
 		// Return a middleware that always returns error
 		return func(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -39,17 +39,14 @@ func NewMiddleware(config *localx402.Config) gin.HandlerFunc {
 
 		// Handle errors
 		if result.Error != nil {
-			c.AbortWithStatusJSON(result.StatusCode, gin.H{
-				"x402Version": 1,
-				"error":       result.ErrorMessage,
-			})
+			abortWithError(c, &result)
 			return
 		}
 
 		// Handle payment required
 		if result.RequirementNeeded {
-			if writeErr := localx402.WritePaymentRequired(c.Writer, *result.Requirement); writeErr != nil {
-				config.Logger.Errorf("[x402-gin] Failed to write payment required: %v", writeErr)
+			if writeErr := localx402.WritePaymentRequired(c.Writer, result.Requirements...); writeErr != nil {
+				config.Slog.Error("Failed to write payment required", "adapter", "gin", "error", writeErr)
 			}
 			c.Abort()
 			return
@@ -64,15 +61,102 @@ func NewMiddleware(config *localx402.Config) gin.HandlerFunc {
 		if result.Settlement != nil {
 			c.Set(settlementInfoKey, result.Settlement)
 			if err := localx402.SetPaymentResponseHeader(c.Writer, *result.Settlement); err != nil {
-				config.Logger.Errorf("[x402-gin] Failed to set payment response header: %v", err)
+				config.Slog.Error("Failed to set payment response header", "adapter", "gin", "error", err)
 			}
 		}
+		for key, value := range result.ResponseHeaders() {
+			c.Writer.Header().Set(key, value)
+		}
+
+		// Settle after the handler succeeds (SettleAfterHandler mode)
+		if result.SettlementPending {
+			serveAndSettle(c, handler, result)
+			return
+		}
 
 		// Payment verified (or free endpoint) - proceed with request
 		c.Next()
 	}
 }
 
+// serveAndSettle runs the remaining handlers against a buffered writer and
+// settles the pending payment only if they succeeded.
+func serveAndSettle(c *gin.Context, handler *common.Handler, pending common.PaymentResult) {
+	original := c.Writer
+	buffered := &bufferedWriter{ResponseWriter: original, buffer: common.NewBufferedResponseWriter()}
+
+	c.Writer = buffered
+	c.Next()
+	c.Writer = original
+
+	result := handler.CompleteDeferred(c.Request.Context(), original, buffered.buffer, pending)
+	if result.Error != nil {
+		abortWithError(c, &result)
+		return
+	}
+	if result.Settlement != nil {
+		c.Set(settlementInfoKey, result.Settlement)
+	}
+}
+
+// abortWithError writes the JSON error response for a failed result.
+func abortWithError(c *gin.Context, result *common.PaymentResult) {
+	if retryAfter := result.RetryAfterHeader(); retryAfter != "" {
+		c.Header("Retry-After", retryAfter)
+	}
+	c.AbortWithStatusJSON(result.StatusCode, result.ErrorResponse())
+}
+
+// bufferedWriter captures the response written by downstream handlers.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	buffer *common.BufferedResponseWriter
+}
+
+// Header implements http.ResponseWriter.
+func (w *bufferedWriter) Header() http.Header {
+	return w.buffer.Header()
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.buffer.WriteHeader(code)
+}
+
+// WriteHeaderNow implements gin.ResponseWriter. The status is sent when the buffer is copied.
+func (*bufferedWriter) WriteHeaderNow() {}
+
+// Write implements http.ResponseWriter.
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.buffer.Write(data)
+}
+
+// WriteString implements gin.ResponseWriter.
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.buffer.Write([]byte(s))
+}
+
+// Status implements gin.ResponseWriter.
+func (w *bufferedWriter) Status() int {
+	return w.buffer.Status()
+}
+
+// Size implements gin.ResponseWriter.
+func (w *bufferedWriter) Size() int {
+	if !w.buffer.Written() {
+		return -1
+	}
+	return len(w.buffer.Body())
+}
+
+// Written implements gin.ResponseWriter.
+func (w *bufferedWriter) Written() bool {
+	return w.buffer.Written()
+}
+
+// Flush implements http.Flusher. Buffered responses are sent once the handler returns.
+func (*bufferedWriter) Flush() {}
+
 // GetPaymentInfo retrieves payment information from the Gin context.
 func GetPaymentInfo(c *gin.Context) (*localx402.PaymentInfo, bool) {
 	if info, exists := c.Get(paymentInfoKey); exists {
@@ -0,0 +1,91 @@
+package gin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+type fixedPrice decimal.Decimal
+
+func (p fixedPrice) GetPrice(context.Context, localx402.Resource) (decimal.Decimal, error) {
+	return decimal.Decimal(p), nil
+}
+
+func TestMiddleware_SettleAfterHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var settled atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/verify", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"isValid": true, "payer": "0xpayer"})
+	})
+	mux.HandleFunc("/settle", func(w http.ResponseWriter, _ *http.Request) {
+		settled.Add(1)
+		_ = json.NewEncoder(w).Encode(x402.SettlementResponse{Success: true, Transaction: "0xtx"})
+	})
+	facilitator := httptest.NewServer(mux)
+	defer facilitator.Close()
+
+	router := gin.New()
+	router.Use(NewMiddleware(&localx402.Config{
+		RecipientAddress:   "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+		Network:            "base-sepolia",
+		FacilitatorURL:     facilitator.URL,
+		PricingStrategy:    fixedPrice(decimal.RequireFromString("0.01")),
+		SettleAfterHandler: true,
+	}))
+	router.GET("/ok", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": "paid"})
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "boom"})
+	})
+
+	header, err := localx402.EncodePaymentPayload(x402.PaymentPayload{
+		X402Version: 1, Scheme: "exact", Network: "base-sepolia", Payload: x402.EVMPayload{},
+	})
+	if err != nil {
+		t.Fatalf("EncodePaymentPayload() error = %v", err)
+	}
+
+	tests := []struct {
+		path        string
+		wantStatus  int
+		wantSettled int32
+		wantBody    string
+	}{
+		{"/fail", http.StatusInternalServerError, 0, `{"error":"boom"}`},
+		{"/ok", http.StatusOK, 1, `{"data":"paid"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(localx402.HeaderPayment, header)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := settled.Load(); got != tt.wantSettled {
+				t.Errorf("settle calls = %d, want %d", got, tt.wantSettled)
+			}
+			if rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+			if got := rec.Header().Get("Content-Type"); got == "" {
+				t.Error("expected handler Content-Type to be copied")
+			}
+		})
+	}
+}
@@ -22,7 +22,7 @@ func NewMiddleware(config *localx402.Config) func(http.Handler) http.Handler {
 	// Create common handler
 	handler, err := common.NewHandler(config)
 	if err != nil {
-		config.Logger.Errorf("[x402-http] Failed to create middleware: %v", err)
+		config.Log().Error("Failed to create middleware", "adapter", "http", "error", err)
 		// Return a middleware that always returns error
 		return func(_ http.Handler) http.Handler {
 			return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
@@ -45,14 +45,16 @@ func NewMiddleware(config *localx402.Config) func(http.Handler) http.Handler {
 
 			// Handle errors
 			if result.Error != nil {
-				http.Error(w, result.ErrorMessage, result.StatusCode)
+				if writeErr := result.WriteError(w); writeErr != nil {
+					config.Slog.Error("Failed to write payment error", "adapter", "http", "error", writeErr)
+				}
 				return
 			}
 
 			// Handle payment required
 			if result.RequirementNeeded {
-				if writeErr := localx402.WritePaymentRequired(w, *result.Requirement); writeErr != nil {
-					config.Logger.Errorf("[x402-http] Failed to write payment required: %v", writeErr)
+				if writeErr := localx402.WritePaymentRequired(w, result.Requirements...); writeErr != nil {
+					config.Slog.Error("Failed to write payment required", "adapter", "http", "error", writeErr)
 				}
 				return
 			}
@@ -67,13 +69,29 @@ func NewMiddleware(config *localx402.Config) func(http.Handler) http.Handler {
 			if result.Settlement != nil {
 				ctx = context.WithValue(ctx, settlementInfoKey, result.Settlement)
 				if err := localx402.SetPaymentResponseHeader(w, *result.Settlement); err != nil {
-					config.Logger.Errorf("[x402-http] Failed to set payment response header: %v", err)
+					config.Slog.Error("Failed to set payment response header", "adapter", "http", "error", err)
 				}
 			}
+			for key, value := range result.ResponseHeaders() {
+				w.Header().Set(key, value)
+			}
 
 			// Update request with new context
 			r = r.WithContext(ctx)
 
+			// Settle after the handler succeeds (SettleAfterHandler mode)
+			if result.SettlementPending {
+				buffered := common.NewBufferedResponseWriter()
+				next.ServeHTTP(buffered, r)
+
+				if settled := handler.CompleteDeferred(ctx, w, buffered, result); settled.Error != nil {
+					if writeErr := settled.WriteError(w); writeErr != nil {
+						config.Slog.Error("Failed to write payment error", "adapter", "http", "error", writeErr)
+					}
+				}
+				return
+			}
+
 			// Payment verified (or free endpoint) - proceed with request
 			next.ServeHTTP(w, r)
 		})
@@ -0,0 +1,672 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/pkg/client"
+	"github.com/dexfra-fun/x402-go/pkg/credits"
+	"github.com/dexfra-fun/x402-go/pkg/facilitator"
+	"github.com/dexfra-fun/x402-go/pkg/nonce"
+	evmsigner "github.com/dexfra-fun/x402-go/pkg/signers/evm"
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+	"github.com/dexfra-fun/x402-go/pkg/x402test"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type fixedPrice decimal.Decimal
+
+func (p fixedPrice) GetPrice(context.Context, localx402.Resource) (decimal.Decimal, error) {
+	return decimal.Decimal(p), nil
+}
+
+// fakeFacilitator accepts every payment and counts settlements.
+func fakeFacilitator(t *testing.T, settleSuccess bool) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var settled atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/verify", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"isValid": true, "payer": "0xpayer"})
+	})
+	mux.HandleFunc("/settle", func(w http.ResponseWriter, _ *http.Request) {
+		settled.Add(1)
+		response := x402.SettlementResponse{Success: settleSuccess, Network: "base-sepolia", Payer: "0xpayer"}
+		if settleSuccess {
+			response.Transaction = "0xtx"
+		} else {
+			response.ErrorReason = "insufficient_funds"
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &settled
+}
+
+func paymentHeader(t *testing.T) string {
+	t.Helper()
+
+	header, err := localx402.EncodePaymentPayload(x402.PaymentPayload{
+		X402Version: 1,
+		Scheme:      "exact",
+		Network:     "base-sepolia",
+		Payload: x402.EVMPayload{Authorization: x402.EVMAuthorization{
+			From:  "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23",
+			Nonce: "0x0101010101010101010101010101010101010101010101010101010101010101",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("EncodePaymentPayload() error = %v", err)
+	}
+	return header
+}
+
+func TestMiddleware_SettleAfterHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		handlerStatus int
+		settleSuccess bool
+		wantStatus    int
+		wantSettled   int32
+		wantHeader    bool
+		wantBody      string
+	}{
+		{"handler succeeds", http.StatusOK, true, http.StatusOK, 1, true, "content"},
+		{"handler fails", http.StatusInternalServerError, true, http.StatusInternalServerError, 0, false, "content"},
+		{"client error", http.StatusNotFound, true, http.StatusNotFound, 0, false, "content"},
+		{"settlement fails", http.StatusOK, false, http.StatusPaymentRequired, 1, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facilitator, settled := fakeFacilitator(t, tt.settleSuccess)
+
+			middleware := NewMiddleware(&localx402.Config{
+				RecipientAddress:   "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+				Network:            "base-sepolia",
+				FacilitatorURL:     facilitator.URL,
+				PricingStrategy:    fixedPrice(decimal.RequireFromString("0.01")),
+				SettleAfterHandler: true,
+			})
+
+			handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if _, ok := GetPaymentInfo(r.Context()); !ok {
+					t.Error("expected payment info in handler context")
+				}
+				w.Header().Set("X-Handler", "yes")
+				w.WriteHeader(tt.handlerStatus)
+				_, _ = w.Write([]byte("content"))
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api", nil)
+			req.Header.Set(localx402.HeaderPayment, paymentHeader(t))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := settled.Load(); got != tt.wantSettled {
+				t.Errorf("settle calls = %d, want %d", got, tt.wantSettled)
+			}
+			if got := rec.Header().Get(localx402.HeaderPaymentResponse) != ""; got != tt.wantHeader {
+				t.Errorf("X-Payment-Response present = %v, want %v", got, tt.wantHeader)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+			if tt.wantBody == "" && rec.Header().Get("X-Handler") != "" {
+				t.Error("handler headers leaked into the settlement error response")
+			}
+		})
+	}
+}
+
+func TestMiddleware_RejectsDuplicatePayments(t *testing.T) {
+	var verified atomic.Int32
+	settling := make(chan struct{})
+	release := make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/verify", func(w http.ResponseWriter, _ *http.Request) {
+		verified.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"isValid": true, "payer": "0xpayer"})
+	})
+	mux.HandleFunc("/settle", func(w http.ResponseWriter, _ *http.Request) {
+		close(settling)
+		<-release
+		_ = json.NewEncoder(w).Encode(x402.SettlementResponse{Success: true, Transaction: "0xtx"})
+	})
+	facilitator := httptest.NewServer(mux)
+	defer facilitator.Close()
+
+	handler := NewMiddleware(&localx402.Config{
+		RecipientAddress: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+		Network:          "base-sepolia",
+		FacilitatorURL:   facilitator.URL,
+		PricingStrategy:  fixedPrice(decimal.RequireFromString("0.01")),
+		NonceStore:       nonce.NewMemoryStore(0),
+	})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	header := paymentHeader(t)
+	send := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/api", nil)
+		req.Header.Set(localx402.HeaderPayment, header)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	first := make(chan int)
+	go func() { first <- send() }()
+	<-settling
+
+	if code := send(); code != http.StatusConflict {
+		t.Errorf("duplicate while in flight: status = %d, want 409", code)
+	}
+
+	close(release)
+	if code := <-first; code != http.StatusOK {
+		t.Errorf("original payment: status = %d, want 200", code)
+	}
+	if code := send(); code != http.StatusPaymentRequired {
+		t.Errorf("replay after settlement: status = %d, want 402", code)
+	}
+	if got := verified.Load(); got != 1 {
+		t.Errorf("verify calls = %d, want 1", got)
+	}
+}
+
+// inProcessFacilitator is a facilitator.Interface that never leaves the process.
+type inProcessFacilitator struct {
+	valid   bool
+	settled atomic.Int32
+}
+
+func (f *inProcessFacilitator) Verify(
+	context.Context, x402.PaymentPayload, x402.PaymentRequirement,
+) (*facilitator.VerifyResponse, error) {
+	if !f.valid {
+		return &facilitator.VerifyResponse{InvalidReason: "invalid_signature"}, nil
+	}
+	return &facilitator.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+}
+
+func (f *inProcessFacilitator) Settle(
+	context.Context, x402.PaymentPayload, x402.PaymentRequirement,
+) (*x402.SettlementResponse, error) {
+	f.settled.Add(1)
+	return &x402.SettlementResponse{Success: true, Transaction: "0xtx", Network: "base-sepolia"}, nil
+}
+
+func (*inProcessFacilitator) Supported(context.Context) (*facilitator.SupportedResponse, error) {
+	return &facilitator.SupportedResponse{}, nil
+}
+
+func TestMiddleware_CustomFacilitator(t *testing.T) {
+	tests := []struct {
+		name        string
+		valid       bool
+		wantStatus  int
+		wantSettled int32
+	}{
+		{"valid payment", true, http.StatusOK, 1},
+		{"invalid payment", false, http.StatusPaymentRequired, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			custom := &inProcessFacilitator{valid: tt.valid}
+			handler := NewMiddleware(&localx402.Config{
+				RecipientAddress: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+				Network:          "base-sepolia",
+				Facilitator:      custom,
+				PricingStrategy:  fixedPrice(decimal.RequireFromString("0.01")),
+			})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api", nil)
+			req.Header.Set(localx402.HeaderPayment, paymentHeader(t))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := custom.settled.Load(); got != tt.wantSettled {
+				t.Errorf("settle calls = %d, want %d", got, tt.wantSettled)
+			}
+		})
+	}
+}
+
+func TestMiddleware_DegradedMode(t *testing.T) {
+	var verifies atomic.Int32
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		verifies.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(down.Close)
+
+	handler := NewMiddleware(&localx402.Config{
+		RecipientAddress: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+		Network:          "base-sepolia",
+		FacilitatorURL:   down.URL,
+		PricingStrategy:  fixedPrice(decimal.RequireFromString("0.01")),
+		Retry:            localx402.RetryPolicy{MaxAttempts: 1},
+		CircuitBreaker:   &localx402.BreakerConfig{ConsecutiveFailures: 1, OpenTimeout: time.Minute},
+		Degraded:         localx402.DegradedPolicy{FreeRoutes: []string{"/public/*"}},
+	})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("content"))
+	}))
+
+	tests := []struct {
+		name           string
+		path           string
+		paid           bool
+		wantStatus     int
+		wantRetryAfter bool
+	}{
+		{"facilitator failure opens the circuit", "/api", true, http.StatusServiceUnavailable, true},
+		{"open circuit fails fast", "/api", false, http.StatusServiceUnavailable, true},
+		{"allowlisted route is served free", "/public/status", false, http.StatusOK, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.paid {
+				req.Header.Set(localx402.HeaderPayment, paymentHeader(t))
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Retry-After") != ""; got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want set %v", rec.Header().Get("Retry-After"), tt.wantRetryAfter)
+			}
+		})
+	}
+
+	if got := verifies.Load(); got != 1 {
+		t.Errorf("facilitator calls = %d, want 1 before the circuit opened", got)
+	}
+}
+
+func TestMiddleware_Tracing(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	f := x402test.NewFacilitator(t)
+
+	handler := NewMiddleware(&localx402.Config{
+		RecipientAddress: "9xQeWvG816bUx9EPjHmaT23yvVM2ZWbrrpZb9PusVFin",
+		Network:          "solana-devnet",
+		FacilitatorURL:   f.URL,
+		PricingStrategy:  fixedPrice(decimal.RequireFromString("0.01")),
+		TracerProvider:   provider,
+	})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("content"))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api", nil))
+	requirements := x402test.PaymentRequirements(t, rec.Body)
+	req := httptest.NewRequest(http.MethodGet, "/api", nil)
+	req.Header.Set(localx402.HeaderPayment, x402test.PaymentHeader(t, requirements[0]))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("paid request: status = %d", rec.Code)
+	}
+
+	spans := make(map[string][]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = append(spans[span.Name()], span)
+	}
+	for _, name := range []string{
+		"x402.ProcessPayment", "x402.GetPrice", "x402.ResolveFeePayer",
+		"x402.Verify", "x402.Settle", "x402.facilitator.verify", "x402.facilitator.settle",
+	} {
+		if len(spans[name]) == 0 {
+			t.Fatalf("no %s span; got %v", name, spans)
+		}
+	}
+
+	if !hasEvent(spans["x402.ResolveFeePayer"][0], localx402.EventCacheMiss) {
+		t.Error("first fee payer lookup has no cache miss event")
+	}
+	if !hasEvent(spans["x402.ResolveFeePayer"][1], localx402.EventCacheHit) {
+		t.Error("second fee payer lookup has no cache hit event")
+	}
+
+	verify := spans["x402.facilitator.verify"][0]
+	if verify.Parent().SpanID() != spans["x402.Verify"][0].SpanContext().SpanID() {
+		t.Error("facilitator verify span is not a child of the Verify span")
+	}
+	paid := spans["x402.ProcessPayment"][1]
+	if got := attributeValue(paid, localx402.AttrTransaction); got == "" {
+		t.Error("ProcessPayment span has no transaction attribute")
+	}
+	if got := attributeValue(paid, localx402.AttrPayer); got == "" {
+		t.Error("ProcessPayment span has no payer attribute")
+	}
+
+	for _, r := range f.Requests() {
+		if r.Path != x402test.PathVerify {
+			continue
+		}
+		carrier := propagation.HeaderCarrier(r.Header)
+		remote := trace.SpanContextFromContext(propagation.TraceContext{}.Extract(context.Background(), carrier))
+		if remote.TraceID() != verify.SpanContext().TraceID() || remote.SpanID() != verify.SpanContext().SpanID() {
+			t.Errorf("facilitator got trace context %v, want the verify span %v", remote, verify.SpanContext())
+		}
+	}
+}
+
+func TestMiddleware_Events(t *testing.T) {
+	tests := []struct {
+		name     string
+		behavior x402test.Behavior
+		want     []localx402.EventType
+		step     string
+	}{
+		{"settled", x402test.Behavior{}, []localx402.EventType{localx402.EventPaymentVerified, localx402.EventPaymentSettled}, ""},
+		{"invalid", x402test.Behavior{InvalidReason: "invalid_signature"}, []localx402.EventType{localx402.EventPaymentFailed}, localx402.EndpointVerify},
+		{
+			"settle failure", x402test.Behavior{SettleErrorReason: "insufficient_funds"},
+			[]localx402.EventType{localx402.EventPaymentVerified, localx402.EventPaymentFailed}, localx402.EndpointSettle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []localx402.Event
+			dispatcher := localx402.NewEventDispatcher(localx402.WithEventHandler("test",
+				func(_ context.Context, event localx402.Event) error {
+					events = append(events, event)
+					return nil
+				}))
+			f := x402test.NewFacilitator(t, x402test.WithBehavior(tt.behavior))
+			handler := NewMiddleware(&localx402.Config{
+				RecipientAddress: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+				Network:          "base-sepolia",
+				FacilitatorURL:   f.URL,
+				PricingStrategy:  fixedPrice(decimal.RequireFromString("0.01")),
+				Events:           dispatcher,
+			})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("content"))
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api", nil))
+			requirements := x402test.PaymentRequirements(t, rec.Body)
+			req := httptest.NewRequest(http.MethodGet, "/api", nil)
+			req.Header.Set(localx402.HeaderPayment, x402test.PaymentHeader(t, requirements[0]))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			if err := dispatcher.Shutdown(context.Background()); err != nil {
+				t.Fatalf("Shutdown: %v", err)
+			}
+
+			if len(events) != len(tt.want) {
+				t.Fatalf("got %d events, want %v", len(events), tt.want)
+			}
+			for i, eventType := range tt.want {
+				if events[i].Type != eventType {
+					t.Errorf("event %d = %s, want %s", i, events[i].Type, eventType)
+				}
+			}
+			last := events[len(events)-1]
+			if last.Route != "/api" || last.PaymentInfo == nil || last.PaymentInfo.Network != "base-sepolia" {
+				t.Errorf("event = %+v, want the route and payment info", last)
+			}
+			if last.Step != tt.step || (tt.step != "") != (last.ErrorCode != "") {
+				t.Errorf("failed step = %q code %q, want step %q", last.Step, last.ErrorCode, tt.step)
+			}
+			if tt.step == "" && (last.Settlement == nil || last.Settlement.Transaction == "" || last.Payer == "") {
+				t.Errorf("settled event = %+v, want the settlement and payer", last)
+			}
+		})
+	}
+}
+
+func TestMiddleware_AccessTokens(t *testing.T) {
+	f := x402test.NewFacilitator(t)
+	var served atomic.Int32
+	handler := NewMiddleware(&localx402.Config{
+		RecipientAddress: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+		Network:          "base-sepolia",
+		FacilitatorURL:   f.URL,
+		PricingStrategy:  fixedPrice(decimal.RequireFromString("0.01")),
+		AccessTokens: &localx402.AccessTokenConfig{
+			Secret: []byte("0123456789abcdef0123456789abcdef"),
+			Calls:  2,
+			Scope:  func(localx402.Resource) string { return "/api/*" },
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		served.Add(1)
+		_, _ = w.Write([]byte("content"))
+	}))
+
+	get := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set(localx402.HeaderAccessToken, token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/api/1", "")
+	requirements := x402test.PaymentRequirements(t, rec.Body)
+	req := httptest.NewRequest(http.MethodGet, "/api/1", nil)
+	req.Header.Set(localx402.HeaderPayment, x402test.PaymentHeader(t, requirements[0]))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	token := rec.Header().Get(localx402.HeaderAccessToken)
+	if rec.Code != http.StatusOK || token == "" {
+		t.Fatalf("paid request: status = %d, token = %q", rec.Code, token)
+	}
+	facilitatorRequests := len(f.Requests())
+
+	// Each use returns the token for the next call
+	for _, path := range []string{"/api/2", "/api/3"} {
+		rec = get(path, token)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s with token: status = %d", path, rec.Code)
+		}
+		token = rec.Header().Get(localx402.HeaderAccessToken)
+	}
+	if got := len(f.Requests()); got != facilitatorRequests {
+		t.Errorf("token requests reached the facilitator %d times", got-facilitatorRequests)
+	}
+
+	if rec = get("/api/4", token); rec.Code != http.StatusPaymentRequired {
+		t.Errorf("used up token: status = %d, want 402", rec.Code)
+	}
+	if rec = get("/other", token); rec.Code != http.StatusPaymentRequired {
+		t.Errorf("token out of scope: status = %d, want 402", rec.Code)
+	}
+	if got := served.Load(); got != 3 {
+		t.Errorf("handler served %d requests, want 3", got)
+	}
+}
+
+func TestMiddleware_Credits(t *testing.T) {
+	f := x402test.NewFacilitator(t)
+	var served atomic.Int32
+	server := httptest.NewServer(NewMiddleware(&localx402.Config{
+		RecipientAddress: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+		Network:          "base-sepolia",
+		FacilitatorURL:   f.URL,
+		PricingStrategy:  fixedPrice(decimal.RequireFromString("0.01")),
+		Credits: &localx402.CreditsConfig{
+			Store: credits.NewMemoryStore(),
+			TopUp: func(resource localx402.Resource) bool { return resource.Path == "/credits" },
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		served.Add(1)
+		_, _ = w.Write([]byte("content"))
+	})))
+	defer server.Close()
+
+	signer, err := evmsigner.NewSignerFromHex("base-sepolia",
+		"0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatalf("NewSignerFromHex() error = %v", err)
+	}
+	payer := &http.Client{Transport: &client.Transport{Signers: []x402.Signer{signer}, Credits: signer}}
+
+	get := func(c *http.Client, path, authorization string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if authorization != "" {
+			req.Header.Set(localx402.HeaderCreditAuthorization, authorization)
+		}
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	// Top-up routes are always paid, and credit the payment to the payer
+	for _, want := range []string{"0.01", "0.02"} {
+		resp := get(payer, "/credits", "")
+		if got := resp.Header.Get(localx402.HeaderCreditBalance); resp.StatusCode != http.StatusOK || got != want {
+			t.Fatalf("top up: status = %d, balance = %q, want %s", resp.StatusCode, got, want)
+		}
+	}
+	settlements := f.Count(x402test.PathSettle)
+
+	authorization, err := localx402.SignCreditAuthorization(signer, http.MethodGet, "/api/data")
+	if err != nil {
+		t.Fatalf("SignCreditAuthorization() error = %v", err)
+	}
+	resp := get(http.DefaultClient, "/api/data", authorization)
+	if got := resp.Header.Get(localx402.HeaderCreditBalance); resp.StatusCode != http.StatusOK || got != "0.01" {
+		t.Fatalf("paid from credits: status = %d, balance = %q, want 0.01", resp.StatusCode, got)
+	}
+	if resp = get(http.DefaultClient, "/api/data", authorization); resp.StatusCode != http.StatusPaymentRequired {
+		t.Errorf("replayed authorization: status = %d, want 402", resp.StatusCode)
+	}
+	if resp = get(payer, "/api/data", ""); resp.Header.Get(localx402.HeaderCreditBalance) != "0" {
+		t.Errorf("client with credits: balance = %q, want 0", resp.Header.Get(localx402.HeaderCreditBalance))
+	}
+	if got := f.Count(x402test.PathSettle); got != settlements {
+		t.Errorf("credit payments reached the facilitator %d times", got-settlements)
+	}
+
+	// An exhausted balance falls back to a payment
+	resp = get(payer, "/api/data", "")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(localx402.HeaderCreditBalance) != "" {
+		t.Errorf("insufficient balance: status = %d, balance = %q", resp.StatusCode, resp.Header.Get(localx402.HeaderCreditBalance))
+	}
+	if got := f.Count(x402test.PathSettle); got != settlements+1 {
+		t.Errorf("settlements = %d, want the fallback payment settled", got-settlements)
+	}
+	if got := served.Load(); got != 5 {
+		t.Errorf("handler served %d requests, want 5", got)
+	}
+}
+
+func TestMiddleware_CreditsSettleAfterHandler(t *testing.T) {
+	f := x402test.NewFacilitator(t)
+	var fail atomic.Bool
+	server := httptest.NewServer(NewMiddleware(&localx402.Config{
+		RecipientAddress:   "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+		Network:            "base-sepolia",
+		FacilitatorURL:     f.URL,
+		PricingStrategy:    fixedPrice(decimal.RequireFromString("0.01")),
+		SettleAfterHandler: true,
+		Credits: &localx402.CreditsConfig{
+			Store: credits.NewMemoryStore(),
+			TopUp: func(resource localx402.Resource) bool { return resource.Path == "/credits" },
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("content"))
+	})))
+	defer server.Close()
+
+	signer, err := evmsigner.NewSignerFromHex("base-sepolia",
+		"0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatalf("NewSignerFromHex() error = %v", err)
+	}
+	payer := &http.Client{Transport: &client.Transport{Signers: []x402.Signer{signer}, Credits: signer}}
+
+	get := func(path string) *http.Response {
+		t.Helper()
+		resp, err := payer.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	if resp := get("/credits"); resp.Header.Get(localx402.HeaderCreditBalance) != "0.01" {
+		t.Fatalf("top up: status = %d, balance = %q", resp.StatusCode, resp.Header.Get(localx402.HeaderCreditBalance))
+	}
+	settlements := f.Count(x402test.PathSettle)
+
+	// A failed handler does not spend the credit
+	fail.Store(true)
+	if resp := get("/api/data"); resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("failed handler: status = %d, want 500", resp.StatusCode)
+	}
+	fail.Store(false)
+	resp := get("/api/data")
+	if got := resp.Header.Get(localx402.HeaderCreditBalance); resp.StatusCode != http.StatusOK || got != "0" {
+		t.Errorf("paid from credits: status = %d, balance = %q, want 0", resp.StatusCode, got)
+	}
+	if got := f.Count(x402test.PathSettle); got != settlements {
+		t.Errorf("credit payments reached the facilitator %d times", got-settlements)
+	}
+}
+
+func hasEvent(span sdktrace.ReadOnlySpan, name string) bool {
+	for _, event := range span.Events() {
+		if event.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func attributeValue(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,260 @@
+// Package client provides an x402-aware HTTP client that pays 402 Payment Required
+// responses automatically using pluggable payment signers.
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/bytedance/sonic"
+	x402 "github.com/dexfra-fun/x402-go"
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+const (
+	// maxRequirementsBodySize limits how much of a 402 response body is read.
+	maxRequirementsBodySize = 1 << 20
+)
+
+// Transport is an http.RoundTripper that handles x402 payments.
+// When a request receives a 402 response, Transport selects a payment
+// requirement it can satisfy, signs a payload and retries the request
+// with the X-Payment header.
+type Transport struct {
+	// Base is the underlying RoundTripper (optional, defaults to http.DefaultTransport).
+	Base http.RoundTripper
+
+	// Signers are the payment signers available for 402 responses (required).
+	Signers []x402.Signer
+
+	// Credits signs a credit authorization on each request, so servers with
+	// prepaid credits debit the signer's balance instead of answering 402
+	// (optional). If the balance is insufficient, the 402 is paid with Signers.
+	Credits localx402.MessageSigner
+
+	// OnSettlement is called when a paid request returns an X-Payment-Response header (optional).
+	OnSettlement func(req *http.Request, settlement *x402.SettlementResponse)
+
+	// Slog is used for diagnostic output (optional).
+	Slog *slog.Logger
+
+	// Logger is used for diagnostic output if Slog is not set (optional).
+	//
+	// Deprecated: use Slog.
+	Logger localx402.Logger
+}
+
+// NewTransport creates a Transport using http.DefaultTransport and the given signers.
+func NewTransport(signers ...x402.Signer) *Transport {
+	return &Transport{Signers: signers}
+}
+
+// NewClient creates an http.Client that pays 402 responses with the given signers.
+func NewClient(signers ...x402.Signer) *http.Client {
+	return &http.Client{Transport: NewTransport(signers...)}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Requests that already carry a payment are passed through untouched
+	if req.Header.Get(localx402.HeaderPayment) != "" {
+		return t.base().RoundTrip(req)
+	}
+
+	getBody, err := bufferBody(req)
+	if err != nil {
+		return nil, err
+	}
+	first, err := cloneRequest(req, getBody)
+	if err != nil {
+		return nil, err
+	}
+	if t.Credits != nil {
+		authorization, err := localx402.SignCreditAuthorization(t.Credits, req.Method, req.URL.Path)
+		if err != nil {
+			return nil, x402.NewPaymentError(x402.ErrCodeSigningFailed, "sign credit authorization", err)
+		}
+		first.Header.Set(localx402.HeaderCreditAuthorization, authorization)
+	}
+
+	resp, err := t.base().RoundTrip(first)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusPaymentRequired {
+		return resp, nil
+	}
+
+	requirements, err := readRequirements(resp)
+	if err != nil {
+		t.logger().Debug("Ignoring 402 without x402 body", "url", req.URL.String(), "error", err)
+		return resp, nil
+	}
+
+	paid, err := t.buildPaidRequest(req, getBody, requirements)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err = t.base().RoundTrip(paid)
+	if err != nil {
+		return nil, err
+	}
+
+	t.handleSettlement(paid, resp)
+	return resp, nil
+}
+
+// buildPaidRequest selects a requirement, signs it and clones req with the X-Payment header.
+func (t *Transport) buildPaidRequest(
+	req *http.Request,
+	getBody func() (io.ReadCloser, error),
+	requirements *x402.PaymentRequirementsResponse,
+) (*http.Request, error) {
+	signer, requirement, err := SelectPayment(t.Signers, requirements.Accepts)
+	if err != nil {
+		return nil, paymentError(err)
+	}
+
+	t.logger().Info("Paying for request", "url", req.URL.String(),
+		"network", requirement.Network, "asset", requirement.Asset, "amount", requirement.MaxAmountRequired)
+
+	payload, err := signer.Sign(req.Context(), requirement)
+	if err != nil {
+		return nil, x402.NewPaymentError(x402.ErrCodeSigningFailed, "sign payment", err)
+	}
+
+	header, err := localx402.EncodePaymentPayload(*payload)
+	if err != nil {
+		return nil, x402.NewPaymentError(x402.ErrCodeSigningFailed, "encode payment", err)
+	}
+
+	paid, err := cloneRequest(req, getBody)
+	if err != nil {
+		return nil, err
+	}
+	paid.Header.Set(localx402.HeaderPayment, header)
+	return paid, nil
+}
+
+// handleSettlement decodes the X-Payment-Response header and reports it.
+func (t *Transport) handleSettlement(req *http.Request, resp *http.Response) {
+	settlement, err := GetSettlement(resp)
+	if err != nil {
+		t.logger().Error("Invalid payment response header", "url", req.URL.String(), "error", err)
+		return
+	}
+	if settlement == nil {
+		return
+	}
+
+	t.logger().Info("Payment settled", "url", req.URL.String(),
+		"network", settlement.Network, "tx", settlement.Transaction)
+	if t.OnSettlement != nil {
+		t.OnSettlement(req, settlement)
+	}
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *Transport) logger() *slog.Logger {
+	switch {
+	case t.Slog != nil:
+		return t.Slog
+	case t.Logger != nil:
+		return slog.New(localx402.NewLoggerHandler(t.Logger, nil))
+	default:
+		return slog.New(slog.DiscardHandler)
+	}
+}
+
+// GetSettlement decodes the X-Payment-Response header of a response.
+// Returns nil without error if the header is absent.
+func GetSettlement(resp *http.Response) (*x402.SettlementResponse, error) {
+	header := resp.Header.Get(localx402.HeaderPaymentResponse)
+	if header == "" {
+		return nil, nil //nolint:nilnil // absent header is not an error
+	}
+	return localx402.DecodeSettlement(header)
+}
+
+// bufferBody returns a function that returns a fresh copy of req's body, or
+// nil if it has none. The body is read at most once and closed; req itself is
+// not modified, as required of a RoundTripper.
+func bufferBody(req *http.Request) (func() (io.ReadCloser, error), error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil //nolint:nilnil // a request without body needs no copies
+	}
+	defer func() {
+		_ = req.Body.Close()
+	}()
+	if req.GetBody != nil {
+		return req.GetBody, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}, nil
+}
+
+// cloneRequest clones req with a fresh copy of its body from getBody.
+func cloneRequest(req *http.Request, getBody func() (io.ReadCloser, error)) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if getBody == nil {
+		return clone, nil
+	}
+
+	body, err := getBody()
+	if err != nil {
+		return nil, fmt.Errorf("get request body: %w", err)
+	}
+	clone.Body = body
+	clone.GetBody = getBody
+	return clone, nil
+}
+
+// readRequirements reads and decodes the 402 response body.
+func readRequirements(resp *http.Response) (*x402.PaymentRequirementsResponse, error) {
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRequirementsBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("read 402 body: %w", err)
+	}
+	// Restore body so callers can still inspect the 402 response
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	var requirements x402.PaymentRequirementsResponse
+	if err := sonic.Unmarshal(body, &requirements); err != nil {
+		return nil, fmt.Errorf("decode 402 body: %w", err)
+	}
+	if len(requirements.Accepts) == 0 {
+		return nil, x402.ErrInvalidRequirements
+	}
+	return &requirements, nil
+}
+
+// paymentError wraps selection errors in a PaymentError.
+func paymentError(err error) error {
+	switch {
+	case errors.Is(err, x402.ErrAmountExceeded):
+		return x402.NewPaymentError(x402.ErrCodeAmountExceeded, "select payment", err)
+	default:
+		return x402.NewPaymentError(x402.ErrCodeNoValidSigner, "select payment", err)
+	}
+}
@@ -0,0 +1,266 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+type fakeSigner struct {
+	network   string
+	priority  int
+	tokens    []x402.TokenConfig
+	maxAmount *big.Int
+	signed    int
+}
+
+func (s *fakeSigner) Network() string { return s.network }
+
+func (*fakeSigner) Scheme() string { return "exact" }
+
+func (s *fakeSigner) CanSign(req *x402.PaymentRequirement) bool {
+	if req.Network != s.network {
+		return false
+	}
+	for _, token := range s.tokens {
+		if strings.EqualFold(token.Address, req.Asset) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *fakeSigner) Sign(_ context.Context, req *x402.PaymentRequirement) (*x402.PaymentPayload, error) {
+	s.signed++
+	return &x402.PaymentPayload{
+		X402Version: 1,
+		Scheme:      req.Scheme,
+		Network:     req.Network,
+		Payload:     map[string]any{"transaction": "signed"},
+	}, nil
+}
+
+func (s *fakeSigner) GetPriority() int { return s.priority }
+
+func (s *fakeSigner) GetTokens() []x402.TokenConfig { return s.tokens }
+
+func (s *fakeSigner) GetMaxAmount() *big.Int { return s.maxAmount }
+
+func requirement(network, asset, amount string) x402.PaymentRequirement {
+	return x402.PaymentRequirement{
+		Scheme:            "exact",
+		Network:           network,
+		MaxAmountRequired: amount,
+		Asset:             asset,
+		PayTo:             "recipient",
+	}
+}
+
+func TestSelectPayment(t *testing.T) {
+	solana := &fakeSigner{
+		network:  "solana",
+		priority: 2,
+		tokens:   []x402.TokenConfig{{Address: "usdc-sol"}},
+	}
+	base := &fakeSigner{
+		network:  "base",
+		priority: 1,
+		tokens:   []x402.TokenConfig{{Address: "usdc-base"}},
+	}
+	limited := &fakeSigner{
+		network:   "solana",
+		tokens:    []x402.TokenConfig{{Address: "usdc-sol"}},
+		maxAmount: big.NewInt(100),
+	}
+
+	tests := []struct {
+		name        string
+		signers     []x402.Signer
+		accepts     []x402.PaymentRequirement
+		wantSigner  x402.Signer
+		wantNetwork string
+		wantErr     error
+	}{
+		{
+			name:    "signer priority wins over accepts order",
+			signers: []x402.Signer{solana, base},
+			accepts: []x402.PaymentRequirement{
+				requirement("solana", "usdc-sol", "1000"),
+				requirement("base", "usdc-base", "1000"),
+			},
+			wantSigner:  base,
+			wantNetwork: "base",
+		},
+		{
+			name:        "only matching signer is used",
+			signers:     []x402.Signer{solana, base},
+			accepts:     []x402.PaymentRequirement{requirement("solana", "usdc-sol", "1000")},
+			wantSigner:  solana,
+			wantNetwork: "solana",
+		},
+		{
+			name:    "no matching signer",
+			signers: []x402.Signer{solana},
+			accepts: []x402.PaymentRequirement{requirement("polygon", "usdc", "1000")},
+			wantErr: x402.ErrNoValidSigner,
+		},
+		{
+			name:    "amount over limit",
+			signers: []x402.Signer{limited},
+			accepts: []x402.PaymentRequirement{requirement("solana", "usdc-sol", "1000")},
+			wantErr: x402.ErrAmountExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, req, err := SelectPayment(tt.signers, tt.accepts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if signer != tt.wantSigner {
+				t.Errorf("unexpected signer for network %s", signer.Network())
+			}
+			if req.Network != tt.wantNetwork {
+				t.Errorf("expected network %s, got %s", tt.wantNetwork, req.Network)
+			}
+		})
+	}
+}
+
+func newPaidServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		header := r.Header.Get(localx402.HeaderPayment)
+		if header == "" {
+			_ = localx402.WritePaymentRequired(w, requirement("solana", "usdc-sol", "1000"))
+			return
+		}
+
+		payment, err := localx402.DecodePaymentPayload(header)
+		if err != nil || payment.Network != "solana" {
+			http.Error(w, "bad payment", http.StatusBadRequest)
+			return
+		}
+
+		_ = localx402.SetPaymentResponseHeader(w, x402.SettlementResponse{
+			Success:     true,
+			Transaction: "tx123",
+			Network:     "solana",
+			Payer:       "payer",
+		})
+		_, _ = w.Write(body)
+	}))
+}
+
+func TestTransport_PaysPaymentRequired(t *testing.T) {
+	server := newPaidServer(t)
+	defer server.Close()
+
+	signer := &fakeSigner{network: "solana", tokens: []x402.TokenConfig{{Address: "usdc-sol"}}}
+	var settled *x402.SettlementResponse
+	client := &http.Client{Transport: &Transport{
+		Signers: []x402.Signer{signer},
+		OnSettlement: func(_ *http.Request, s *x402.SettlementResponse) {
+			settled = s
+		},
+	}}
+
+	original := io.NopCloser(strings.NewReader("hello"))
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL, original)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "hello" {
+		t.Errorf("expected body to be replayed, got %q", body)
+	}
+	if signer.signed != 1 {
+		t.Errorf("expected one signature, got %d", signer.signed)
+	}
+	if req.Body != original || req.GetBody != nil {
+		t.Error("the caller's request was modified")
+	}
+	if settled == nil || settled.Transaction != "tx123" {
+		t.Errorf("expected settlement tx123, got %+v", settled)
+	}
+
+	settlement, err := GetSettlement(resp)
+	if err != nil || settlement == nil || !settlement.Success {
+		t.Errorf("expected decoded settlement, got %+v (err=%v)", settlement, err)
+	}
+}
+
+func TestTransport_NoValidSigner(t *testing.T) {
+	server := newPaidServer(t)
+	defer server.Close()
+
+	client := NewClient(&fakeSigner{network: "base", tokens: []x402.TokenConfig{{Address: "usdc-base"}}})
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("expected error")
+	}
+
+	var paymentErr *x402.PaymentError
+	if !errors.As(err, &paymentErr) || paymentErr.Code != x402.ErrCodeNoValidSigner {
+		t.Errorf("expected NO_VALID_SIGNER payment error, got %v", err)
+	}
+}
+
+func TestTransport_PassesThroughNonX402(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusPaymentRequired)
+		_, _ = w.Write([]byte("pay me"))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusPaymentRequired || string(body) != "pay me" {
+		t.Errorf("expected untouched 402, got %d %q", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"math/big"
+	"strings"
+
+	x402 "github.com/dexfra-fun/x402-go"
+)
+
+// candidate is a signer paired with a requirement it can satisfy.
+type candidate struct {
+	signer        x402.Signer
+	requirement   *x402.PaymentRequirement
+	tokenPriority int
+	index         int
+}
+
+// less orders candidates by signer priority, token priority, then server preference.
+func (c candidate) less(other candidate) bool {
+	if c.signer.GetPriority() != other.signer.GetPriority() {
+		return c.signer.GetPriority() < other.signer.GetPriority()
+	}
+	if c.tokenPriority != other.tokenPriority {
+		return c.tokenPriority < other.tokenPriority
+	}
+	return c.index < other.index
+}
+
+// SelectPayment picks the signer and requirement to use for a 402 response.
+// Requirements are matched against every signer; the candidate with the best
+// signer priority, then token priority, then position in accepts wins.
+//
+// Returns x402.ErrAmountExceeded if signers matched but all were over their
+// per-call limit, or x402.ErrNoValidSigner if no signer matched at all.
+func SelectPayment(
+	signers []x402.Signer,
+	accepts []x402.PaymentRequirement,
+) (x402.Signer, *x402.PaymentRequirement, error) {
+	var (
+		best     *candidate
+		exceeded bool
+	)
+
+	for i := range accepts {
+		requirement := &accepts[i]
+		for _, signer := range signers {
+			if !signer.CanSign(requirement) {
+				continue
+			}
+			if !withinLimit(signer, requirement) {
+				exceeded = true
+				continue
+			}
+
+			c := candidate{
+				signer:        signer,
+				requirement:   requirement,
+				tokenPriority: tokenPriority(signer, requirement.Asset),
+				index:         i,
+			}
+			if best == nil || c.less(*best) {
+				best = &c
+			}
+		}
+	}
+
+	if best != nil {
+		return best.signer, best.requirement, nil
+	}
+	if exceeded {
+		return nil, nil, x402.ErrAmountExceeded
+	}
+	return nil, nil, x402.ErrNoValidSigner
+}
+
+// withinLimit checks the requirement amount against the signer's per-call limit.
+func withinLimit(signer x402.Signer, requirement *x402.PaymentRequirement) bool {
+	limit := signer.GetMaxAmount()
+	if limit == nil {
+		return true
+	}
+
+	amount, ok := new(big.Int).SetString(requirement.MaxAmountRequired, x402.DecimalBase)
+	if !ok {
+		return false
+	}
+	return amount.Cmp(limit) <= 0
+}
+
+// tokenPriority returns the priority of the signer's token matching asset.
+func tokenPriority(signer x402.Signer, asset string) int {
+	for _, token := range signer.GetTokens() {
+		if strings.EqualFold(token.Address, asset) {
+			return token.Priority
+		}
+	}
+	return 0
+}
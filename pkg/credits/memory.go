@@ -0,0 +1,79 @@
+// Package credits provides BalanceStore implementations that keep x402
+// prepaid credit balances.
+package credits
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/dexfra-fun/x402-go/pkg/x402"
+	"github.com/shopspring/decimal"
+)
+
+// errNegativeAmount is returned when crediting or debiting a negative amount.
+var errNegativeAmount = errors.New("credits: amount must not be negative")
+
+// MemoryStore is an in-process BalanceStore. Balances are lost on restart and
+// not shared between replicas; use SQLStore in production. It remembers every
+// reference it has seen, so it grows with the number of payments.
+type MemoryStore struct {
+	mu         sync.Mutex
+	balances   map[string]decimal.Decimal
+	references map[string]struct{}
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		balances:   make(map[string]decimal.Decimal),
+		references: make(map[string]struct{}),
+	}
+}
+
+var _ x402.BalanceStore = (*MemoryStore)(nil)
+
+// Balance implements x402.BalanceStore.
+func (s *MemoryStore) Balance(_ context.Context, account string) (decimal.Decimal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.balances[account], nil
+}
+
+// Credit implements x402.BalanceStore.
+func (s *MemoryStore) Credit(_ context.Context, account string, amount decimal.Decimal, reference string) (decimal.Decimal, error) {
+	if amount.IsNegative() {
+		return decimal.Zero, errNegativeAmount
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, seen := s.references[reference]; !seen {
+		s.references[reference] = struct{}{}
+		s.balances[account] = s.balances[account].Add(amount)
+	}
+	return s.balances[account], nil
+}
+
+// Debit implements x402.BalanceStore.
+func (s *MemoryStore) Debit(_ context.Context, account string, amount decimal.Decimal, reference string) (decimal.Decimal, error) {
+	if amount.IsNegative() {
+		return decimal.Zero, errNegativeAmount
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, seen := s.references[reference]; seen {
+		return decimal.Zero, x402.ErrDuplicateReference
+	}
+	balance := s.balances[account]
+	if balance.LessThan(amount) {
+		return balance, x402.ErrInsufficientBalance
+	}
+	s.references[reference] = struct{}{}
+	s.balances[account] = balance.Sub(amount)
+	return s.balances[account], nil
+}
@@ -0,0 +1,253 @@
+package credits
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dexfra-fun/x402-go/pkg/x402"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// DefaultTable is the default balance table name used by SQLStore.
+	DefaultTable = "x402_credit_balances"
+	// DefaultEntriesTable is the default entry table name used by SQLStore.
+	DefaultEntriesTable = "x402_credit_entries"
+
+	// scale is the number of decimals amounts are stored with, as integers.
+	scale = 9
+)
+
+// SQLStore is a BalanceStore backed by a SQL database (SQLite or PostgreSQL),
+// shared by all replicas using the same tables. Every credit and debit is an
+// entry keyed by its reference, written in the same transaction as the
+// balance, so a reference is applied at most once. Debits use a conditional
+// UPDATE, so a balance never goes negative.
+//
+// Amounts are stored as integers in billionths of a token; amounts with more
+// than 9 decimals are rejected.
+//
+// Schema (see CreateTable):
+//
+//	CREATE TABLE x402_credit_balances (
+//	    account VARCHAR(128) PRIMARY KEY,
+//	    balance BIGINT       NOT NULL
+//	);
+//	CREATE TABLE x402_credit_entries (
+//	    reference  VARCHAR(255) PRIMARY KEY,
+//	    account    VARCHAR(128) NOT NULL,
+//	    amount     BIGINT       NOT NULL,
+//	    created_at BIGINT       NOT NULL
+//	);
+type SQLStore struct {
+	db          *sql.DB
+	table       string
+	entries     string
+	placeholder func(n int) string
+	now         func() time.Time
+}
+
+// SQLOption configures a SQLStore.
+type SQLOption func(*SQLStore)
+
+// WithTables sets the balance and entry table names.
+func WithTables(balances, entries string) SQLOption {
+	return func(s *SQLStore) {
+		s.table = balances
+		s.entries = entries
+	}
+}
+
+// WithDollarPlaceholders uses $1, $2, ... placeholders (PostgreSQL) instead of ?.
+func WithDollarPlaceholders() SQLOption {
+	return func(s *SQLStore) {
+		s.placeholder = func(n int) string {
+			return "$" + strconv.Itoa(n)
+		}
+	}
+}
+
+// NewSQLStore creates a store using db. The tables must exist; see CreateTable.
+func NewSQLStore(db *sql.DB, opts ...SQLOption) *SQLStore {
+	s := &SQLStore{
+		db:          db,
+		table:       DefaultTable,
+		entries:     DefaultEntriesTable,
+		placeholder: func(int) string { return "?" },
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateTable creates the balance and entry tables if they do not exist.
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+s.table+` (
+		account VARCHAR(128) PRIMARY KEY,
+		balance BIGINT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("create credit balance table: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+s.entries+` (
+		reference VARCHAR(255) PRIMARY KEY,
+		account VARCHAR(128) NOT NULL,
+		amount BIGINT NOT NULL,
+		created_at BIGINT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("create credit entry table: %w", err)
+	}
+	return nil
+}
+
+var _ x402.BalanceStore = (*SQLStore)(nil)
+
+// Balance implements x402.BalanceStore.
+func (s *SQLStore) Balance(ctx context.Context, account string) (decimal.Decimal, error) {
+	balance, err := s.balance(ctx, s.db, account)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("read credit balance: %w", err)
+	}
+	return balance, nil
+}
+
+// Credit implements x402.BalanceStore.
+func (s *SQLStore) Credit(ctx context.Context, account string, amount decimal.Decimal, reference string) (decimal.Decimal, error) {
+	units, err := toUnits(amount)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("credit: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	added, err := s.addEntry(ctx, tx, account, units, reference)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("credit: %w", err)
+	}
+	if added {
+		if _, err := tx.ExecContext(ctx,
+			s.query("INSERT INTO %s (account, balance) VALUES (%s, %s) "+
+				"ON CONFLICT (account) DO UPDATE SET balance = "+s.table+".balance + excluded.balance", s.table),
+			account, units,
+		); err != nil {
+			return decimal.Zero, fmt.Errorf("credit: %w", err)
+		}
+	}
+
+	balance, err := s.balance(ctx, tx, account)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("credit: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return decimal.Zero, fmt.Errorf("credit: %w", err)
+	}
+	return balance, nil
+}
+
+// Debit implements x402.BalanceStore.
+func (s *SQLStore) Debit(ctx context.Context, account string, amount decimal.Decimal, reference string) (decimal.Decimal, error) {
+	units, err := toUnits(amount)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("debit: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	added, err := s.addEntry(ctx, tx, account, -units, reference)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("debit: %w", err)
+	}
+	if !added {
+		return decimal.Zero, x402.ErrDuplicateReference
+	}
+
+	result, err := tx.ExecContext(ctx,
+		s.query("UPDATE %s SET balance = balance - %s WHERE account = %s AND balance >= %s", s.table),
+		units, account, units,
+	)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("debit: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err != nil {
+		return decimal.Zero, fmt.Errorf("debit: %w", err)
+	} else if updated == 0 {
+		// Rolling back also releases the reference for a later attempt
+		return decimal.Zero, x402.ErrInsufficientBalance
+	}
+
+	balance, err := s.balance(ctx, tx, account)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("debit: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return decimal.Zero, fmt.Errorf("debit: %w", err)
+	}
+	return balance, nil
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// balance reads the balance of an account, zero if it has none.
+func (s *SQLStore) balance(ctx context.Context, q queryer, account string) (decimal.Decimal, error) {
+	var units int64
+	err := q.QueryRowContext(ctx, s.query("SELECT balance FROM %s WHERE account = %s", s.table), account).Scan(&units)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return decimal.Zero, err
+	}
+	return decimal.New(units, -scale), nil
+}
+
+// addEntry records an entry and reports whether its reference was new.
+func (s *SQLStore) addEntry(ctx context.Context, tx *sql.Tx, account string, units int64, reference string) (bool, error) {
+	result, err := tx.ExecContext(ctx,
+		s.query("INSERT INTO %s (reference, account, amount, created_at) VALUES (%s, %s, %s, %s) "+
+			"ON CONFLICT (reference) DO NOTHING", s.entries),
+		reference, account, units, s.now().UnixMilli(),
+	)
+	if err != nil {
+		return false, err
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return inserted > 0, nil
+}
+
+// toUnits converts a non-negative amount to stored integer units.
+func toUnits(amount decimal.Decimal) (int64, error) {
+	if amount.IsNegative() {
+		return 0, errNegativeAmount
+	}
+	units := amount.Shift(scale)
+	if !units.IsInteger() {
+		return 0, fmt.Errorf("credits: amount %s has more than %d decimals", amount, scale)
+	}
+	return units.IntPart(), nil
+}
+
+// query formats a statement with a table name and numbered placeholders.
+func (s *SQLStore) query(format, table string) string {
+	args := []any{table}
+	for i := 1; i < strings.Count(format, "%s"); i++ {
+		args = append(args, s.placeholder(i))
+	}
+	return fmt.Sprintf(format, args...)
+}
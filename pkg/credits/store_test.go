@@ -0,0 +1,153 @@
+package credits
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/dexfra-fun/x402-go/internal/sqltest"
+	"github.com/dexfra-fun/x402-go/pkg/x402"
+	"github.com/shopspring/decimal"
+)
+
+// newStore returns an empty store of a kind ("memory" or "sql").
+func newStore(t *testing.T, kind string) x402.BalanceStore {
+	t.Helper()
+
+	if kind == "memory" {
+		return NewMemoryStore()
+	}
+	store := NewSQLStore(sqltest.Open(t), WithTables("balances", "entries"))
+	if err := store.CreateTable(context.Background()); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	return store
+}
+
+var storeKinds = []string{"memory", "sql"}
+
+func TestStore_Credit(t *testing.T) {
+	tests := []struct {
+		name      string
+		reference string
+		amount    string
+		want      string
+		wantErr   bool
+	}{
+		{"top up", "topup:0x2", "0.25", "1.75", false},
+		{"retried top up", "topup:0x1", "1.5", "1.5", false},
+		{"smallest unit", "topup:0x2", "0.000000001", "1.500000001", false},
+		{"negative amount", "topup:0x2", "-1", "", true},
+	}
+
+	for _, tt := range tests {
+		for _, kind := range storeKinds {
+			t.Run(tt.name+"/"+kind, func(t *testing.T) {
+				ctx := context.Background()
+				store := newStore(t, kind)
+				if _, err := store.Credit(ctx, "alice", decimal.RequireFromString("1.5"), "topup:0x1"); err != nil {
+					t.Fatalf("Credit() error = %v", err)
+				}
+
+				balance, err := store.Credit(ctx, "alice", decimal.RequireFromString(tt.amount), tt.reference)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("Credit() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err == nil && !balance.Equal(decimal.RequireFromString(tt.want)) {
+					t.Errorf("Credit() = %s, want %s", balance, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestStore_Debit(t *testing.T) {
+	tests := []struct {
+		name        string
+		account     string
+		amount      string
+		reference   string
+		wantErr     error
+		wantBalance string
+	}{
+		{"debit", "alice", "0.000001", "debit:2", nil, "1.499999"},
+		{"whole balance", "alice", "1.5", "debit:2", nil, "0"},
+		{"replayed reference", "alice", "0.01", "debit:1", x402.ErrDuplicateReference, "1.5"},
+		{"overdraft", "alice", "2", "debit:2", x402.ErrInsufficientBalance, "1.5"},
+		{"no account", "bob", "0.01", "debit:2", x402.ErrInsufficientBalance, "0"},
+	}
+
+	for _, tt := range tests {
+		for _, kind := range storeKinds {
+			t.Run(tt.name+"/"+kind, func(t *testing.T) {
+				ctx := context.Background()
+				store := newStore(t, kind)
+				if _, err := store.Credit(ctx, "alice", decimal.RequireFromString("1.51"), "topup:0x1"); err != nil {
+					t.Fatalf("Credit() error = %v", err)
+				}
+				if _, err := store.Debit(ctx, "alice", decimal.RequireFromString("0.01"), "debit:1"); err != nil {
+					t.Fatalf("Debit() error = %v", err)
+				}
+
+				balance, err := store.Debit(ctx, tt.account, decimal.RequireFromString(tt.amount), tt.reference)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Debit() error = %v, want %v", err, tt.wantErr)
+				}
+				if err == nil && !balance.Equal(decimal.RequireFromString(tt.wantBalance)) {
+					t.Errorf("Debit() = %s, want %s", balance, tt.wantBalance)
+				}
+				if balance, _ := store.Balance(ctx, tt.account); !balance.Equal(decimal.RequireFromString(tt.wantBalance)) {
+					t.Errorf("Balance() = %s, want %s", balance, tt.wantBalance)
+				}
+			})
+		}
+	}
+}
+
+func TestStore_ConcurrentDebits(t *testing.T) {
+	for _, kind := range storeKinds {
+		t.Run(kind, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore(t, kind)
+			if _, err := store.Credit(ctx, "alice", decimal.NewFromInt(5), "topup"); err != nil {
+				t.Fatalf("Credit() error = %v", err)
+			}
+
+			var (
+				wg       sync.WaitGroup
+				mu       sync.Mutex
+				debited  int
+				rejected int
+			)
+			for i := range 20 {
+				wg.Go(func() {
+					_, err := store.Debit(ctx, "alice", decimal.NewFromInt(1), "debit:"+strconv.Itoa(i))
+					mu.Lock()
+					defer mu.Unlock()
+					switch {
+					case err == nil:
+						debited++
+					case errors.Is(err, x402.ErrInsufficientBalance):
+						rejected++
+					default:
+						t.Errorf("Debit() error = %v", err)
+					}
+				})
+			}
+			wg.Wait()
+
+			if debited != 5 || rejected != 15 {
+				t.Errorf("debited %d, rejected %d, want 5 and 15", debited, rejected)
+			}
+		})
+	}
+}
+
+func TestSQLStore_RejectsExcessPrecision(t *testing.T) {
+	store := newStore(t, "sql")
+	if _, err := store.Credit(context.Background(), "alice", decimal.New(1, -10), "topup"); err == nil {
+		t.Error("Credit() with 10 decimals: error = nil, want an error")
+	}
+}
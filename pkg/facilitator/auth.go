@@ -0,0 +1,26 @@
+package facilitator
+
+import "net/http"
+
+// RequestAuthorizer adds credentials to requests sent to a facilitator.
+// Authorize is called for every attempt, after the body and headers are set,
+// so per-request signatures stay fresh across retries.
+type RequestAuthorizer interface {
+	Authorize(req *http.Request) error
+}
+
+// AuthorizerFunc adapts a function to the RequestAuthorizer interface.
+type AuthorizerFunc func(req *http.Request) error
+
+// Authorize calls f(req).
+func (f AuthorizerFunc) Authorize(req *http.Request) error {
+	return f(req)
+}
+
+// BearerToken returns an authorizer that sends a static bearer token.
+func BearerToken(token string) RequestAuthorizer {
+	return AuthorizerFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
@@ -0,0 +1,177 @@
+// Package cdp authenticates requests to the Coinbase Developer Platform (CDP)
+// x402 facilitator. Every request carries a short-lived JWT signed with a CDP
+// API key and bound to the request's method, host and path.
+package cdp
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dexfra-fun/x402-go/pkg/facilitator"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// FacilitatorURL is the base URL of the CDP x402 facilitator.
+	FacilitatorURL = "https://api.cdp.coinbase.com/platform/v2/x402"
+
+	// DefaultLifetime is how long a token is valid.
+	DefaultLifetime = 2 * time.Minute
+
+	// issuer is the iss claim expected by CDP.
+	issuer = "cdp"
+
+	// nonceSize is the number of random bytes in the token nonce.
+	nonceSize = 16
+)
+
+// ErrInvalidKey indicates that the API key secret is not a P-256 or Ed25519 private key.
+var ErrInvalidKey = errors.New("cdp: invalid API key secret")
+
+// Authorizer signs facilitator requests with a CDP API key.
+type Authorizer struct {
+	keyID    string
+	key      crypto.Signer
+	method   jwt.SigningMethod
+	lifetime time.Duration
+}
+
+// Option configures an Authorizer.
+type Option func(*Authorizer)
+
+// WithLifetime sets how long each token is valid.
+func WithLifetime(lifetime time.Duration) Option {
+	return func(a *Authorizer) {
+		a.lifetime = lifetime
+	}
+}
+
+// NewAuthorizer creates an authorizer for a CDP API key. keyID is the key name
+// and secret the private key: a PEM-encoded EC key for ES256 keys, or a
+// base64-encoded Ed25519 key as exported by the CDP portal.
+func NewAuthorizer(keyID, secret string, opts ...Option) (*Authorizer, error) {
+	key, method, err := parseKey(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Authorizer{
+		keyID:    keyID,
+		key:      key,
+		method:   method,
+		lifetime: DefaultLifetime,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a, nil
+}
+
+var _ facilitator.RequestAuthorizer = (*Authorizer)(nil)
+
+// Authorize implements facilitator.RequestAuthorizer by setting a bearer token
+// for the request's method, host and path.
+func (a *Authorizer) Authorize(req *http.Request) error {
+	token, err := a.Token(req.Method, req.URL.Host, req.URL.Path)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// claims are the claims of a CDP token.
+type claims struct {
+	jwt.RegisteredClaims
+
+	URIs []string `json:"uris"`
+}
+
+// Token returns a signed JWT for a request, e.g.
+// Token("POST", "api.cdp.coinbase.com", "/platform/v2/x402/verify").
+func (a *Authorizer) Token(method, host, path string) (string, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("cdp: generate nonce: %w", err)
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(a.method, claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   a.keyID,
+			Issuer:    issuer,
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(a.lifetime)),
+		},
+		URIs: []string{method + " " + host + path},
+	})
+	token.Header["kid"] = a.keyID
+	token.Header["nonce"] = hex.EncodeToString(nonce)
+
+	signed, err := token.SignedString(a.key)
+	if err != nil {
+		return "", fmt.Errorf("cdp: sign token: %w", err)
+	}
+	return signed, nil
+}
+
+// parseKey decodes a CDP API key secret and returns the key and its JWT signing method.
+func parseKey(secret string) (crypto.Signer, jwt.SigningMethod, error) {
+	// Secrets copied from JSON key files often contain escaped newlines
+	secret = strings.TrimSpace(strings.ReplaceAll(secret, `\n`, "\n"))
+
+	if block, _ := pem.Decode([]byte(secret)); block != nil {
+		return parsePEMKey(block)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+	switch len(raw) {
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), jwt.SigningMethodEdDSA, nil
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: unexpected Ed25519 key length %d", ErrInvalidKey, len(raw))
+	}
+}
+
+// parsePEMKey decodes a SEC 1 or PKCS #8 private key.
+func parsePEMKey(block *pem.Block) (crypto.Signer, jwt.SigningMethod, error) {
+	var key any
+	var err error
+	if block.Type == "EC PRIVATE KEY" {
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	} else {
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return nil, nil, fmt.Errorf("%w: ES256 requires a P-256 key", ErrInvalidKey)
+		}
+		return key, jwt.SigningMethodES256, nil
+	case ed25519.PrivateKey:
+		return key, jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: unsupported key type %T", ErrInvalidKey, key)
+	}
+}
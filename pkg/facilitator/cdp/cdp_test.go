@@ -0,0 +1,138 @@
+package cdp_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/dexfra-fun/x402-go/pkg/facilitator/cdp"
+)
+
+const keyID = "organizations/org/apiKeys/key"
+
+// decodeToken splits a JWT and decodes its header and claims.
+func decodeToken(t *testing.T, token string) (map[string]any, map[string]any, []byte, []byte) {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token has %d parts, want 3", len(parts))
+	}
+	decode := func(part string) []byte {
+		raw, err := base64.RawURLEncoding.DecodeString(part)
+		if err != nil {
+			t.Fatalf("decode token part: %v", err)
+		}
+		return raw
+	}
+
+	var header, claims map[string]any
+	if err := sonic.Unmarshal(decode(parts[0]), &header); err != nil {
+		t.Fatalf("unmarshal header: %v", err)
+	}
+	if err := sonic.Unmarshal(decode(parts[1]), &claims); err != nil {
+		t.Fatalf("unmarshal claims: %v", err)
+	}
+	return header, claims, []byte(parts[0] + "." + parts[1]), decode(parts[2])
+}
+
+func TestAuthorizer(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecSecret := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edSecret := base64.StdEncoding.EncodeToString(edKey)
+
+	tests := []struct {
+		name   string
+		secret string
+		alg    string
+		verify func(input, signature []byte) bool
+	}{
+		{"ES256", ecSecret, "ES256", func(input, signature []byte) bool {
+			digest := sha256.Sum256(input)
+			r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+			return len(signature) == 64 && ecdsa.Verify(&ecKey.PublicKey, digest[:], r, s)
+		}},
+		{"ES256 escaped newlines", strings.ReplaceAll(ecSecret, "\n", `\n`), "ES256", nil},
+		{"EdDSA", edSecret, "EdDSA", func(input, signature []byte) bool {
+			return ed25519.Verify(edKey.Public().(ed25519.PublicKey), input, signature)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorizer, err := cdp.NewAuthorizer(keyID, tt.secret)
+			if err != nil {
+				t.Fatalf("NewAuthorizer() error = %v", err)
+			}
+			req, _ := http.NewRequest(http.MethodPost, cdp.FacilitatorURL+"/verify", nil)
+			if err := authorizer.Authorize(req); err != nil {
+				t.Fatalf("Authorize() error = %v", err)
+			}
+
+			token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				t.Fatalf("Authorization = %q, want bearer token", req.Header.Get("Authorization"))
+			}
+			header, claims, input, signature := decodeToken(t, token)
+			if header["alg"] != tt.alg || header["kid"] != keyID || header["nonce"] == "" {
+				t.Errorf("header = %v", header)
+			}
+			wantURI := "POST api.cdp.coinbase.com/platform/v2/x402/verify"
+			if uris, _ := claims["uris"].([]any); len(uris) != 1 || uris[0] != wantURI {
+				t.Errorf("uris = %v, want [%s]", claims["uris"], wantURI)
+			}
+			if claims["sub"] != keyID || claims["iss"] != "cdp" {
+				t.Errorf("claims = %v", claims)
+			}
+			if nbf, exp := claims["nbf"].(float64), claims["exp"].(float64); exp-nbf != cdp.DefaultLifetime.Seconds() {
+				t.Errorf("lifetime = %vs, want %v", exp-nbf, cdp.DefaultLifetime)
+			}
+			if tt.verify != nil && !tt.verify(input, signature) {
+				t.Error("signature does not verify")
+			}
+		})
+	}
+}
+
+func TestNewAuthorizer_InvalidKey(t *testing.T) {
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(p384)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, secret := range map[string]string{
+		"not base64":  "not a key!",
+		"short seed":  base64.StdEncoding.EncodeToString([]byte("short")),
+		"P-384 curve": string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})),
+	} {
+		if _, err := cdp.NewAuthorizer(keyID, secret); !errors.Is(err, cdp.ErrInvalidKey) {
+			t.Errorf("%s: error = %v, want ErrInvalidKey", name, err)
+		}
+	}
+}
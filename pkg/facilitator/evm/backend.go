@@ -0,0 +1,160 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/internal/evm"
+)
+
+const (
+	// defaultReceiptTimeout is how long SendTransaction waits for a receipt.
+	defaultReceiptTimeout = 60 * time.Second
+
+	// defaultReceiptInterval is how often SendTransaction polls for a receipt.
+	defaultReceiptInterval = time.Second
+
+	// gasBufferPercent is added to estimated gas.
+	gasBufferPercent = 20
+)
+
+// ErrReceiptTimeout indicates a submitted transaction was not mined in time.
+// Its outcome is unknown and the payment must not be resubmitted elsewhere.
+var ErrReceiptTimeout = errors.New("evm facilitator: transaction not mined in time")
+
+// RPCBackend sends EIP-1559 transactions from a local key through a JSON-RPC
+// endpoint. It implements both Sender and Reader. Transactions are sent one at
+// a time so nonces are assigned in order.
+type RPCBackend struct {
+	client          *evm.RPCClient
+	chainID         uint64
+	key             *evm.PrivateKey
+	address         [evm.AddressLength]byte
+	receiptTimeout  time.Duration
+	receiptInterval time.Duration
+
+	mu sync.Mutex
+}
+
+// RPCOption configures an RPCBackend.
+type RPCOption func(*RPCBackend)
+
+// WithReceiptPolling sets how long and how often SendTransaction waits for a receipt.
+func WithReceiptPolling(timeout, interval time.Duration) RPCOption {
+	return func(b *RPCBackend) {
+		b.receiptTimeout = timeout
+		b.receiptInterval = interval
+	}
+}
+
+// NewRPCBackend creates a backend for network that pays gas with the 32-byte privateKey.
+func NewRPCBackend(network, rpcURL string, privateKey []byte, opts ...RPCOption) (*RPCBackend, error) {
+	chain, ok := x402.GetChainConfig(network)
+	if !ok || !chain.IsEVM() {
+		return nil, fmt.Errorf("%w: %s", x402.ErrInvalidNetwork, network)
+	}
+	key, err := evm.ParsePrivateKey(privateKey)
+	if err != nil {
+		return nil, x402.ErrInvalidKey
+	}
+
+	b := &RPCBackend{
+		client:          evm.NewRPCClient(rpcURL, nil),
+		chainID:         chain.ChainID,
+		key:             key,
+		address:         key.Address(),
+		receiptTimeout:  defaultReceiptTimeout,
+		receiptInterval: defaultReceiptInterval,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b, nil
+}
+
+// Address returns the checksummed address that pays gas.
+func (b *RPCBackend) Address() string {
+	return evm.ChecksumAddress(b.address)
+}
+
+// CallContract implements Reader.
+func (b *RPCBackend) CallContract(ctx context.Context, to string, data []byte) ([]byte, error) {
+	contract, err := evm.ParseAddress(to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAddress, err)
+	}
+	return b.client.CallContract(ctx, contract, data)
+}
+
+// SendTransaction implements Sender.
+func (b *RPCBackend) SendTransaction(ctx context.Context, to string, data []byte) (Receipt, error) {
+	contract, err := evm.ParseAddress(to)
+	if err != nil {
+		return Receipt{}, fmt.Errorf("%w: %w", ErrInvalidAddress, err)
+	}
+
+	hash, err := b.submit(ctx, contract, data)
+	if err != nil {
+		return Receipt{}, err
+	}
+	return b.waitForReceipt(ctx, hash)
+}
+
+// submit signs and sends a transaction, holding the lock so nonces do not collide.
+func (b *RPCBackend) submit(ctx context.Context, to [evm.AddressLength]byte, data []byte) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	gas, err := b.client.EstimateGas(ctx, b.address, to, data)
+	if err != nil {
+		return "", fmt.Errorf("estimate gas: %w", err)
+	}
+	tip, feeCap, err := b.client.SuggestFees(ctx)
+	if err != nil {
+		return "", fmt.Errorf("suggest fees: %w", err)
+	}
+	nonce, err := b.client.PendingNonce(ctx, b.address)
+	if err != nil {
+		return "", fmt.Errorf("get nonce: %w", err)
+	}
+
+	tx := &evm.DynamicFeeTx{
+		ChainID:   b.chainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		Gas:       gas + gas*gasBufferPercent/100, //nolint:mnd // percentage
+		To:        to,
+		Value:     new(big.Int),
+		Data:      data,
+	}
+	raw, _ := tx.Sign(b.key)
+	return b.client.SendRawTransaction(ctx, raw)
+}
+
+// waitForReceipt polls until the transaction is mined.
+func (b *RPCBackend) waitForReceipt(ctx context.Context, hash string) (Receipt, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.receiptTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(b.receiptInterval)
+	defer ticker.Stop()
+
+	for {
+		mined, success, err := b.client.TransactionStatus(ctx, hash)
+		if err == nil && mined {
+			return Receipt{TxHash: hash, Success: success}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return Receipt{}, fmt.Errorf("%w: %s", ErrReceiptTimeout, hash)
+		case <-ticker.C:
+		}
+	}
+}
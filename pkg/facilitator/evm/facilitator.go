@@ -0,0 +1,287 @@
+// Package evm is a self-hosted x402 facilitator for EVM networks. It verifies
+// EIP-3009 transferWithAuthorization payments locally and settles them by
+// submitting the authorization to the token contract through a Sender.
+//
+// The facilitator implements facilitator.Interface and can be used directly as
+// Config.Facilitator, or exposed to other services with facilitator.NewHandler.
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/internal/evm"
+	"github.com/dexfra-fun/x402-go/pkg/facilitator"
+	evmsigner "github.com/dexfra-fun/x402-go/pkg/signers/evm"
+)
+
+// validBeforeMargin is the minimum remaining validity of an authorization,
+// leaving time for the settlement transaction to be mined.
+const validBeforeMargin = 6 * time.Second
+
+// Reasons reported in VerifyResponse.InvalidReason and SettlementResponse.ErrorReason.
+const (
+	ReasonUnsupportedScheme = "unsupported_scheme"
+	ReasonInvalidNetwork    = "invalid_network"
+	ReasonInvalidAsset      = "invalid_exact_evm_payload_asset"
+	ReasonInvalidPayload    = "invalid_payload"
+	ReasonInvalidSignature  = "invalid_exact_evm_payload_signature"
+	ReasonRecipientMismatch = "invalid_exact_evm_payload_recipient_mismatch"
+	ReasonInvalidValue      = "invalid_exact_evm_payload_authorization_value"
+	ReasonNotYetValid       = "invalid_exact_evm_payload_authorization_valid_after"
+	ReasonExpired           = "invalid_exact_evm_payload_authorization_valid_before"
+	ReasonNonceUsed         = "invalid_exact_evm_payload_authorization_nonce_used"
+	ReasonInsufficientFunds = "insufficient_funds"
+	ReasonTransactionFailed = "transaction_failed"
+)
+
+// ErrInvalidAddress indicates a malformed contract or account address.
+var ErrInvalidAddress = errors.New("evm facilitator: invalid address")
+
+// Receipt is the outcome of a mined transaction.
+type Receipt struct {
+	TxHash  string
+	Success bool
+}
+
+// Sender submits transactions from an account that pays gas.
+type Sender interface {
+	// SendTransaction calls contract to with data, waits until the
+	// transaction is mined and returns its receipt.
+	SendTransaction(ctx context.Context, to string, data []byte) (Receipt, error)
+}
+
+// Reader performs read-only contract calls.
+type Reader interface {
+	// CallContract executes a call against the latest block and returns its result.
+	CallContract(ctx context.Context, to string, data []byte) ([]byte, error)
+}
+
+// Facilitator verifies and settles EIP-3009 payments for one network. It only
+// accepts payments in the chain's USDC and the tokens added with WithAssets,
+// so callers cannot make it pay gas for calls to arbitrary contracts.
+type Facilitator struct {
+	chain       x402.ChainConfig
+	sender      Sender
+	reader      Reader
+	now         func() time.Time
+	extraAssets []string
+	assets      map[[evm.AddressLength]byte]bool
+}
+
+// Option configures a Facilitator.
+type Option func(*Facilitator)
+
+// WithReader enables on-chain checks of the payer's balance and nonce during
+// verification. RPCBackend implements Reader.
+func WithReader(reader Reader) Option {
+	return func(f *Facilitator) {
+		f.reader = reader
+	}
+}
+
+// WithAssets accepts payments in additional EIP-3009 token contracts besides
+// the chain's USDC.
+func WithAssets(addresses ...string) Option {
+	return func(f *Facilitator) {
+		f.extraAssets = append(f.extraAssets, addresses...)
+	}
+}
+
+// WithClock sets the time source used to check authorization validity.
+func WithClock(now func() time.Time) Option {
+	return func(f *Facilitator) {
+		f.now = now
+	}
+}
+
+// NewFacilitator creates a facilitator for an EVM network that settles
+// payments through sender.
+func NewFacilitator(network string, sender Sender, opts ...Option) (*Facilitator, error) {
+	chain, ok := x402.GetChainConfig(network)
+	if !ok || !chain.IsEVM() {
+		return nil, fmt.Errorf("%w: %s", x402.ErrInvalidNetwork, network)
+	}
+
+	f := &Facilitator{chain: chain, sender: sender, now: time.Now}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	f.assets = make(map[[evm.AddressLength]byte]bool)
+	for _, asset := range append([]string{chain.USDCAddress}, f.extraAssets...) {
+		address, err := evm.ParseAddress(asset)
+		if err != nil {
+			return nil, fmt.Errorf("%w: asset %q: %w", ErrInvalidAddress, asset, err)
+		}
+		f.assets[address] = true
+	}
+	return f, nil
+}
+
+var _ facilitator.Interface = (*Facilitator)(nil)
+
+// Supported implements facilitator.Interface.
+func (f *Facilitator) Supported(context.Context) (*facilitator.SupportedResponse, error) {
+	return &facilitator.SupportedResponse{Kinds: []facilitator.SupportedKind{{
+		X402Version: 1,
+		Scheme:      x402.DefaultScheme,
+		Network:     f.chain.NetworkID,
+	}}}, nil
+}
+
+// Verify implements facilitator.Interface.
+func (f *Facilitator) Verify(
+	ctx context.Context,
+	payment x402.PaymentPayload,
+	requirement x402.PaymentRequirement,
+) (*facilitator.VerifyResponse, error) {
+	check, err := f.check(ctx, payment, requirement)
+	if err != nil {
+		return nil, err
+	}
+	if check.reason != "" {
+		return &facilitator.VerifyResponse{InvalidReason: check.reason, Payer: check.payer}, nil
+	}
+	return &facilitator.VerifyResponse{IsValid: true, Payer: check.payer}, nil
+}
+
+// Settle implements facilitator.Interface. The payment is verified again and
+// submitted as a transferWithAuthorization call to the token contract.
+func (f *Facilitator) Settle(
+	ctx context.Context,
+	payment x402.PaymentPayload,
+	requirement x402.PaymentRequirement,
+) (*x402.SettlementResponse, error) {
+	check, err := f.check(ctx, payment, requirement)
+	if err != nil {
+		return nil, err
+	}
+	response := &x402.SettlementResponse{Network: f.chain.NetworkID, Payer: check.payer}
+	if check.reason != "" {
+		response.ErrorReason = check.reason
+		return response, nil
+	}
+
+	data, err := evm.TransferWithAuthorizationCall(check.auth, check.signature)
+	if err != nil {
+		return nil, fmt.Errorf("encode call: %w", err)
+	}
+	// Only allowed token contracts get here; check rejects any other asset
+	receipt, err := f.sender.SendTransaction(ctx, evm.ChecksumAddress(check.token), data)
+	if err != nil {
+		return nil, fmt.Errorf("send transaction: %w", err)
+	}
+
+	response.Transaction = receipt.TxHash
+	if !receipt.Success {
+		response.ErrorReason = ReasonTransactionFailed
+		return response, nil
+	}
+	response.Success = true
+	return response, nil
+}
+
+// checked is the result of validating a payment.
+type checked struct {
+	token     [evm.AddressLength]byte
+	auth      evm.TransferAuthorization
+	signature []byte
+	payer     string
+	reason    string
+}
+
+// check validates a payment against a requirement. Invalid payments are
+// reported in the result's reason; errors are returned only when on-chain
+// state cannot be read.
+func (f *Facilitator) check(
+	ctx context.Context,
+	payment x402.PaymentPayload,
+	requirement x402.PaymentRequirement,
+) (checked, error) {
+	if payment.Scheme != x402.DefaultScheme || requirement.Scheme != x402.DefaultScheme {
+		return checked{reason: ReasonUnsupportedScheme}, nil
+	}
+	if payment.Network != f.chain.NetworkID || requirement.Network != f.chain.NetworkID {
+		return checked{reason: ReasonInvalidNetwork}, nil
+	}
+	// The asset is the EIP-712 verifying contract and the settlement target
+	token, err := evm.ParseAddress(requirement.Asset)
+	if err != nil || !f.assets[token] {
+		return checked{reason: ReasonInvalidAsset}, nil
+	}
+
+	payload, err := payment.DecodeEVMPayload()
+	if err != nil {
+		return checked{reason: ReasonInvalidPayload}, nil
+	}
+	auth, err := evmsigner.ParseAuthorization(payload.Authorization)
+	if err != nil {
+		return checked{reason: ReasonInvalidPayload}, nil
+	}
+	signature, err := evm.DecodeHex(payload.Signature)
+	if err != nil {
+		return checked{reason: ReasonInvalidPayload}, nil
+	}
+
+	result := checked{token: token, auth: auth, signature: signature, payer: evm.ChecksumAddress(auth.From)}
+	result.reason = f.checkAuthorization(payment, requirement, auth)
+	if result.reason == "" && f.reader != nil {
+		result.reason, err = f.checkOnChain(ctx, evm.ChecksumAddress(token), auth)
+	}
+	return result, err
+}
+
+// checkAuthorization checks the signature, recipient, value and validity window.
+func (f *Facilitator) checkAuthorization(
+	payment x402.PaymentPayload,
+	requirement x402.PaymentRequirement,
+	auth evm.TransferAuthorization,
+) string {
+	if _, err := evmsigner.VerifyPayment(payment, requirement); err != nil {
+		return ReasonInvalidSignature
+	}
+
+	payTo, err := evm.ParseAddress(requirement.PayTo)
+	if err != nil || payTo != auth.To {
+		return ReasonRecipientMismatch
+	}
+
+	required, ok := new(big.Int).SetString(requirement.MaxAmountRequired, x402.DecimalBase)
+	if !ok || auth.Value.Cmp(required) < 0 {
+		return ReasonInvalidValue
+	}
+
+	now := f.now()
+	if auth.ValidAfter.Cmp(big.NewInt(now.Unix())) > 0 {
+		return ReasonNotYetValid
+	}
+	if auth.ValidBefore.Cmp(big.NewInt(now.Add(validBeforeMargin).Unix())) < 0 {
+		return ReasonExpired
+	}
+	return ""
+}
+
+// checkOnChain checks that the nonce is unused and the payer can cover the value.
+func (f *Facilitator) checkOnChain(ctx context.Context, token string, auth evm.TransferAuthorization) (string, error) {
+	state, err := f.reader.CallContract(ctx, token, evm.AuthorizationStateCall(auth.From, auth.Nonce))
+	if err != nil {
+		return "", fmt.Errorf("read authorization state: %w", err)
+	}
+	if used, ok := evm.DecodeUint256(state); !ok || used.Sign() != 0 {
+		return ReasonNonceUsed, nil
+	}
+
+	result, err := f.reader.CallContract(ctx, token, evm.BalanceOfCall(auth.From))
+	if err != nil {
+		return "", fmt.Errorf("read balance: %w", err)
+	}
+	if balance, ok := evm.DecodeUint256(result); !ok || balance.Cmp(auth.Value) < 0 {
+		return ReasonInsufficientFunds, nil
+	}
+	return "", nil
+}
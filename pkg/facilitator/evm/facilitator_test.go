@@ -0,0 +1,292 @@
+package evm_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/pkg/facilitator"
+	evmfacilitator "github.com/dexfra-fun/x402-go/pkg/facilitator/evm"
+	"github.com/dexfra-fun/x402-go/pkg/x402test"
+)
+
+const recipient = "0x209693Bc6afc0C5328bA36FaF03C514EF312287C"
+
+var requirement = x402.PaymentRequirement{
+	Scheme:            x402.DefaultScheme,
+	Network:           "base-sepolia",
+	MaxAmountRequired: "10000",
+	Asset:             x402.BaseSepolia.USDCAddress,
+	PayTo:             recipient,
+	MaxTimeoutSeconds: 60,
+	Extra:             map[string]any{"name": "USDC", "version": "2"},
+}
+
+// fakeChain answers contract reads with fixed values and records transactions.
+type fakeChain struct {
+	nonceUsed bool
+	balance   int64
+	success   bool
+	sent      []string
+}
+
+func (c *fakeChain) CallContract(_ context.Context, _ string, data []byte) ([]byte, error) {
+	word := make([]byte, 32)
+	switch hex.EncodeToString(data[:4]) {
+	case "e94a0102": // authorizationState
+		if c.nonceUsed {
+			word[31] = 1
+		}
+	case "70a08231": // balanceOf
+		big.NewInt(c.balance).FillBytes(word)
+	}
+	return word, nil
+}
+
+func (c *fakeChain) SendTransaction(_ context.Context, to string, data []byte) (evmfacilitator.Receipt, error) {
+	c.sent = append(c.sent, to+":"+hex.EncodeToString(data[:4]))
+	return evmfacilitator.Receipt{TxHash: "0xhash", Success: c.success}, nil
+}
+
+func newFacilitator(t *testing.T, chain *fakeChain, opts ...evmfacilitator.Option) *evmfacilitator.Facilitator {
+	t.Helper()
+	f, err := evmfacilitator.NewFacilitator("base-sepolia", chain, append(opts, evmfacilitator.WithReader(chain))...)
+	if err != nil {
+		t.Fatalf("NewFacilitator() error = %v", err)
+	}
+	return f
+}
+
+func TestFacilitator_Verify(t *testing.T) {
+	payment := x402test.Payment(t, requirement)
+
+	tests := []struct {
+		name       string
+		chain      fakeChain
+		clock      time.Time
+		modify     func(*x402.PaymentRequirement)
+		wantReason string
+	}{
+		{name: "valid", chain: fakeChain{balance: 10000}},
+		{
+			name:       "other recipient",
+			chain:      fakeChain{balance: 10000},
+			modify:     func(r *x402.PaymentRequirement) { r.PayTo = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23" },
+			wantReason: evmfacilitator.ReasonRecipientMismatch,
+		},
+		{
+			name:       "higher price",
+			chain:      fakeChain{balance: 10000},
+			modify:     func(r *x402.PaymentRequirement) { r.MaxAmountRequired = "20000" },
+			wantReason: evmfacilitator.ReasonInvalidValue,
+		},
+		{
+			name:       "other domain",
+			chain:      fakeChain{balance: 10000},
+			modify:     func(r *x402.PaymentRequirement) { r.Extra = map[string]any{"name": "USD Coin", "version": "2"} },
+			wantReason: evmfacilitator.ReasonInvalidSignature,
+		},
+		{name: "expired", chain: fakeChain{balance: 10000}, clock: time.Now().Add(time.Hour), wantReason: evmfacilitator.ReasonExpired},
+		{name: "not yet valid", chain: fakeChain{balance: 10000}, clock: time.Now().Add(-time.Hour), wantReason: evmfacilitator.ReasonNotYetValid},
+		{name: "nonce used", chain: fakeChain{balance: 10000, nonceUsed: true}, wantReason: evmfacilitator.ReasonNonceUsed},
+		{name: "insufficient funds", chain: fakeChain{balance: 9999}, wantReason: evmfacilitator.ReasonInsufficientFunds},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []evmfacilitator.Option
+			if !tt.clock.IsZero() {
+				opts = append(opts, evmfacilitator.WithClock(func() time.Time { return tt.clock }))
+			}
+			f := newFacilitator(t, &tt.chain, opts...)
+
+			req := requirement
+			if tt.modify != nil {
+				tt.modify(&req)
+			}
+			verification, err := f.Verify(context.Background(), payment, req)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if verification.IsValid != (tt.wantReason == "") || verification.InvalidReason != tt.wantReason {
+				t.Errorf("Verify() = %+v, want reason %q", verification, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestFacilitator_Settle(t *testing.T) {
+	tests := []struct {
+		name        string
+		success     bool
+		wantSuccess bool
+		wantReason  string
+	}{
+		{"mined", true, true, ""},
+		{"reverted", false, false, evmfacilitator.ReasonTransactionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := &fakeChain{balance: 10000, success: tt.success}
+			f := newFacilitator(t, chain)
+
+			settlement, err := f.Settle(context.Background(), x402test.Payment(t, requirement), requirement)
+			if err != nil {
+				t.Fatalf("Settle() error = %v", err)
+			}
+			if settlement.Success != tt.wantSuccess || settlement.ErrorReason != tt.wantReason ||
+				settlement.Transaction != "0xhash" {
+				t.Errorf("Settle() = %+v", settlement)
+			}
+			want := requirement.Asset + ":e3ee160e"
+			if len(chain.sent) != 1 || chain.sent[0] != want {
+				t.Errorf("sent = %v, want [%s]", chain.sent, want)
+			}
+		})
+	}
+}
+
+func TestFacilitator_SettleRejectsInvalidPayment(t *testing.T) {
+	chain := &fakeChain{balance: 0, success: true}
+	f := newFacilitator(t, chain)
+
+	settlement, err := f.Settle(context.Background(), x402test.Payment(t, requirement), requirement)
+	if err != nil {
+		t.Fatalf("Settle() error = %v", err)
+	}
+	if settlement.Success || settlement.ErrorReason != evmfacilitator.ReasonInsufficientFunds {
+		t.Errorf("Settle() = %+v", settlement)
+	}
+	if len(chain.sent) != 0 {
+		t.Errorf("expected no transaction, got %v", chain.sent)
+	}
+}
+
+func TestFacilitator_RejectsUnknownAsset(t *testing.T) {
+	// A payment signed for a contract the caller controls
+	unknown := requirement
+	unknown.Asset = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+	payment := x402test.Payment(t, unknown)
+
+	chain := &fakeChain{balance: 10000, success: true}
+	f := newFacilitator(t, chain)
+	verification, err := f.Verify(context.Background(), payment, unknown)
+	if err != nil || verification.IsValid || verification.InvalidReason != evmfacilitator.ReasonInvalidAsset {
+		t.Errorf("Verify() = %+v, %v, want %s", verification, err, evmfacilitator.ReasonInvalidAsset)
+	}
+	settlement, err := f.Settle(context.Background(), payment, unknown)
+	if err != nil || settlement.Success || settlement.ErrorReason != evmfacilitator.ReasonInvalidAsset {
+		t.Errorf("Settle() = %+v, %v, want %s", settlement, err, evmfacilitator.ReasonInvalidAsset)
+	}
+	if len(chain.sent) != 0 {
+		t.Errorf("expected no transaction, got %v", chain.sent)
+	}
+
+	// Allowed explicitly
+	f = newFacilitator(t, chain, evmfacilitator.WithAssets(unknown.Asset))
+	if verification, err := f.Verify(context.Background(), payment, unknown); err != nil || !verification.IsValid {
+		t.Errorf("Verify() with allowed asset = %+v, %v", verification, err)
+	}
+
+	if _, err := evmfacilitator.NewFacilitator("base-sepolia", chain, evmfacilitator.WithAssets("not-an-address")); !errors.Is(err, evmfacilitator.ErrInvalidAddress) {
+		t.Errorf("NewFacilitator() with invalid asset: error = %v, want ErrInvalidAddress", err)
+	}
+}
+
+func TestFacilitator_RejectsOversizedValue(t *testing.T) {
+	payment := x402test.Payment(t, requirement)
+	payload, err := payment.DecodeEVMPayload()
+	if err != nil {
+		t.Fatalf("DecodeEVMPayload() error = %v", err)
+	}
+	// Wider than a uint256; must be rejected rather than panic while hashing
+	payload.Authorization.Value = "1" + strings.Repeat("0", 90)
+	payment.Payload = *payload
+
+	chain := &fakeChain{balance: 10000, success: true}
+	f := newFacilitator(t, chain)
+
+	verification, err := f.Verify(context.Background(), payment, requirement)
+	if err != nil || verification.IsValid || verification.InvalidReason != evmfacilitator.ReasonInvalidPayload {
+		t.Errorf("Verify() = %+v, %v, want %s", verification, err, evmfacilitator.ReasonInvalidPayload)
+	}
+	settlement, err := f.Settle(context.Background(), payment, requirement)
+	if err != nil || settlement.Success || settlement.ErrorReason != evmfacilitator.ReasonInvalidPayload {
+		t.Errorf("Settle() = %+v, %v, want %s", settlement, err, evmfacilitator.ReasonInvalidPayload)
+	}
+
+	body, err := json.Marshal(facilitator.Request{X402Version: 1, PaymentPayload: payment, PaymentRequirements: requirement})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := facilitator.NewHandler(f)
+	for _, path := range []string{"/verify", "/settle"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(string(body))))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), evmfacilitator.ReasonInvalidPayload) {
+			t.Errorf("POST %s = %d %s", path, rec.Code, rec.Body.String())
+		}
+	}
+	if len(chain.sent) != 0 {
+		t.Errorf("expected no transaction, got %v", chain.sent)
+	}
+}
+
+func TestRPCBackend_SendTransaction(t *testing.T) {
+	var raw string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+			Params []any  `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		results := map[string]any{
+			"eth_estimateGas":           "0x15f90",
+			"eth_maxPriorityFeePerGas":  "0x3b9aca00",
+			"eth_getBlockByNumber":      map[string]any{"baseFeePerGas": "0x5f5e100"},
+			"eth_getTransactionCount":   "0x7",
+			"eth_sendRawTransaction":    "0xabc",
+			"eth_getTransactionReceipt": map[string]any{"status": "0x1"},
+		}
+		if req.Method == "eth_sendRawTransaction" {
+			raw, _ = req.Params[0].(string)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "result": results[req.Method]})
+	}))
+	defer server.Close()
+
+	key, _ := hex.DecodeString("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	backend, err := evmfacilitator.NewRPCBackend("base-sepolia", server.URL, key,
+		evmfacilitator.WithReceiptPolling(time.Second, 10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewRPCBackend() error = %v", err)
+	}
+	if backend.Address() != "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23" {
+		t.Errorf("Address() = %s", backend.Address())
+	}
+
+	receipt, err := backend.SendTransaction(context.Background(), requirement.Asset, []byte{0xe3, 0xee, 0x16, 0x0e})
+	if err != nil {
+		t.Fatalf("SendTransaction() error = %v", err)
+	}
+	if receipt.TxHash != "0xabc" || !receipt.Success {
+		t.Errorf("receipt = %+v", receipt)
+	}
+	if !strings.HasPrefix(raw, "0x02") {
+		t.Errorf("expected an EIP-1559 transaction, got %s", raw)
+	}
+
+	if _, err := backend.SendTransaction(context.Background(), "not-an-address", nil); !errors.Is(err, evmfacilitator.ErrInvalidAddress) {
+		t.Errorf("expected ErrInvalidAddress, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+// Package facilitator defines the contract between the x402 middleware and a
+// facilitator service. Implement Interface to verify and settle payments
+// in-process, against a mock, or through a proxy.
+package facilitator
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	x402 "github.com/dexfra-fun/x402-go"
+)
+
+// Interface defines the standard facilitator contract for payment verification and settlement.
+type Interface interface {
+	Verify(ctx context.Context, payment x402.PaymentPayload, requirement x402.PaymentRequirement) (*VerifyResponse, error)
+	Settle(ctx context.Context, payment x402.PaymentPayload, requirement x402.PaymentRequirement) (*x402.SettlementResponse, error)
+	Supported(ctx context.Context) (*SupportedResponse, error)
+}
+
+// VerifyResponse contains the payment verification result from the facilitator.
+type VerifyResponse struct {
+	IsValid       bool   `json:"isValid"`
+	InvalidReason string `json:"invalidReason,omitempty"`
+	Payer         string `json:"payer"`
+}
+
+// SupportedKind describes a supported payment type with its configuration.
+type SupportedKind struct {
+	X402Version int            `json:"x402Version"`
+	Scheme      string         `json:"scheme"`
+	Network     string         `json:"network"`
+	Extra       map[string]any `json:"extra,omitempty"`
+}
+
+// FeePayer returns extra.feePayer, or an empty string if it is not set.
+func (k SupportedKind) FeePayer() string {
+	feePayer, _ := k.Extra["feePayer"].(string)
+	return strings.TrimSpace(feePayer)
+}
+
+// SupportedResponse lists all payment types supported by the facilitator.
+type SupportedResponse struct {
+	Kinds []SupportedKind `json:"kinds"`
+}
+
+// FeePayer returns the fee payer of the first kind for network (case-insensitive).
+// It reports false if the network is not supported or has no fee payer.
+func (r *SupportedResponse) FeePayer(network string) (string, bool) {
+	target := strings.ToLower(strings.TrimSpace(network))
+	for _, kind := range r.Kinds {
+		if strings.ToLower(kind.Network) == target {
+			feePayer := kind.FeePayer()
+			return feePayer, feePayer != ""
+		}
+	}
+	return "", false
+}
+
+// FeePayers returns the distinct fee payers of all kinds for network
+// (case-insensitive), in the order they are listed.
+func (r *SupportedResponse) FeePayers(network string) []string {
+	target := strings.ToLower(strings.TrimSpace(network))
+	var feePayers []string
+	for _, kind := range r.Kinds {
+		feePayer := kind.FeePayer()
+		if strings.ToLower(kind.Network) == target && feePayer != "" && !slices.Contains(feePayers, feePayer) {
+			feePayers = append(feePayers, feePayer)
+		}
+	}
+	return feePayers
+}
@@ -0,0 +1,32 @@
+package facilitator
+
+import "testing"
+
+func TestSupportedResponse_FeePayer(t *testing.T) {
+	supported := &SupportedResponse{Kinds: []SupportedKind{
+		{Scheme: "exact", Network: "base"},
+		{Scheme: "exact", Network: "solana", Extra: map[string]any{"feePayer": " FeePayer111 "}},
+		{Scheme: "exact", Network: "solana-devnet", Extra: map[string]any{"feePayer": 42}},
+	}}
+
+	tests := []struct {
+		network   string
+		wantPayer string
+		wantFound bool
+	}{
+		{"solana", "FeePayer111", true},
+		{"Solana", "FeePayer111", true},
+		{"base", "", false},
+		{"solana-devnet", "", false},
+		{"polygon", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			feePayer, found := supported.FeePayer(tt.network)
+			if feePayer != tt.wantPayer || found != tt.wantFound {
+				t.Errorf("FeePayer(%q) = %q, %v; want %q, %v", tt.network, feePayer, found, tt.wantPayer, tt.wantFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package facilitator
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/bytedance/sonic"
+	x402 "github.com/dexfra-fun/x402-go"
+)
+
+// maxRequestBody limits the size of /verify and /settle request bodies.
+const maxRequestBody = 1 << 20
+
+// Request is the body of /verify and /settle requests.
+type Request struct {
+	X402Version         int                     `json:"x402Version"`
+	PaymentPayload      x402.PaymentPayload     `json:"paymentPayload"`
+	PaymentRequirements x402.PaymentRequirement `json:"paymentRequirements"`
+}
+
+// errorResponse is returned when a request cannot be processed.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler exposes a facilitator over HTTP with the standard /supported,
+// /verify and /settle endpoints, so other services can use it through a
+// FacilitatorURL. Mount it under a prefix with http.StripPrefix.
+//
+// Invalid payments are reported in the response body with status 200. Errors
+// from the facilitator are returned as 500, so clients never retry a
+// settlement that may have been submitted.
+func NewHandler(f Interface) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /supported", func(w http.ResponseWriter, r *http.Request) {
+		supported, err := f.Supported(r.Context())
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, supported)
+	})
+
+	mux.HandleFunc("POST /verify", func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeRequest(w, r)
+		if !ok {
+			return
+		}
+		verification, err := f.Verify(r.Context(), req.PaymentPayload, req.PaymentRequirements)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, verification)
+	})
+
+	mux.HandleFunc("POST /settle", func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeRequest(w, r)
+		if !ok {
+			return
+		}
+		settlement, err := f.Settle(r.Context(), req.PaymentPayload, req.PaymentRequirements)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, settlement)
+	})
+
+	return mux
+}
+
+// decodeRequest reads a /verify or /settle body, writing 400 if it is malformed.
+func decodeRequest(w http.ResponseWriter, r *http.Request) (*Request, bool) {
+	raw, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "read request: " + err.Error()})
+		return nil, false
+	}
+	var req Request
+	if err := sonic.Unmarshal(raw, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "decode request: " + err.Error()})
+		return nil, false
+	}
+	return &req, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = sonic.ConfigDefault.NewEncoder(w).Encode(v)
+}
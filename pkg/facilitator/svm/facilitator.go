@@ -0,0 +1,380 @@
+// Package svm is a self-hosted x402 facilitator for Solana. It verifies
+// "exact" payments locally, co-signs them with the operator's fee payer key
+// and submits them through an RPC endpoint.
+//
+// The facilitator implements facilitator.Interface and can be used directly as
+// Config.Facilitator, or exposed to other services with facilitator.NewHandler.
+package svm
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/internal/svm"
+	"github.com/dexfra-fun/x402-go/pkg/facilitator"
+)
+
+const (
+	// DefaultMaxComputeUnitPrice caps the priority fee the fee payer will pay, in micro-lamports.
+	DefaultMaxComputeUnitPrice = 5_000_000
+
+	// DefaultMaxComputeUnitLimit caps the compute units the fee payer will pay
+	// the priority fee for. A token transfer needs well under this.
+	DefaultMaxComputeUnitLimit = 200_000
+
+	// implicitComputeUnitLimit is the limit of a transaction without a
+	// SetComputeUnitLimit instruction: 200,000 units for its one transfer.
+	implicitComputeUnitLimit = 200_000
+
+	// defaultConfirmTimeout is how long Settle waits for a transaction to confirm.
+	defaultConfirmTimeout = 30 * time.Second
+
+	// defaultPollInterval is how often Settle polls the transaction status.
+	defaultPollInterval = 500 * time.Millisecond
+)
+
+// Reasons reported in VerifyResponse.InvalidReason and SettlementResponse.ErrorReason.
+const (
+	ReasonUnsupportedScheme   = "unsupported_scheme"
+	ReasonInvalidNetwork      = "invalid_network"
+	ReasonInvalidTransaction  = "invalid_exact_svm_payload_transaction"
+	ReasonInvalidInstructions = "invalid_exact_svm_payload_transaction_instructions"
+	ReasonInvalidFeePayer     = "invalid_exact_svm_payload_transaction_fee_payer"
+	ReasonInvalidSignature    = "invalid_exact_svm_payload_transaction_signature"
+	ReasonInvalidMint         = "invalid_exact_svm_payload_transaction_mint"
+	ReasonInvalidRecipient    = "invalid_exact_svm_payload_transaction_recipient"
+	ReasonInvalidAmount       = "invalid_exact_svm_payload_transaction_amount"
+	ReasonComputePriceTooHigh = "invalid_exact_svm_payload_transaction_compute_price"
+	ReasonComputeLimitTooHigh = "invalid_exact_svm_payload_transaction_compute_limit"
+	ReasonTransactionFailed   = "transaction_failed"
+)
+
+// ErrConfirmationTimeout indicates a submitted transaction did not confirm in time.
+// Its outcome is unknown and the payment must not be resubmitted elsewhere.
+var ErrConfirmationTimeout = errors.New("svm facilitator: transaction not confirmed in time")
+
+// RPC submits transactions to a Solana cluster.
+type RPC interface {
+	// SendTransaction submits a signed transaction and returns its base58 signature.
+	SendTransaction(ctx context.Context, tx []byte) (string, error)
+	// GetSignatureStatus returns the status of a submitted transaction.
+	GetSignatureStatus(ctx context.Context, signature string) (SignatureStatus, error)
+}
+
+// SignatureStatus is the processing status of a transaction.
+type SignatureStatus struct {
+	// Confirmed reports whether the transaction reached confirmed commitment.
+	Confirmed bool
+	// Err is the execution error of a failed transaction, if any.
+	Err string
+}
+
+// rpcClient adapts the JSON-RPC client to the RPC interface.
+type rpcClient struct {
+	client *svm.RPCClient
+}
+
+// NewRPCClient creates an RPC backed by a Solana JSON-RPC endpoint.
+func NewRPCClient(rpcURL string) RPC {
+	return &rpcClient{client: svm.NewRPCClient(rpcURL, nil)}
+}
+
+// SendTransaction implements RPC.
+func (c *rpcClient) SendTransaction(ctx context.Context, tx []byte) (string, error) {
+	return c.client.SendTransaction(ctx, tx)
+}
+
+// GetSignatureStatus implements RPC.
+func (c *rpcClient) GetSignatureStatus(ctx context.Context, signature string) (SignatureStatus, error) {
+	status, err := c.client.GetSignatureStatus(ctx, signature)
+	if err != nil {
+		return SignatureStatus{}, err
+	}
+	result := SignatureStatus{
+		Confirmed: status.ConfirmationStatus == "confirmed" || status.ConfirmationStatus == "finalized",
+	}
+	if status.Err != nil {
+		result.Err = fmt.Sprint(status.Err)
+	}
+	return result, nil
+}
+
+// Facilitator verifies and settles Solana payments for one network.
+type Facilitator struct {
+	network             string
+	feePayer            ed25519.PrivateKey
+	feePayerKey         svm.PublicKey
+	rpc                 RPC
+	maxComputeUnitPrice uint64
+	maxComputeUnitLimit uint32
+	confirmTimeout      time.Duration
+	pollInterval        time.Duration
+}
+
+// Option configures a Facilitator.
+type Option func(*Facilitator)
+
+// WithMaxComputeUnitPrice sets the highest compute unit price accepted, in micro-lamports.
+func WithMaxComputeUnitPrice(microLamports uint64) Option {
+	return func(f *Facilitator) {
+		f.maxComputeUnitPrice = microLamports
+	}
+}
+
+// WithMaxComputeUnitLimit sets the highest compute unit limit accepted. The
+// priority fee the fee payer pays is the unit price times this limit.
+func WithMaxComputeUnitLimit(units uint32) Option {
+	return func(f *Facilitator) {
+		f.maxComputeUnitLimit = units
+	}
+}
+
+// WithConfirmation sets how long and how often Settle polls for confirmation.
+func WithConfirmation(timeout, pollInterval time.Duration) Option {
+	return func(f *Facilitator) {
+		f.confirmTimeout = timeout
+		f.pollInterval = pollInterval
+	}
+}
+
+// NewFacilitator creates a facilitator for a Solana network that pays
+// transaction fees with feePayer and submits transactions through rpc.
+func NewFacilitator(network string, feePayer ed25519.PrivateKey, rpc RPC, opts ...Option) (*Facilitator, error) {
+	chain, ok := x402.GetChainConfig(network)
+	if !ok || chain.IsEVM() {
+		return nil, fmt.Errorf("%w: %s", x402.ErrInvalidNetwork, network)
+	}
+	if len(feePayer) != ed25519.PrivateKeySize {
+		return nil, x402.ErrInvalidKey
+	}
+
+	f := &Facilitator{
+		network:             chain.NetworkID,
+		feePayer:            feePayer,
+		rpc:                 rpc,
+		maxComputeUnitPrice: DefaultMaxComputeUnitPrice,
+		maxComputeUnitLimit: DefaultMaxComputeUnitLimit,
+		confirmTimeout:      defaultConfirmTimeout,
+		pollInterval:        defaultPollInterval,
+	}
+	copy(f.feePayerKey[:], feePayer.Public().(ed25519.PublicKey))
+
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f, nil
+}
+
+var _ facilitator.Interface = (*Facilitator)(nil)
+
+// FeePayer returns the base58 address that pays transaction fees.
+func (f *Facilitator) FeePayer() string {
+	return f.feePayerKey.String()
+}
+
+// Supported implements facilitator.Interface.
+func (f *Facilitator) Supported(context.Context) (*facilitator.SupportedResponse, error) {
+	return &facilitator.SupportedResponse{Kinds: []facilitator.SupportedKind{{
+		X402Version: 1,
+		Scheme:      x402.DefaultScheme,
+		Network:     f.network,
+		Extra:       map[string]any{"feePayer": f.FeePayer()},
+	}}}, nil
+}
+
+// Verify implements facilitator.Interface.
+func (f *Facilitator) Verify(
+	_ context.Context,
+	payment x402.PaymentPayload,
+	requirement x402.PaymentRequirement,
+) (*facilitator.VerifyResponse, error) {
+	_, owner, reason := f.check(payment, requirement)
+	if reason != "" {
+		return &facilitator.VerifyResponse{InvalidReason: reason, Payer: owner}, nil
+	}
+	return &facilitator.VerifyResponse{IsValid: true, Payer: owner}, nil
+}
+
+// Settle implements facilitator.Interface. The payment is verified again,
+// co-signed by the fee payer, submitted and awaited until confirmed.
+func (f *Facilitator) Settle(
+	ctx context.Context,
+	payment x402.PaymentPayload,
+	requirement x402.PaymentRequirement,
+) (*x402.SettlementResponse, error) {
+	tx, owner, reason := f.check(payment, requirement)
+	response := &x402.SettlementResponse{Network: f.network, Payer: owner}
+	if reason != "" {
+		response.ErrorReason = reason
+		return response, nil
+	}
+
+	if err := tx.Sign(f.feePayer); err != nil {
+		return nil, fmt.Errorf("sign transaction: %w", err)
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("encode transaction: %w", err)
+	}
+
+	signature, err := f.rpc.SendTransaction(ctx, raw)
+	if err != nil {
+		return nil, fmt.Errorf("send transaction: %w", err)
+	}
+	response.Transaction = signature
+
+	status, err := f.confirm(ctx, signature)
+	if err != nil {
+		return nil, err
+	}
+	if status.Err != "" {
+		response.ErrorReason = ReasonTransactionFailed
+		return response, nil
+	}
+
+	response.Success = true
+	return response, nil
+}
+
+// confirm polls the transaction status until it is confirmed or has failed.
+func (f *Facilitator) confirm(ctx context.Context, signature string) (SignatureStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.confirmTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(f.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		status, err := f.rpc.GetSignatureStatus(ctx, signature)
+		if err == nil && (status.Confirmed || status.Err != "") {
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return SignatureStatus{}, fmt.Errorf("%w: %s", ErrConfirmationTimeout, signature)
+		case <-ticker.C:
+		}
+	}
+}
+
+// check validates a payment against a requirement. It returns the decoded
+// transaction and the paying owner, or the reason the payment is invalid.
+func (f *Facilitator) check(
+	payment x402.PaymentPayload,
+	requirement x402.PaymentRequirement,
+) (*svm.Transaction, string, string) {
+	if payment.Scheme != x402.DefaultScheme || requirement.Scheme != x402.DefaultScheme {
+		return nil, "", ReasonUnsupportedScheme
+	}
+	if payment.Network != f.network || requirement.Network != f.network {
+		return nil, "", ReasonInvalidNetwork
+	}
+	if feePayer, _ := requirement.Extra["feePayer"].(string); feePayer != "" && feePayer != f.FeePayer() {
+		return nil, "", ReasonInvalidFeePayer
+	}
+
+	tx, err := decodeTransaction(payment)
+	if err != nil {
+		return nil, "", ReasonInvalidTransaction
+	}
+	if reason := f.checkStructure(tx); reason != "" {
+		return nil, "", reason
+	}
+
+	transfer, _ := tx.Message.FindTransferChecked()
+	owner := transfer.Owner.String()
+	return tx, owner, checkTransfer(transfer, requirement)
+}
+
+// checkStructure checks that the transaction only sets a compute budget and
+// transfers tokens, that the fee payer is not otherwise involved, and that
+// every signature except the fee payer's is present and valid.
+func (f *Facilitator) checkStructure(tx *svm.Transaction) string {
+	message := &tx.Message
+	if len(message.AccountKeys) == 0 || message.AccountKeys[0] != f.feePayerKey {
+		return ReasonInvalidFeePayer
+	}
+
+	transfers := 0
+	for _, ix := range message.Instructions {
+		for _, account := range ix.Accounts {
+			if account == 0 {
+				return ReasonInvalidFeePayer
+			}
+		}
+		if !message.IsComputeBudget(ix) {
+			transfers++
+		}
+	}
+	if _, ok := message.FindTransferChecked(); !ok || transfers != 1 {
+		return ReasonInvalidInstructions
+	}
+
+	budget, ok := message.ComputeBudget()
+	if !ok {
+		return ReasonInvalidInstructions
+	}
+	if budget.UnitPrice > f.maxComputeUnitPrice {
+		return ReasonComputePriceTooHigh
+	}
+	limit := budget.UnitLimit
+	if limit == 0 {
+		limit = implicitComputeUnitLimit
+	}
+	if limit > f.maxComputeUnitLimit {
+		return ReasonComputeLimitTooHigh
+	}
+
+	for i := 1; i < len(tx.Signatures); i++ {
+		if !tx.VerifySignature(i) {
+			return ReasonInvalidSignature
+		}
+	}
+	return ""
+}
+
+// checkTransfer checks the transfer's mint, destination and amount.
+func checkTransfer(transfer svm.TransferCheckedParams, requirement x402.PaymentRequirement) string {
+	if transfer.Mint.String() != requirement.Asset {
+		return ReasonInvalidMint
+	}
+
+	payTo, err := svm.ParsePublicKey(requirement.PayTo)
+	if err != nil {
+		return ReasonInvalidRecipient
+	}
+	destination, err := svm.FindAssociatedTokenAddress(payTo, transfer.Mint, transfer.TokenProgram)
+	if err != nil || destination != transfer.Destination {
+		return ReasonInvalidRecipient
+	}
+
+	amount, err := strconv.ParseUint(requirement.MaxAmountRequired, x402.DecimalBase, 64)
+	if err != nil || transfer.Amount != amount {
+		return ReasonInvalidAmount
+	}
+	return ""
+}
+
+// decodeTransaction decodes the base64 transaction of an SVM payment.
+func decodeTransaction(payment x402.PaymentPayload) (*svm.Transaction, error) {
+	payload, err := payment.DecodeSVMPayload()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(payload.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	var tx svm.Transaction
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
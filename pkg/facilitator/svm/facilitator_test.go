@@ -0,0 +1,261 @@
+package svm_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/internal/svm"
+	"github.com/dexfra-fun/x402-go/pkg/facilitator"
+	svmfacilitator "github.com/dexfra-fun/x402-go/pkg/facilitator/svm"
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+	"github.com/dexfra-fun/x402-go/pkg/x402test"
+	"github.com/shopspring/decimal"
+)
+
+const recipient = "9xQeWvG816bUx9EPjHmaT23yvVM2ZWbrrpZb9PusVFin"
+
+// fakeRPC records submitted transactions and reports them with a fixed status.
+type fakeRPC struct {
+	mu     sync.Mutex
+	sent   [][]byte
+	status svmfacilitator.SignatureStatus
+}
+
+func (r *fakeRPC) SendTransaction(_ context.Context, tx []byte) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, tx)
+	return "5sig", nil
+}
+
+func (r *fakeRPC) GetSignatureStatus(context.Context, string) (svmfacilitator.SignatureStatus, error) {
+	return r.status, nil
+}
+
+func newFacilitator(t *testing.T, rpc svmfacilitator.RPC) *svmfacilitator.Facilitator {
+	t.Helper()
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	f, err := svmfacilitator.NewFacilitator("solana-devnet", key, rpc,
+		svmfacilitator.WithConfirmation(50*time.Millisecond, 5*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewFacilitator() error = %v", err)
+	}
+	return f
+}
+
+func requirementFor(f *svmfacilitator.Facilitator) x402.PaymentRequirement {
+	return x402.PaymentRequirement{
+		Scheme:            x402.DefaultScheme,
+		Network:           "solana-devnet",
+		MaxAmountRequired: "10000",
+		Asset:             x402.SolanaDevnet.USDCAddress,
+		PayTo:             recipient,
+		MaxTimeoutSeconds: 60,
+		Extra:             map[string]any{"feePayer": f.FeePayer()},
+	}
+}
+
+func TestFacilitator_Verify(t *testing.T) {
+	f := newFacilitator(t, &fakeRPC{})
+	requirement := requirementFor(f)
+	payment := x402test.Payment(t, requirement)
+
+	tests := []struct {
+		name       string
+		modify     func(*x402.PaymentRequirement)
+		wantReason string
+	}{
+		{"valid", func(*x402.PaymentRequirement) {}, ""},
+		{"other asset", func(r *x402.PaymentRequirement) { r.Asset = x402.SolanaMainnet.USDCAddress }, svmfacilitator.ReasonInvalidMint},
+		{"other recipient", func(r *x402.PaymentRequirement) { r.PayTo = f.FeePayer() }, svmfacilitator.ReasonInvalidRecipient},
+		{"other amount", func(r *x402.PaymentRequirement) { r.MaxAmountRequired = "20000" }, svmfacilitator.ReasonInvalidAmount},
+		{"other fee payer", func(r *x402.PaymentRequirement) { r.Extra = map[string]any{"feePayer": recipient} }, svmfacilitator.ReasonInvalidFeePayer},
+		{"other network", func(r *x402.PaymentRequirement) { r.Network = "solana" }, svmfacilitator.ReasonInvalidNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := requirement
+			tt.modify(&req)
+
+			verification, err := f.Verify(context.Background(), payment, req)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if verification.IsValid != (tt.wantReason == "") || verification.InvalidReason != tt.wantReason {
+				t.Errorf("Verify() = %+v, want reason %q", verification, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestFacilitator_VerifyRejectsTampering(t *testing.T) {
+	f := newFacilitator(t, &fakeRPC{})
+	requirement := requirementFor(f)
+
+	tests := []struct {
+		name       string
+		tamper     func(*svm.Transaction)
+		wantReason string
+	}{
+		{"forged signature", func(tx *svm.Transaction) { tx.Signatures[1][0] ^= 0xff }, svmfacilitator.ReasonInvalidSignature},
+		{"fee payer as account", func(tx *svm.Transaction) {
+			tx.Message.Instructions[2].Accounts[0] = 0
+		}, svmfacilitator.ReasonInvalidFeePayer},
+		{"extra instruction", func(tx *svm.Transaction) {
+			tx.Message.Instructions = append(tx.Message.Instructions, tx.Message.Instructions[2])
+		}, svmfacilitator.ReasonInvalidInstructions},
+		{"expensive priority fee", func(tx *svm.Transaction) {
+			tx.Message.Instructions[1].Data = []byte{3, 0, 0, 0, 0, 0, 0, 0, 1}
+		}, svmfacilitator.ReasonComputePriceTooHigh},
+		{"excessive compute limit", func(tx *svm.Transaction) {
+			tx.Message.Instructions[0].Data = []byte{2, 0xc0, 0x5c, 0x15, 0} // 1,400,000 units
+		}, svmfacilitator.ReasonComputeLimitTooHigh},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := x402test.Payment(t, requirement)
+			payload, _ := payment.DecodeSVMPayload()
+			raw, _ := base64.StdEncoding.DecodeString(payload.Transaction)
+			var tx svm.Transaction
+			if err := tx.UnmarshalBinary(raw); err != nil {
+				t.Fatalf("UnmarshalBinary() error = %v", err)
+			}
+			tt.tamper(&tx)
+			raw, _ = tx.MarshalBinary()
+			payment.Payload = x402.SVMPayload{Transaction: base64.StdEncoding.EncodeToString(raw)}
+
+			verification, err := f.Verify(context.Background(), payment, requirement)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if verification.IsValid || verification.InvalidReason != tt.wantReason {
+				t.Errorf("Verify() = %+v, want reason %q", verification, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestFacilitator_MaxComputeUnitLimit(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	tests := []struct {
+		name       string
+		limit      uint32
+		wantReason string
+	}{
+		{"above the signer's limit", 20_000, ""},
+		{"below the signer's limit", 10_000, svmfacilitator.ReasonComputeLimitTooHigh},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := svmfacilitator.NewFacilitator("solana-devnet", key, &fakeRPC{},
+				svmfacilitator.WithMaxComputeUnitLimit(tt.limit))
+			if err != nil {
+				t.Fatalf("NewFacilitator() error = %v", err)
+			}
+			requirement := requirementFor(f)
+
+			verification, err := f.Verify(context.Background(), x402test.Payment(t, requirement), requirement)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if verification.IsValid != (tt.wantReason == "") || verification.InvalidReason != tt.wantReason {
+				t.Errorf("Verify() = %+v, want reason %q", verification, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestFacilitator_Settle(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      svmfacilitator.SignatureStatus
+		wantSuccess bool
+		wantReason  string
+		wantErr     error
+	}{
+		{"confirmed", svmfacilitator.SignatureStatus{Confirmed: true}, true, "", nil},
+		{"failed on chain", svmfacilitator.SignatureStatus{Err: "InsufficientFunds"}, false, svmfacilitator.ReasonTransactionFailed, nil},
+		{"not confirmed", svmfacilitator.SignatureStatus{}, false, "", svmfacilitator.ErrConfirmationTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpc := &fakeRPC{status: tt.status}
+			f := newFacilitator(t, rpc)
+			requirement := requirementFor(f)
+
+			settlement, err := f.Settle(context.Background(), x402test.Payment(t, requirement), requirement)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Settle() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if settlement.Success != tt.wantSuccess || settlement.ErrorReason != tt.wantReason {
+				t.Errorf("Settle() = %+v", settlement)
+			}
+			if settlement.Transaction != "5sig" {
+				t.Errorf("transaction = %q, want 5sig", settlement.Transaction)
+			}
+
+			var sent svm.Transaction
+			if len(rpc.sent) != 1 || sent.UnmarshalBinary(rpc.sent[0]) != nil {
+				t.Fatalf("expected one submitted transaction, got %d", len(rpc.sent))
+			}
+			for i := range sent.Signatures {
+				if !sent.VerifySignature(i) {
+					t.Errorf("signature %d of submitted transaction is invalid", i)
+				}
+			}
+		})
+	}
+}
+
+type fixedPrice decimal.Decimal
+
+func (p fixedPrice) GetPrice(context.Context, localx402.Resource) (decimal.Decimal, error) {
+	return decimal.Decimal(p), nil
+}
+
+func TestFacilitator_OverHTTP(t *testing.T) {
+	rpc := &fakeRPC{status: svmfacilitator.SignatureStatus{Confirmed: true}}
+	server := httptest.NewServer(facilitator.NewHandler(newFacilitator(t, rpc)))
+	defer server.Close()
+
+	m, err := localx402.New(&localx402.Config{
+		RecipientAddress: recipient,
+		Network:          "solana-devnet",
+		FacilitatorURL:   server.URL,
+		PricingStrategy:  fixedPrice(decimal.RequireFromString("0.01")),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	requirements, _, err := m.ProcessRequest(context.Background(), localx402.Resource{Path: "/api", Method: http.MethodGet})
+	if err != nil {
+		t.Fatalf("ProcessRequest() error = %v", err)
+	}
+	payment := x402test.Payment(t, requirements[0])
+
+	verification, err := m.GetFacilitator().Verify(context.Background(), payment, requirements[0])
+	if err != nil || !verification.IsValid {
+		t.Fatalf("Verify() = %+v, %v", verification, err)
+	}
+	settlement, err := m.GetFacilitator().Settle(context.Background(), payment, requirements[0])
+	if err != nil || !settlement.Success {
+		t.Fatalf("Settle() = %+v, %v", settlement, err)
+	}
+}
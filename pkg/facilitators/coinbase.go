@@ -9,7 +9,8 @@ var Coinbase = &Facilitator{
 		Name:    "Coinbase",
 		DocsURL: "https://docs.cdp.coinbase.com/x402/welcome",
 	},
-	URL: "https://facilitator.coinbase.com",
+	URL:  "https://facilitator.coinbase.com",
+	Auth: AuthCDP,
 	Addresses: map[Network][]FacilitatorAddress{
 		NetworkSolana: {
 			{
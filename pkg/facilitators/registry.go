@@ -2,6 +2,7 @@ package facilitators
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 )
 
@@ -40,6 +41,21 @@ func (r *Registry) Get(id string) *Facilitator {
 	return r.facilitators[id]
 }
 
+// FindByURL retrieves the facilitator whose base URL is url, ignoring case and
+// trailing slashes. Returns nil if not found.
+func (r *Registry) FindByURL(url string) *Facilitator {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	url = strings.TrimRight(url, "/")
+	for _, f := range r.facilitators {
+		if strings.EqualFold(strings.TrimRight(f.URL, "/"), url) {
+			return f
+		}
+	}
+	return nil
+}
+
 // List returns all registered facilitators.
 // If network is specified, only facilitators supporting that network are returned.
 func (r *Registry) List(network Network) []*Facilitator {
@@ -84,6 +100,11 @@ func Get(id string) *Facilitator {
 	return globalRegistry.Get(id)
 }
 
+// FindByURL retrieves a facilitator from the global registry by base URL.
+func FindByURL(url string) *Facilitator {
+	return globalRegistry.FindByURL(url)
+}
+
 // List returns all facilitators from the global registry.
 func List(network Network) []*Facilitator {
 	return globalRegistry.List(network)
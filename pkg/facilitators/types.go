@@ -10,12 +10,48 @@ const (
 	NetworkSolana Network = "solana"
 )
 
+// AuthScheme identifies how a facilitator authenticates API requests.
+type AuthScheme string
+
+const (
+	// AuthNone means the facilitator accepts unauthenticated requests.
+	AuthNone AuthScheme = ""
+	// AuthCDP means requests need a Coinbase Developer Platform API key JWT.
+	AuthCDP AuthScheme = "cdp"
+)
+
 // Facilitator represents a complete facilitator configuration.
 type Facilitator struct {
 	ID        string
 	Metadata  FacilitatorMetadata
 	URL       string
 	Addresses map[Network][]FacilitatorAddress
+	// Auth is the authentication the facilitator requires, if any.
+	Auth AuthScheme
+}
+
+// RequiresAuth reports whether requests to the facilitator must be authenticated.
+func (f *Facilitator) RequiresAuth() bool {
+	return f.Auth != AuthNone
+}
+
+// FeePayers returns the facilitator's registered fee payer addresses on network.
+func (f *Facilitator) FeePayers(network Network) []string {
+	addresses := make([]string, 0, len(f.Addresses[network]))
+	for _, address := range f.Addresses[network] {
+		addresses = append(addresses, address.Address)
+	}
+	return addresses
+}
+
+// HasFeePayer reports whether address is a registered fee payer on network.
+func (f *Facilitator) HasFeePayer(network Network, address string) bool {
+	for _, registered := range f.Addresses[network] {
+		if registered.Address == address {
+			return true
+		}
+	}
+	return false
 }
 
 // FacilitatorMetadata contains display information for a facilitator.
@@ -0,0 +1,66 @@
+// Package ledger provides PaymentRecorder implementations that keep an audit
+// log of x402 payments for reconciliation against on-chain data.
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/bytedance/sonic"
+	"github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+// JSONLRecorder writes one JSON object per line to a writer, e.g. an
+// append-only file shipped to a log pipeline or data warehouse.
+type JSONLRecorder struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewJSONLRecorder creates a recorder writing to w. Writes are serialized.
+func NewJSONLRecorder(w io.Writer) *JSONLRecorder {
+	return &JSONLRecorder{w: w}
+}
+
+// OpenJSONL creates a recorder appending to the file at path, creating it if
+// needed. Close closes the file.
+func OpenJSONL(path string) (*JSONLRecorder, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open ledger: %w", err)
+	}
+	return &JSONLRecorder{w: file, closer: file}, nil
+}
+
+var _ x402.PaymentRecorder = (*JSONLRecorder)(nil)
+
+// Record implements x402.PaymentRecorder.
+func (r *JSONLRecorder) Record(_ context.Context, record x402.PaymentRecord) error {
+	line, err := sonic.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal payment record: %w", err)
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.w.Write(line); err != nil {
+		return fmt.Errorf("write payment record: %w", err)
+	}
+	return nil
+}
+
+// Close closes the file opened by OpenJSONL. It does nothing for recorders
+// created with NewJSONLRecorder.
+func (r *JSONLRecorder) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closer.Close()
+}
@@ -0,0 +1,241 @@
+package ledger_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/internal/sqltest"
+	x402http "github.com/dexfra-fun/x402-go/pkg/adapters/http"
+	"github.com/dexfra-fun/x402-go/pkg/ledger"
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+	"github.com/dexfra-fun/x402-go/pkg/x402test"
+	"github.com/shopspring/decimal"
+)
+
+type fixedPrice decimal.Decimal
+
+func (p fixedPrice) GetPrice(context.Context, localx402.Resource) (decimal.Decimal, error) {
+	return decimal.Decimal(p), nil
+}
+
+// memoryRecorder keeps records in memory.
+type memoryRecorder struct {
+	mu      sync.Mutex
+	records []localx402.PaymentRecord
+}
+
+func (r *memoryRecorder) Record(_ context.Context, record localx402.PaymentRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, record)
+	return nil
+}
+
+func settledRecord() localx402.PaymentRecord {
+	return localx402.PaymentRecord{
+		Kind:        localx402.RecordSettled,
+		Time:        time.UnixMilli(1_700_000_000_000).UTC(),
+		RequestID:   "req-1",
+		Route:       "/api/1",
+		Method:      http.MethodGet,
+		Price:       decimal.RequireFromString("0.01"),
+		Amount:      "10000",
+		Asset:       "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+		Currency:    "USDC",
+		Network:     "base-sepolia",
+		Scheme:      "exact",
+		PayTo:       "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+		Payer:       "0x857b06519E91e3A54538791bDbb0E22373e36b66",
+		Transaction: "0xabc",
+		Facilitator: "https://facilitator.example",
+		VerifiedAt:  time.UnixMilli(1_699_999_999_000).UTC(),
+	}
+}
+
+func TestJSONLRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := ledger.NewJSONLRecorder(&buf)
+
+	want := settledRecord()
+	failed := localx402.PaymentRecord{
+		Kind:         localx402.RecordVerified,
+		Time:         want.Time,
+		Route:        "/api/2",
+		ErrorCode:    x402.ErrCodeInvalidPayment,
+		ErrorMessage: "invalid signature",
+	}
+	for _, record := range []localx402.PaymentRecord{want, failed} {
+		if err := recorder.Record(context.Background(), record); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	var got []localx402.PaymentRecord
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var record localx402.PaymentRecord
+		if err := sonic.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, record)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d lines, want 2", len(got))
+	}
+	if got[0].Transaction != want.Transaction || !got[0].Price.Equal(want.Price) || !got[0].VerifiedAt.Equal(want.VerifiedAt) {
+		t.Errorf("first record = %+v, want %+v", got[0], want)
+	}
+	if got[1].Success() || !got[1].VerifiedAt.IsZero() {
+		t.Errorf("second record = %+v, want a failure without verification time", got[1])
+	}
+}
+
+func TestOpenJSONL_Appends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payments.jsonl")
+	for range 2 {
+		recorder, err := ledger.OpenJSONL(path)
+		if err != nil {
+			t.Fatalf("OpenJSONL: %v", err)
+		}
+		if err := recorder.Record(context.Background(), settledRecord()); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+		if err := recorder.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read ledger: %v", err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines != 2 {
+		t.Errorf("got %d lines, want 2", lines)
+	}
+}
+
+func TestSQLRecorder(t *testing.T) {
+	db := sqltest.Open(t)
+
+	ctx := context.Background()
+	recorder := ledger.NewSQLRecorder(db, ledger.WithTable("payments"))
+	for range 2 {
+		if err := recorder.CreateTable(ctx); err != nil {
+			t.Fatalf("CreateTable: %v", err)
+		}
+	}
+
+	want := settledRecord()
+	if err := recorder.Record(ctx, want); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := recorder.Record(ctx, localx402.PaymentRecord{Kind: localx402.RecordRequirementIssued}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	var (
+		kind, tx, facilitator, price string
+		recordedAt                   int64
+		verifiedAt                   sql.NullInt64
+	)
+	err := db.QueryRowContext(ctx,
+		"SELECT kind, tx, facilitator, price, recorded_at, verified_at FROM payments WHERE tx = ?", want.Transaction,
+	).Scan(&kind, &tx, &facilitator, &price, &recordedAt, &verifiedAt)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if kind != string(want.Kind) || facilitator != want.Facilitator || !decimal.RequireFromString(price).Equal(want.Price) {
+		t.Errorf("row = %s %s %s, want %s %s %s", kind, facilitator, price, want.Kind, want.Facilitator, want.Price)
+	}
+	if recordedAt != want.Time.UnixMilli() || verifiedAt.Int64 != want.VerifiedAt.UnixMilli() {
+		t.Errorf("times = %d %v, want %d %d", recordedAt, verifiedAt, want.Time.UnixMilli(), want.VerifiedAt.UnixMilli())
+	}
+
+	var unverified int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM payments WHERE verified_at IS NULL").Scan(&unverified); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if unverified != 1 {
+		t.Errorf("unverified rows = %d, want 1", unverified)
+	}
+}
+
+func TestRecorder_Middleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		behavior x402test.Behavior
+		kinds    []localx402.RecordKind
+		code     x402.ErrorCode
+	}{
+		{
+			name:  "settled",
+			kinds: []localx402.RecordKind{localx402.RecordRequirementIssued, localx402.RecordVerified, localx402.RecordSettled},
+		},
+		{
+			name:     "invalid",
+			behavior: x402test.Behavior{InvalidReason: "invalid_signature"},
+			kinds:    []localx402.RecordKind{localx402.RecordRequirementIssued, localx402.RecordVerified},
+			code:     x402.ErrCodeInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &memoryRecorder{}
+			f := x402test.NewFacilitator(t, x402test.WithBehavior(tt.behavior))
+			handler := x402http.NewMiddleware(&localx402.Config{
+				RecipientAddress: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+				Network:          "base-sepolia",
+				FacilitatorURL:   f.URL,
+				PricingStrategy:  fixedPrice(decimal.RequireFromString("0.01")),
+				Recorder:         recorder,
+			})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("content"))
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/1", nil))
+			requirements := x402test.PaymentRequirements(t, rec.Body)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/1", nil)
+			req.Header.Set(localx402.HeaderPayment, x402test.PaymentHeader(t, requirements[0]))
+			req.Header.Set(localx402.HeaderRequestID, "req-42")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			records := recorder.records
+			if len(records) != len(tt.kinds) {
+				t.Fatalf("got %d records, want %d: %+v", len(records), len(tt.kinds), records)
+			}
+			for i, kind := range tt.kinds {
+				if records[i].Kind != kind {
+					t.Errorf("record %d kind = %s, want %s", i, records[i].Kind, kind)
+				}
+			}
+			if issued := records[0]; issued.Route != "/api/1" || !issued.Price.Equal(decimal.RequireFromString("0.01")) {
+				t.Errorf("issued record = %+v", issued)
+			}
+
+			last := records[len(records)-1]
+			if last.RequestID != "req-42" || last.Facilitator != f.URL || last.Network != "base-sepolia" {
+				t.Errorf("last record = %+v, want request ID, facilitator and network", last)
+			}
+			if last.ErrorCode != tt.code {
+				t.Errorf("error code = %q, want %q", last.ErrorCode, tt.code)
+			}
+			if tt.code == "" && (last.Transaction == "" || last.Payer == "" || last.VerifiedAt.IsZero()) {
+				t.Errorf("settled record = %+v, want transaction, payer and verification time", last)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+// DefaultTable is the default table name used by SQLRecorder.
+const DefaultTable = "x402_payments"
+
+// columns are the record columns in insert order.
+var columns = []string{
+	"kind", "recorded_at", "request_id", "route", "method",
+	"price", "amount", "asset", "currency", "network", "scheme", "pay_to",
+	"payer", "tx", "facilitator", "verified_at", "error_code", "error_message",
+}
+
+// SQLRecorder is a PaymentRecorder writing to a SQL table, e.g. in the
+// application's database so payments can be joined with its own data.
+// Times are stored as Unix milliseconds (verified_at is NULL until verified).
+//
+// Schema (see CreateTable; PostgreSQL uses BIGSERIAL for id):
+//
+//	CREATE TABLE x402_payments (
+//	    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+//	    kind          VARCHAR(32)  NOT NULL,
+//	    recorded_at   BIGINT       NOT NULL,
+//	    request_id    VARCHAR(255) NOT NULL,
+//	    route         TEXT         NOT NULL,
+//	    method        VARCHAR(16)  NOT NULL,
+//	    price         NUMERIC      NOT NULL,
+//	    amount        VARCHAR(78)  NOT NULL,
+//	    asset         VARCHAR(128) NOT NULL,
+//	    currency      VARCHAR(32)  NOT NULL,
+//	    network       VARCHAR(64)  NOT NULL,
+//	    scheme        VARCHAR(32)  NOT NULL,
+//	    pay_to        VARCHAR(128) NOT NULL,
+//	    payer         VARCHAR(128) NOT NULL,
+//	    tx            VARCHAR(128) NOT NULL,
+//	    facilitator   TEXT         NOT NULL,
+//	    verified_at   BIGINT,
+//	    error_code    VARCHAR(64)  NOT NULL,
+//	    error_message TEXT         NOT NULL
+//	);
+//	CREATE INDEX x402_payments_recorded_at ON x402_payments (recorded_at);
+//	CREATE INDEX x402_payments_tx ON x402_payments (tx);
+type SQLRecorder struct {
+	db       *sql.DB
+	table    string
+	postgres bool
+	insert   string
+}
+
+// SQLOption configures a SQLRecorder.
+type SQLOption func(*SQLRecorder)
+
+// WithTable sets the table name.
+func WithTable(table string) SQLOption {
+	return func(r *SQLRecorder) {
+		r.table = table
+	}
+}
+
+// WithPostgres uses PostgreSQL placeholders ($1, $2, ...) and a BIGSERIAL id.
+// The default schema and ? placeholders are for SQLite.
+func WithPostgres() SQLOption {
+	return func(r *SQLRecorder) {
+		r.postgres = true
+	}
+}
+
+// NewSQLRecorder creates a recorder using db. The table must exist; see CreateTable.
+func NewSQLRecorder(db *sql.DB, opts ...SQLOption) *SQLRecorder {
+	r := &SQLRecorder{
+		db:    db,
+		table: DefaultTable,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	placeholders := make([]string, len(columns))
+	for i := range columns {
+		placeholders[i] = "?"
+		if r.postgres {
+			placeholders[i] = "$" + strconv.Itoa(i+1)
+		}
+	}
+	r.insert = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		r.table, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+	return r
+}
+
+// CreateTable creates the payment table and its indexes if they do not exist.
+func (r *SQLRecorder) CreateTable(ctx context.Context) error {
+	id := "id INTEGER PRIMARY KEY AUTOINCREMENT"
+	if r.postgres {
+		id = "id BIGSERIAL PRIMARY KEY"
+	}
+	statements := []string{
+		"CREATE TABLE IF NOT EXISTS " + r.table + ` (
+			` + id + `,
+			kind VARCHAR(32) NOT NULL,
+			recorded_at BIGINT NOT NULL,
+			request_id VARCHAR(255) NOT NULL,
+			route TEXT NOT NULL,
+			method VARCHAR(16) NOT NULL,
+			price NUMERIC NOT NULL,
+			amount VARCHAR(78) NOT NULL,
+			asset VARCHAR(128) NOT NULL,
+			currency VARCHAR(32) NOT NULL,
+			network VARCHAR(64) NOT NULL,
+			scheme VARCHAR(32) NOT NULL,
+			pay_to VARCHAR(128) NOT NULL,
+			payer VARCHAR(128) NOT NULL,
+			tx VARCHAR(128) NOT NULL,
+			facilitator TEXT NOT NULL,
+			verified_at BIGINT,
+			error_code VARCHAR(64) NOT NULL,
+			error_message TEXT NOT NULL
+		)`,
+		"CREATE INDEX IF NOT EXISTS " + r.table + "_recorded_at ON " + r.table + " (recorded_at)",
+		"CREATE INDEX IF NOT EXISTS " + r.table + "_tx ON " + r.table + " (tx)",
+	}
+	for _, statement := range statements {
+		if _, err := r.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("create payment table: %w", err)
+		}
+	}
+	return nil
+}
+
+var _ x402.PaymentRecorder = (*SQLRecorder)(nil)
+
+// Record implements x402.PaymentRecorder.
+func (r *SQLRecorder) Record(ctx context.Context, record x402.PaymentRecord) error {
+	var verifiedAt sql.NullInt64
+	if !record.VerifiedAt.IsZero() {
+		verifiedAt = sql.NullInt64{Int64: record.VerifiedAt.UnixMilli(), Valid: true}
+	}
+	recordedAt := record.Time
+	if recordedAt.IsZero() {
+		recordedAt = time.Now()
+	}
+
+	if _, err := r.db.ExecContext(ctx, r.insert,
+		string(record.Kind), recordedAt.UnixMilli(), record.RequestID, record.Route, record.Method,
+		record.Price.String(), record.Amount, record.Asset, record.Currency, record.Network, record.Scheme,
+		record.PayTo, record.Payer, record.Transaction, record.Facilitator, verifiedAt,
+		string(record.ErrorCode), record.ErrorMessage,
+	); err != nil {
+		return fmt.Errorf("insert payment record: %w", err)
+	}
+	return nil
+}
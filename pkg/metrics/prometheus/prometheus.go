@@ -0,0 +1,180 @@
+// Package prometheus exports x402 payment metrics to Prometheus.
+//
+//	metrics, err := prometheus.New(prometheus.DefaultRegisterer)
+//	config.Metrics = metrics
+//
+// Metrics (with the default "x402" namespace):
+//
+//	x402_requirements_issued_total{route}                               402 responses to requests without payment
+//	x402_payments_verified_total{route, network, result, code}          payment attempts by verification outcome
+//	x402_payments_settled_total{route, network, result, code}           settlements by outcome
+//	x402_amount_settled_total{route, network, asset}                    settled amount in token units
+//	x402_facilitator_request_duration_seconds{facilitator, endpoint, result}  facilitator latency
+//
+// result is "success" or "failure"; code is the error code of a failure, e.g.
+// INSUFFICIENT_FUNDS. Routes default to the request method, since request paths
+// may contain IDs; use WithRoute to label requests by their route pattern.
+package prometheus
+
+import (
+	"net/http"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultNamespace prefixes all metric names.
+	DefaultNamespace = "x402"
+
+	resultSuccess = "success"
+	resultFailure = "failure"
+
+	// methodOther labels requests with non-standard methods.
+	methodOther = "OTHER"
+)
+
+// Metrics implements localx402.Metrics with Prometheus collectors.
+type Metrics struct {
+	requirements  *prometheus.CounterVec
+	verifications *prometheus.CounterVec
+	settlements   *prometheus.CounterVec
+	amount        *prometheus.CounterVec
+	latency       *prometheus.HistogramVec
+	route         func(localx402.Resource) string
+}
+
+// Option configures Metrics.
+type Option func(*options)
+
+type options struct {
+	namespace string
+	buckets   []float64
+	route     func(localx402.Resource) string
+}
+
+// WithNamespace sets the metric name prefix (default "x402").
+func WithNamespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// WithBuckets sets the facilitator latency histogram buckets in seconds
+// (default prometheus.DefBuckets).
+func WithBuckets(buckets []float64) Option {
+	return func(o *options) {
+		o.buckets = buckets
+	}
+}
+
+// WithRoute sets the route label of a resource, e.g. the router's pattern
+// "/api/items/{id}" (default: the request method). The labels it returns must
+// be bounded; do not return the request path if it contains IDs.
+func WithRoute(route func(localx402.Resource) string) Option {
+	return func(o *options) {
+		o.route = route
+	}
+}
+
+// New creates the collectors and registers them with registerer.
+func New(registerer prometheus.Registerer, opts ...Option) (*Metrics, error) {
+	o := options{
+		namespace: DefaultNamespace,
+		buckets:   prometheus.DefBuckets,
+		route:     methodRoute,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	m := &Metrics{
+		requirements: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Name:      "requirements_issued_total",
+			Help:      "Payment Required responses issued to requests without payment.",
+		}, []string{"route"}),
+		verifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Name:      "payments_verified_total",
+			Help:      "Payment attempts by verification outcome.",
+		}, []string{"route", "network", "result", "code"}),
+		settlements: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Name:      "payments_settled_total",
+			Help:      "Payment settlements by outcome.",
+		}, []string{"route", "network", "result", "code"}),
+		amount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Name:      "amount_settled_total",
+			Help:      "Settled payment amount in token units.",
+		}, []string{"route", "network", "asset"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: o.namespace,
+			Name:      "facilitator_request_duration_seconds",
+			Help:      "Duration of facilitator requests by endpoint.",
+			Buckets:   o.buckets,
+		}, []string{"facilitator", "endpoint", "result"}),
+		route: o.route,
+	}
+
+	for _, collector := range []prometheus.Collector{
+		m.requirements, m.verifications, m.settlements, m.amount, m.latency,
+	} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+var _ localx402.Metrics = (*Metrics)(nil)
+
+// RequirementsIssued implements localx402.Metrics.
+func (m *Metrics) RequirementsIssued(resource localx402.Resource, _ []x402.PaymentRequirement) {
+	m.requirements.WithLabelValues(m.route(resource)).Inc()
+}
+
+// PaymentVerified implements localx402.Metrics.
+func (m *Metrics) PaymentVerified(event localx402.PaymentEvent) {
+	m.verifications.WithLabelValues(m.route(event.Resource), event.Network, result(event), string(event.Code)).Inc()
+}
+
+// PaymentSettled implements localx402.Metrics.
+func (m *Metrics) PaymentSettled(event localx402.PaymentEvent) {
+	route := m.route(event.Resource)
+	m.settlements.WithLabelValues(route, event.Network, result(event), string(event.Code)).Inc()
+	if event.Success() && event.Amount.IsPositive() {
+		m.amount.WithLabelValues(route, event.Network, event.Asset).Add(event.Amount.InexactFloat64())
+	}
+}
+
+// FacilitatorRequest implements localx402.Metrics.
+func (m *Metrics) FacilitatorRequest(facilitator, endpoint string, duration time.Duration, err error) {
+	outcome := resultSuccess
+	if err != nil {
+		outcome = resultFailure
+	}
+	m.latency.WithLabelValues(facilitator, endpoint, outcome).Observe(duration.Seconds())
+}
+
+// result returns the result label of an event.
+func result(event localx402.PaymentEvent) string {
+	if event.Success() {
+		return resultSuccess
+	}
+	return resultFailure
+}
+
+// methodRoute labels a resource by its request method, the default route label.
+func methodRoute(resource localx402.Resource) string {
+	switch resource.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return resource.Method
+	default:
+		return methodOther
+	}
+}
@@ -0,0 +1,221 @@
+package prometheus_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	x402http "github.com/dexfra-fun/x402-go/pkg/adapters/http"
+	x402prom "github.com/dexfra-fun/x402-go/pkg/metrics/prometheus"
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+	"github.com/dexfra-fun/x402-go/pkg/x402test"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/shopspring/decimal"
+)
+
+type fixedPrice decimal.Decimal
+
+func (p fixedPrice) GetPrice(context.Context, localx402.Resource) (decimal.Decimal, error) {
+	return decimal.Decimal(p), nil
+}
+
+func newMetrics(t *testing.T) (*x402prom.Metrics, *prometheus.Registry) {
+	t.Helper()
+
+	registry := prometheus.NewRegistry()
+	metrics, err := x402prom.New(registry, x402prom.WithRoute(func(localx402.Resource) string { return "/api" }))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return metrics, registry
+}
+
+// pay requests the resource unpaid, then pays the first accepted requirement.
+func pay(t *testing.T, handler http.Handler) int {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/1", nil))
+	requirements := x402test.PaymentRequirements(t, rec.Body)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/1", nil)
+	req.Header.Set(localx402.HeaderPayment, x402test.PaymentHeader(t, requirements[0]))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestMetrics_Middleware(t *testing.T) {
+	tests := []struct {
+		name         string
+		behavior     x402test.Behavior
+		verified     string
+		settled      string
+		amount       float64
+		settleFailed bool
+	}{
+		{"settled", x402test.Behavior{}, "success", "success", 0.01, false},
+		{"invalid", x402test.Behavior{InvalidReason: "invalid_signature"}, "failure", "", 0, false},
+		{"settle failure", x402test.Behavior{SettleErrorReason: "insufficient_funds"}, "success", "failure", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics, registry := newMetrics(t)
+			f := x402test.NewFacilitator(t, x402test.WithBehavior(tt.behavior))
+			handler := x402http.NewMiddleware(&localx402.Config{
+				RecipientAddress: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+				Network:          "base-sepolia",
+				FacilitatorURL:   f.URL,
+				PricingStrategy:  fixedPrice(decimal.RequireFromString("0.01")),
+				Metrics:          metrics,
+			})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("content"))
+			}))
+
+			pay(t, handler)
+
+			if got := sumOf(t, registry, "x402_requirements_issued_total"); got != 1 {
+				t.Errorf("requirements issued = %v, want 1", got)
+			}
+			if count := testutil.CollectAndCount(registry, "x402_payments_verified_total"); count != 1 {
+				t.Fatalf("verified series = %d, want 1", count)
+			}
+			if got := labelValue(t, registry, "x402_payments_verified_total", "result"); got != tt.verified {
+				t.Errorf("verify result = %q, want %q", got, tt.verified)
+			}
+			if got := labelValue(t, registry, "x402_payments_settled_total", "result"); got != tt.settled {
+				t.Errorf("settle result = %q, want %q", got, tt.settled)
+			}
+			if tt.settleFailed {
+				if got := labelValue(t, registry, "x402_payments_settled_total", "code"); got != "INSUFFICIENT_FUNDS" {
+					t.Errorf("settle code = %q, want INSUFFICIENT_FUNDS", got)
+				}
+			}
+			if got := sumOf(t, registry, "x402_amount_settled_total"); got != tt.amount {
+				t.Errorf("amount settled = %v, want %v", got, tt.amount)
+			}
+			if testutil.CollectAndCount(registry, "x402_facilitator_request_duration_seconds") == 0 {
+				t.Error("no facilitator latency recorded")
+			}
+		})
+	}
+}
+
+func TestMetrics_BoundedLabels(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics, err := x402prom.New(registry)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	f := x402test.NewFacilitator(t)
+	handler := x402http.NewMiddleware(&localx402.Config{
+		RecipientAddress: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+		Network:          "base-sepolia",
+		FacilitatorURL:   f.URL,
+		PricingStrategy:  fixedPrice(decimal.RequireFromString("0.01")),
+		Metrics:          metrics,
+	})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("content"))
+	}))
+
+	// A payment on a network the server does not accept, to a path with an ID
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/1", nil))
+	requirement := x402test.PaymentRequirements(t, rec.Body)[0]
+	requirement.Network = "polygon-amoy"
+	req := httptest.NewRequest(http.MethodGet, "/api/2", nil)
+	req.Header.Set(localx402.HeaderPayment, x402test.PaymentHeader(t, requirement))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got := labelValue(t, registry, "x402_payments_verified_total", "network"); got != "unknown" {
+		t.Errorf("network label = %q, want unknown", got)
+	}
+	if got := labelValue(t, registry, "x402_payments_verified_total", "route"); got != http.MethodGet {
+		t.Errorf("default route label = %q, want the method", got)
+	}
+}
+
+func TestMetrics_FacilitatorRequest(t *testing.T) {
+	metrics, registry := newMetrics(t)
+
+	metrics.FacilitatorRequest("https://f.example", localx402.EndpointVerify, 50*time.Millisecond, nil)
+	metrics.FacilitatorRequest("https://f.example", localx402.EndpointVerify, time.Second, errors.New("timeout"))
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	results := map[string]uint64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "result" {
+					results[label.GetValue()] += metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	if results["success"] != 1 || results["failure"] != 1 {
+		t.Errorf("samples by result = %v, want one success and one failure", results)
+	}
+}
+
+func TestNew_DuplicateRegistration(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	if _, err := x402prom.New(registry); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := x402prom.New(registry); err == nil {
+		t.Error("expected an error registering the metrics twice")
+	}
+	if _, err := x402prom.New(registry, x402prom.WithNamespace("tenant")); err != nil {
+		t.Errorf("New with another namespace: %v", err)
+	}
+}
+
+// labelValue returns a label of the only series of a metric, or "" if there is none.
+func labelValue(t *testing.T, registry *prometheus.Registry, name, label string) string {
+	t.Helper()
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() == label {
+					return pair.GetValue()
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// sumOf returns the sum of all series of a counter.
+func sumOf(t *testing.T, registry *prometheus.Registry, name string) float64 {
+	t.Helper()
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	var sum float64
+	for _, family := range families {
+		if family.GetName() == name {
+			for _, metric := range family.GetMetric() {
+				sum += metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return sum
+}
@@ -0,0 +1,107 @@
+// Package nonce provides NonceStore implementations for x402 replay protection.
+package nonce
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+// DefaultCapacity is the default number of nonces kept by a MemoryStore.
+const DefaultCapacity = 100_000
+
+// memoryEntry is a reserved or committed nonce.
+type memoryEntry struct {
+	key       string
+	used      bool
+	expiresAt time.Time
+}
+
+// MemoryStore is an in-process NonceStore with LRU eviction and per-key TTL.
+// It only protects a single replica; use SQLStore to share state between replicas.
+//
+// When full, the least recently reserved nonce is evicted even if it has not
+// expired, so capacity should exceed the number of payments per timeout window.
+type MemoryStore struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+// NewMemoryStore creates an in-memory store holding up to capacity nonces.
+// If capacity is not positive, DefaultCapacity is used.
+func NewMemoryStore(capacity int) *MemoryStore {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &MemoryStore{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Reserve implements x402.NonceStore.
+func (s *MemoryStore) Reserve(_ context.Context, key string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if elem, ok := s.entries[key]; ok {
+		entry := elem.Value.(*memoryEntry) //nolint:errcheck // only *memoryEntry is stored
+		if now.Before(entry.expiresAt) {
+			if entry.used {
+				return x402.ErrNonceUsed
+			}
+			return x402.ErrNonceInFlight
+		}
+		s.remove(elem)
+	}
+
+	s.entries[key] = s.order.PushFront(&memoryEntry{key: key, expiresAt: now.Add(ttl)})
+	for s.order.Len() > s.capacity {
+		s.remove(s.order.Back())
+	}
+	return nil
+}
+
+// Commit implements x402.NonceStore.
+func (s *MemoryStore) Commit(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[key]; ok {
+		elem.Value.(*memoryEntry).used = true //nolint:errcheck // only *memoryEntry is stored
+	}
+	return nil
+}
+
+// Release implements x402.NonceStore.
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[key]; ok && !elem.Value.(*memoryEntry).used { //nolint:errcheck // see above
+		s.remove(elem)
+	}
+	return nil
+}
+
+// Len returns the number of nonces currently held, including expired ones not yet evicted.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// remove deletes an element from the list and index.
+func (s *MemoryStore) remove(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.entries, elem.Value.(*memoryEntry).key) //nolint:errcheck // only *memoryEntry is stored
+}
@@ -0,0 +1,159 @@
+package nonce
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+const (
+	// DefaultTable is the default table name used by SQLStore.
+	DefaultTable = "x402_nonces"
+
+	statePending = "pending"
+	stateUsed    = "used"
+)
+
+// SQLStore is a NonceStore backed by a SQL database, shared by all replicas
+// using the same table. The primary key on nonce_key makes reservations atomic.
+//
+// Schema (see CreateTable):
+//
+//	CREATE TABLE x402_nonces (
+//	    nonce_key  VARCHAR(255) PRIMARY KEY,
+//	    state      VARCHAR(16)  NOT NULL,
+//	    expires_at BIGINT       NOT NULL
+//	);
+type SQLStore struct {
+	db          *sql.DB
+	table       string
+	placeholder func(n int) string
+	now         func() time.Time
+}
+
+// SQLOption configures a SQLStore.
+type SQLOption func(*SQLStore)
+
+// WithTable sets the table name.
+func WithTable(table string) SQLOption {
+	return func(s *SQLStore) {
+		s.table = table
+	}
+}
+
+// WithDollarPlaceholders uses $1, $2, ... placeholders (PostgreSQL) instead of ?.
+func WithDollarPlaceholders() SQLOption {
+	return func(s *SQLStore) {
+		s.placeholder = func(n int) string {
+			return "$" + strconv.Itoa(n)
+		}
+	}
+}
+
+// NewSQLStore creates a store using db. The table must exist; see CreateTable.
+func NewSQLStore(db *sql.DB, opts ...SQLOption) *SQLStore {
+	s := &SQLStore{
+		db:          db,
+		table:       DefaultTable,
+		placeholder: func(int) string { return "?" },
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateTable creates the nonce table if it does not exist.
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+s.table+` (
+		nonce_key VARCHAR(255) PRIMARY KEY,
+		state VARCHAR(16) NOT NULL,
+		expires_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create nonce table: %w", err)
+	}
+	return nil
+}
+
+// Reserve implements x402.NonceStore.
+func (s *SQLStore) Reserve(ctx context.Context, key string, ttl time.Duration) error {
+	now := s.now()
+
+	// Expired entries no longer protect anything and may be reused
+	if _, err := s.db.ExecContext(ctx,
+		s.query("DELETE FROM %s WHERE nonce_key = %s AND expires_at <= %s"),
+		key, now.UnixMilli(),
+	); err != nil {
+		return fmt.Errorf("delete expired nonce: %w", err)
+	}
+
+	_, insertErr := s.db.ExecContext(ctx,
+		s.query("INSERT INTO %s (nonce_key, state, expires_at) VALUES (%s, %s, %s)"),
+		key, statePending, now.Add(ttl).UnixMilli(),
+	)
+	if insertErr == nil {
+		return nil
+	}
+
+	// The insert failed: either the key exists or the database failed
+	var state string
+	err := s.db.QueryRowContext(ctx, s.query("SELECT state FROM %s WHERE nonce_key = %s"), key).Scan(&state)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("reserve nonce: %w", insertErr)
+	case err != nil:
+		return fmt.Errorf("read nonce: %w", err)
+	case state == stateUsed:
+		return x402.ErrNonceUsed
+	default:
+		return x402.ErrNonceInFlight
+	}
+}
+
+// Commit implements x402.NonceStore.
+func (s *SQLStore) Commit(ctx context.Context, key string) error {
+	if _, err := s.db.ExecContext(ctx,
+		s.query("UPDATE %s SET state = %s WHERE nonce_key = %s"),
+		stateUsed, key,
+	); err != nil {
+		return fmt.Errorf("commit nonce: %w", err)
+	}
+	return nil
+}
+
+// Release implements x402.NonceStore.
+func (s *SQLStore) Release(ctx context.Context, key string) error {
+	if _, err := s.db.ExecContext(ctx,
+		s.query("DELETE FROM %s WHERE nonce_key = %s AND state = %s"),
+		key, statePending,
+	); err != nil {
+		return fmt.Errorf("release nonce: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes expired nonces. Call it periodically to bound table size.
+func (s *SQLStore) DeleteExpired(ctx context.Context) (int64, error) {
+	result, err := s.db.ExecContext(ctx, s.query("DELETE FROM %s WHERE expires_at <= %s"), s.now().UnixMilli())
+	if err != nil {
+		return 0, fmt.Errorf("delete expired nonces: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// query formats a statement with the table name and numbered placeholders.
+func (s *SQLStore) query(format string) string {
+	args := []any{s.table}
+	for i := 1; i < strings.Count(format, "%s"); i++ {
+		args = append(args, s.placeholder(i))
+	}
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,113 @@
+package nonce
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dexfra-fun/x402-go/internal/sqltest"
+	"github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+func newSQLiteStore(t *testing.T, c *sqltest.Clock) *SQLStore {
+	t.Helper()
+
+	store := NewSQLStore(sqltest.Open(t), WithTable("nonces"))
+	store.now = c.Now
+	if err := store.CreateTable(context.Background()); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	return store
+}
+
+func TestStores(t *testing.T) {
+	stores := map[string]func(t *testing.T, c *sqltest.Clock) x402.NonceStore{
+		"memory": func(_ *testing.T, c *sqltest.Clock) x402.NonceStore {
+			store := NewMemoryStore(0)
+			store.now = c.Now
+			return store
+		},
+		"sql": func(t *testing.T, c *sqltest.Clock) x402.NonceStore {
+			return newSQLiteStore(t, c)
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			c := sqltest.NewClock()
+			store := newStore(t, c)
+
+			expect := func(step string, err, want error) {
+				t.Helper()
+				if !errors.Is(err, want) {
+					t.Fatalf("%s: error = %v, want %v", step, err, want)
+				}
+			}
+
+			expect("reserve", store.Reserve(ctx, "a", time.Minute), nil)
+			expect("duplicate in flight", store.Reserve(ctx, "a", time.Minute), x402.ErrNonceInFlight)
+			expect("other key", store.Reserve(ctx, "b", time.Minute), nil)
+
+			expect("release", store.Release(ctx, "b"), nil)
+			expect("reserve after release", store.Reserve(ctx, "b", time.Minute), nil)
+
+			expect("commit", store.Commit(ctx, "a"), nil)
+			expect("duplicate after commit", store.Reserve(ctx, "a", time.Minute), x402.ErrNonceUsed)
+			expect("release committed", store.Release(ctx, "a"), nil)
+			expect("still used", store.Reserve(ctx, "a", time.Minute), x402.ErrNonceUsed)
+
+			c.Advance(time.Minute)
+			expect("reserve after expiry", store.Reserve(ctx, "a", time.Minute), nil)
+		})
+	}
+}
+
+func TestMemoryStore_Capacity(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(2)
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := store.Reserve(ctx, key, time.Minute); err != nil {
+			t.Fatalf("Reserve(%s) error = %v", key, err)
+		}
+	}
+
+	if store.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", store.Len())
+	}
+	if err := store.Reserve(ctx, "a", time.Minute); err != nil {
+		t.Errorf("expected oldest key to be evicted, got %v", err)
+	}
+	if err := store.Reserve(ctx, "c", time.Minute); !errors.Is(err, x402.ErrNonceInFlight) {
+		t.Errorf("expected newest key to be kept, got %v", err)
+	}
+}
+
+func TestSQLStore_DeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	c := sqltest.NewClock()
+	store := newSQLiteStore(t, c)
+
+	_ = store.Reserve(ctx, "short", time.Second)
+	_ = store.Reserve(ctx, "long", time.Hour)
+	c.Advance(time.Minute)
+
+	deleted, err := store.DeleteExpired(ctx)
+	if err != nil {
+		t.Fatalf("DeleteExpired() error = %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("DeleteExpired() = %d, want 1", deleted)
+	}
+}
+
+func TestSQLStore_Query(t *testing.T) {
+	store := NewSQLStore(nil, WithDollarPlaceholders())
+	got := store.query("UPDATE %s SET state = %s WHERE nonce_key = %s")
+	want := "UPDATE x402_nonces SET state = $1 WHERE nonce_key = $2"
+	if got != want {
+		t.Errorf("query() = %q, want %q", got, want)
+	}
+}
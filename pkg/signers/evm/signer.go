@@ -0,0 +1,218 @@
+// Package evm provides an x402 payment signer for EVM networks using
+// EIP-3009 transferWithAuthorization, plus signature recovery helpers
+// that servers can use to pre-check payments before calling a facilitator.
+package evm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/internal/evm"
+)
+
+const (
+	// validAfterSkew backdates validAfter to tolerate clock drift between client and chain.
+	validAfterSkew = 10 * time.Minute
+)
+
+// Signer signs x402 "exact" payments on EVM networks.
+type Signer struct {
+	chain     x402.ChainConfig
+	key       *evm.PrivateKey
+	address   string
+	tokens    []x402.TokenConfig
+	priority  int
+	maxAmount *big.Int
+	now       func() time.Time
+}
+
+// Option configures a Signer.
+type Option func(*Signer)
+
+// WithToken adds a token the signer can pay with.
+func WithToken(token x402.TokenConfig) Option {
+	return func(s *Signer) {
+		s.tokens = append(s.tokens, token)
+	}
+}
+
+// WithPriority sets the signer priority (lower numbers are preferred).
+func WithPriority(priority int) Option {
+	return func(s *Signer) {
+		s.priority = priority
+	}
+}
+
+// WithMaxAmount sets the per-call limit in atomic units.
+func WithMaxAmount(amount *big.Int) Option {
+	return func(s *Signer) {
+		s.maxAmount = amount
+	}
+}
+
+// WithClock sets the time source used for validAfter/validBefore (useful in tests).
+func WithClock(now func() time.Time) Option {
+	return func(s *Signer) {
+		s.now = now
+	}
+}
+
+// NewSigner creates an EVM signer for the given network and raw 32-byte private key.
+// If no token is configured, USDC for the network is used.
+func NewSigner(network string, privateKey []byte, opts ...Option) (*Signer, error) {
+	chain, ok := x402.GetChainConfig(network)
+	if !ok || !chain.IsEVM() {
+		return nil, fmt.Errorf("%w: %s", x402.ErrInvalidNetwork, network)
+	}
+
+	key, err := evm.ParsePrivateKey(privateKey)
+	if err != nil {
+		return nil, x402.ErrInvalidKey
+	}
+
+	s := &Signer{
+		chain:   chain,
+		key:     key,
+		address: evm.ChecksumAddress(key.Address()),
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if len(s.tokens) == 0 {
+		s.tokens = []x402.TokenConfig{x402.NewUSDCTokenConfig(chain, 0)}
+	}
+	for _, token := range s.tokens {
+		if _, err := evm.ParseAddress(token.Address); err != nil {
+			return nil, fmt.Errorf("%w: %w", x402.ErrInvalidToken, err)
+		}
+	}
+
+	return s, nil
+}
+
+// NewSignerFromHex creates a signer from a hex-encoded private key (with or without 0x).
+func NewSignerFromHex(network, privateKey string, opts ...Option) (*Signer, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(privateKey), "0x"))
+	if err != nil {
+		return nil, x402.ErrInvalidKey
+	}
+	return NewSigner(network, raw, opts...)
+}
+
+// Address returns the signer's checksummed address.
+func (s *Signer) Address() string {
+	return s.address
+}
+
+// SignMessage signs a message with personal_sign (EIP-191) and returns the
+// 0x-prefixed hex signature, e.g. for x402 credit authorizations.
+func (s *Signer) SignMessage(message []byte) (string, error) {
+	return "0x" + hex.EncodeToString(s.key.Sign(evm.PersonalMessageHash(message))), nil
+}
+
+// Network implements x402.Signer.
+func (s *Signer) Network() string {
+	return s.chain.NetworkID
+}
+
+// Scheme implements x402.Signer.
+func (*Signer) Scheme() string {
+	return x402.DefaultScheme
+}
+
+// GetPriority implements x402.Signer.
+func (s *Signer) GetPriority() int {
+	return s.priority
+}
+
+// GetTokens implements x402.Signer.
+func (s *Signer) GetTokens() []x402.TokenConfig {
+	return s.tokens
+}
+
+// GetMaxAmount implements x402.Signer.
+func (s *Signer) GetMaxAmount() *big.Int {
+	return s.maxAmount
+}
+
+// CanSign implements x402.Signer.
+func (s *Signer) CanSign(requirement *x402.PaymentRequirement) bool {
+	if requirement.Network != s.chain.NetworkID || requirement.Scheme != x402.DefaultScheme {
+		return false
+	}
+	for _, token := range s.tokens {
+		if strings.EqualFold(token.Address, requirement.Asset) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign implements x402.Signer.
+// It authorizes a transfer of MaxAmountRequired to PayTo that is valid for
+// MaxTimeoutSeconds and signs it with a fresh random nonce.
+func (s *Signer) Sign(_ context.Context, requirement *x402.PaymentRequirement) (*x402.PaymentPayload, error) {
+	if !s.CanSign(requirement) {
+		return nil, x402.ErrNoValidSigner
+	}
+
+	value, ok := new(big.Int).SetString(requirement.MaxAmountRequired, x402.DecimalBase)
+	if !ok || value.Sign() < 0 {
+		return nil, fmt.Errorf("%w: amount %q", x402.ErrInvalidRequirements, requirement.MaxAmountRequired)
+	}
+	if s.maxAmount != nil && value.Cmp(s.maxAmount) > 0 {
+		return nil, x402.ErrAmountExceeded
+	}
+
+	to, err := evm.ParseAddress(requirement.PayTo)
+	if err != nil {
+		return nil, fmt.Errorf("%w: payTo: %w", x402.ErrInvalidRequirements, err)
+	}
+
+	domain, err := DomainForRequirement(*requirement)
+	if err != nil {
+		return nil, err
+	}
+
+	var nonce [evm.NonceLength]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, fmt.Errorf("%w: generate nonce: %w", x402.ErrSigningFailed, err)
+	}
+
+	now := s.now()
+	auth := evm.TransferAuthorization{
+		From:        s.key.Address(),
+		To:          to,
+		Value:       value,
+		ValidAfter:  big.NewInt(now.Add(-validAfterSkew).Unix()),
+		ValidBefore: big.NewInt(now.Add(time.Duration(requirement.MaxTimeoutSeconds) * time.Second).Unix()),
+		Nonce:       nonce,
+	}
+
+	signature := s.key.Sign(evm.TypedDataHash(domain, auth))
+
+	return &x402.PaymentPayload{
+		X402Version: 1,
+		Scheme:      requirement.Scheme,
+		Network:     requirement.Network,
+		Payload: x402.EVMPayload{
+			Signature: "0x" + hex.EncodeToString(signature),
+			Authorization: x402.EVMAuthorization{
+				From:        s.address,
+				To:          evm.ChecksumAddress(to),
+				Value:       auth.Value.String(),
+				ValidAfter:  auth.ValidAfter.String(),
+				ValidBefore: auth.ValidBefore.String(),
+				Nonce:       "0x" + hex.EncodeToString(nonce[:]),
+			},
+		},
+	}, nil
+}
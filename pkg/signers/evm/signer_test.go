@@ -0,0 +1,231 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+)
+
+const (
+	testPrivateKey = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testAddress    = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+	testPayTo      = "0x209693Bc6afc0C5328bA36FaF03C514EF312287C"
+)
+
+func testRequirement() *x402.PaymentRequirement {
+	return &x402.PaymentRequirement{
+		Scheme:            "exact",
+		Network:           "base-sepolia",
+		MaxAmountRequired: "10000",
+		Asset:             x402.BaseSepolia.USDCAddress,
+		PayTo:             testPayTo,
+		MaxTimeoutSeconds: 300,
+		Extra:             map[string]any{"name": "USDC", "version": "2"},
+	}
+}
+
+func newTestSigner(t *testing.T, opts ...Option) *Signer {
+	t.Helper()
+	signer, err := NewSignerFromHex("base-sepolia", testPrivateKey, opts...)
+	if err != nil {
+		t.Fatalf("NewSignerFromHex() error = %v", err)
+	}
+	return signer
+}
+
+func TestSigner_Sign(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	signer := newTestSigner(t, WithClock(func() time.Time { return now }))
+
+	if signer.Address() != testAddress {
+		t.Fatalf("Address() = %s, want %s", signer.Address(), testAddress)
+	}
+
+	requirement := testRequirement()
+	payment, err := signer.Sign(context.Background(), requirement)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	payload, err := payment.DecodeEVMPayload()
+	if err != nil {
+		t.Fatalf("DecodeEVMPayload() error = %v", err)
+	}
+
+	auth := payload.Authorization
+	if auth.From != testAddress || auth.To != testPayTo || auth.Value != "10000" {
+		t.Errorf("unexpected authorization: %+v", auth)
+	}
+	if auth.ValidAfter != "1699999400" || auth.ValidBefore != "1700000300" {
+		t.Errorf("validity window = [%s, %s]", auth.ValidAfter, auth.ValidBefore)
+	}
+	if len(auth.Nonce) != 66 || !strings.HasPrefix(auth.Nonce, "0x") {
+		t.Errorf("nonce = %q, want 32 bytes hex", auth.Nonce)
+	}
+	if len(payload.Signature) != 132 {
+		t.Errorf("signature length = %d, want 132", len(payload.Signature))
+	}
+
+	payer, err := VerifyPayment(*payment, *requirement)
+	if err != nil {
+		t.Fatalf("VerifyPayment() error = %v", err)
+	}
+	if payer != testAddress {
+		t.Errorf("VerifyPayment() = %s, want %s", payer, testAddress)
+	}
+}
+
+func TestSigner_SignUniqueNonces(t *testing.T) {
+	signer := newTestSigner(t)
+
+	seen := make(map[string]bool)
+	for range 5 {
+		payment, err := signer.Sign(context.Background(), testRequirement())
+		if err != nil {
+			t.Fatalf("Sign() error = %v", err)
+		}
+		payload, _ := payment.DecodeEVMPayload()
+		if seen[payload.Authorization.Nonce] {
+			t.Fatalf("nonce reused: %s", payload.Authorization.Nonce)
+		}
+		seen[payload.Authorization.Nonce] = true
+	}
+}
+
+func TestSigner_CanSign(t *testing.T) {
+	signer := newTestSigner(t)
+
+	tests := []struct {
+		name   string
+		modify func(*x402.PaymentRequirement)
+		want   bool
+	}{
+		{"matching requirement", func(*x402.PaymentRequirement) {}, true},
+		{"lowercase asset", func(r *x402.PaymentRequirement) { r.Asset = strings.ToLower(r.Asset) }, true},
+		{"other network", func(r *x402.PaymentRequirement) { r.Network = "base" }, false},
+		{"other scheme", func(r *x402.PaymentRequirement) { r.Scheme = "upto" }, false},
+		{"other asset", func(r *x402.PaymentRequirement) { r.Asset = x402.BaseMainnet.USDCAddress }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requirement := testRequirement()
+			tt.modify(requirement)
+			if got := signer.CanSign(requirement); got != tt.want {
+				t.Errorf("CanSign() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSigner_SignMaxAmount(t *testing.T) {
+	signer := newTestSigner(t, WithMaxAmount(big.NewInt(9999)))
+
+	_, err := signer.Sign(context.Background(), testRequirement())
+	if !errors.Is(err, x402.ErrAmountExceeded) {
+		t.Errorf("Sign() error = %v, want ErrAmountExceeded", err)
+	}
+}
+
+func TestNewSigner_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		network string
+		key     string
+		wantErr error
+	}{
+		{"solana network", "solana", testPrivateKey, x402.ErrInvalidNetwork},
+		{"unknown network", "ethereum-classic", testPrivateKey, x402.ErrInvalidNetwork},
+		{"bad hex", "base", "0xzz", x402.ErrInvalidKey},
+		{"short key", "base", "0x0102", x402.ErrInvalidKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSignerFromHex(tt.network, tt.key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewSignerFromHex() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyPayment_Tampered(t *testing.T) {
+	signer := newTestSigner(t)
+	requirement := testRequirement()
+
+	tests := []struct {
+		name   string
+		modify func(*x402.EVMPayload, *x402.PaymentRequirement)
+	}{
+		{"value changed", func(p *x402.EVMPayload, _ *x402.PaymentRequirement) {
+			p.Authorization.Value = "20000"
+		}},
+		{"from changed", func(p *x402.EVMPayload, _ *x402.PaymentRequirement) {
+			p.Authorization.From = testPayTo
+		}},
+		{"other domain version", func(_ *x402.EVMPayload, r *x402.PaymentRequirement) {
+			r.Extra = map[string]any{"name": "USDC", "version": "1"}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment, err := signer.Sign(context.Background(), requirement)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			payload, _ := payment.DecodeEVMPayload()
+			req := *requirement
+			tt.modify(payload, &req)
+			payment.Payload = *payload
+
+			if _, err := VerifyPayment(*payment, req); err == nil {
+				t.Error("VerifyPayment() succeeded for tampered payment")
+			}
+		})
+	}
+}
+
+func TestVerifyPayment_OversizedValue(t *testing.T) {
+	signer := newTestSigner(t)
+	requirement := testRequirement()
+
+	payment, err := signer.Sign(context.Background(), requirement)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	payload, _ := payment.DecodeEVMPayload()
+	payload.Authorization.Value = "1" + strings.Repeat("0", 90)
+	payment.Payload = *payload
+
+	if _, err := VerifyPayment(*payment, *requirement); !errors.Is(err, x402.ErrMalformedHeader) {
+		t.Errorf("VerifyPayment() error = %v, want ErrMalformedHeader", err)
+	}
+	if _, err := ParseAuthorization(payload.Authorization); err == nil {
+		t.Error("ParseAuthorization() accepted a value wider than 256 bits")
+	}
+}
+
+func TestDomainForRequirement_ChainFallback(t *testing.T) {
+	requirement := testRequirement()
+	requirement.Extra = nil
+
+	domain, err := DomainForRequirement(*requirement)
+	if err != nil {
+		t.Fatalf("DomainForRequirement() error = %v", err)
+	}
+	if domain.Name != x402.BaseSepolia.EIP3009Name || domain.ChainID != x402.BaseSepolia.ChainID {
+		t.Errorf("unexpected domain: %+v", domain)
+	}
+
+	requirement.Asset = testPayTo
+	if _, err := DomainForRequirement(*requirement); !errors.Is(err, ErrMissingDomain) {
+		t.Errorf("DomainForRequirement() error = %v, want ErrMissingDomain", err)
+	}
+}
@@ -0,0 +1,136 @@
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/internal/evm"
+)
+
+var (
+	// ErrSignerMismatch indicates the recovered signer differs from authorization.from.
+	ErrSignerMismatch = errors.New("evm: signature does not match authorization.from")
+
+	// ErrMissingDomain indicates the EIP-712 domain name/version could not be determined.
+	ErrMissingDomain = errors.New("evm: missing EIP-712 domain name or version")
+)
+
+// DomainForRequirement builds the EIP-712 domain for a requirement.
+// Name and version come from extra.name/extra.version, falling back to the
+// chain's USDC parameters when the asset is the chain's USDC contract.
+func DomainForRequirement(requirement x402.PaymentRequirement) (evm.Domain, error) {
+	chain, ok := x402.GetChainConfig(requirement.Network)
+	if !ok || !chain.IsEVM() {
+		return evm.Domain{}, fmt.Errorf("%w: %s", x402.ErrInvalidNetwork, requirement.Network)
+	}
+
+	contract, err := evm.ParseAddress(requirement.Asset)
+	if err != nil {
+		return evm.Domain{}, fmt.Errorf("%w: asset: %w", x402.ErrInvalidRequirements, err)
+	}
+
+	name, _ := requirement.Extra["name"].(string)
+	version, _ := requirement.Extra["version"].(string)
+	if (name == "" || version == "") && strings.EqualFold(requirement.Asset, chain.USDCAddress) {
+		name, version = chain.EIP3009Name, chain.EIP3009Version
+	}
+	if name == "" || version == "" {
+		return evm.Domain{}, ErrMissingDomain
+	}
+
+	return evm.Domain{
+		Name:              name,
+		Version:           version,
+		ChainID:           chain.ChainID,
+		VerifyingContract: contract,
+	}, nil
+}
+
+// ParseAuthorization converts the wire authorization into typed EIP-3009 parameters.
+func ParseAuthorization(auth x402.EVMAuthorization) (evm.TransferAuthorization, error) {
+	var parsed evm.TransferAuthorization
+
+	from, err := evm.ParseAddress(auth.From)
+	if err != nil {
+		return parsed, fmt.Errorf("authorization.from: %w", err)
+	}
+	to, err := evm.ParseAddress(auth.To)
+	if err != nil {
+		return parsed, fmt.Errorf("authorization.to: %w", err)
+	}
+
+	values := make([]*big.Int, 0, 3)
+	for _, field := range []struct{ name, value string }{
+		{"value", auth.Value},
+		{"validAfter", auth.ValidAfter},
+		{"validBefore", auth.ValidBefore},
+	} {
+		v, ok := new(big.Int).SetString(field.value, x402.DecimalBase)
+		if !ok || v.Sign() < 0 || v.BitLen() > evm.Uint256Bits {
+			return parsed, fmt.Errorf("authorization.%s: invalid integer %q", field.name, field.value)
+		}
+		values = append(values, v)
+	}
+
+	nonce, err := evm.DecodeHex(auth.Nonce)
+	if err != nil || len(nonce) != evm.NonceLength {
+		return parsed, fmt.Errorf("authorization.nonce: expected %d bytes hex", evm.NonceLength)
+	}
+
+	parsed = evm.TransferAuthorization{
+		From:        from,
+		To:          to,
+		Value:       values[0],
+		ValidAfter:  values[1],
+		ValidBefore: values[2],
+	}
+	copy(parsed.Nonce[:], nonce)
+	return parsed, nil
+}
+
+// RecoverSigner recovers the checksummed address that signed the payload's
+// authorization under the EIP-712 domain of the requirement.
+func RecoverSigner(payload x402.EVMPayload, requirement x402.PaymentRequirement) (string, error) {
+	domain, err := DomainForRequirement(requirement)
+	if err != nil {
+		return "", err
+	}
+
+	auth, err := ParseAuthorization(payload.Authorization)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", x402.ErrMalformedHeader, err)
+	}
+
+	signature, err := evm.DecodeHex(payload.Signature)
+	if err != nil {
+		return "", fmt.Errorf("%w: signature: %w", x402.ErrMalformedHeader, err)
+	}
+
+	signer, err := evm.Recover(evm.TypedDataHash(domain, auth), signature)
+	if err != nil {
+		return "", err
+	}
+	return evm.ChecksumAddress(signer), nil
+}
+
+// VerifyPayment checks that an EVM payment's signature was produced by
+// authorization.from for the requirement's domain. It returns the payer address.
+// This is a local pre-check; balances and nonce usage are verified on-chain.
+func VerifyPayment(payment x402.PaymentPayload, requirement x402.PaymentRequirement) (string, error) {
+	payload, err := payment.DecodeEVMPayload()
+	if err != nil {
+		return "", err
+	}
+
+	signer, err := RecoverSigner(*payload, requirement)
+	if err != nil {
+		return "", err
+	}
+	if !strings.EqualFold(signer, payload.Authorization.From) {
+		return "", ErrSignerMismatch
+	}
+	return signer, nil
+}
@@ -0,0 +1,375 @@
+// Package svm provides an x402 payment signer for Solana networks.
+// It builds a partially signed SPL TransferChecked transaction where the
+// facilitator's fee payer signature is added during settlement.
+package svm
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/internal/svm"
+	"github.com/mr-tron/base58"
+)
+
+const (
+	// DefaultComputeUnitLimit is the compute unit limit set on payment transactions.
+	DefaultComputeUnitLimit = 20_000
+
+	// DefaultComputeUnitPrice is the compute unit price in micro-lamports.
+	DefaultComputeUnitPrice = 1
+
+	// MainnetRPCURL is the public Solana mainnet RPC endpoint.
+	MainnetRPCURL = "https://api.mainnet-beta.solana.com"
+
+	// DevnetRPCURL is the public Solana devnet RPC endpoint.
+	DevnetRPCURL = "https://api.devnet.solana.com"
+)
+
+// BlockhashProvider supplies a recent blockhash for new transactions.
+type BlockhashProvider interface {
+	// LatestBlockhash returns a recent blockhash encoded in base58.
+	LatestBlockhash(ctx context.Context) (string, error)
+}
+
+// BlockhashFunc adapts a function to the BlockhashProvider interface.
+type BlockhashFunc func(ctx context.Context) (string, error)
+
+// LatestBlockhash calls f(ctx).
+func (f BlockhashFunc) LatestBlockhash(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// rpcBlockhashProvider fetches blockhashes via JSON-RPC.
+type rpcBlockhashProvider struct {
+	client *svm.RPCClient
+}
+
+// NewRPCBlockhashProvider creates a BlockhashProvider backed by a Solana RPC endpoint.
+func NewRPCBlockhashProvider(rpcURL string) BlockhashProvider {
+	return &rpcBlockhashProvider{client: svm.NewRPCClient(rpcURL, nil)}
+}
+
+// LatestBlockhash implements BlockhashProvider.
+func (p *rpcBlockhashProvider) LatestBlockhash(ctx context.Context) (string, error) {
+	return p.client.GetLatestBlockhash(ctx)
+}
+
+// Signer signs x402 "exact" payments on Solana.
+type Signer struct {
+	network          string
+	privateKey       ed25519.PrivateKey
+	owner            svm.PublicKey
+	tokens           []x402.TokenConfig
+	priority         int
+	maxAmount        *big.Int
+	blockhashes      BlockhashProvider
+	tokenProgram     svm.PublicKey
+	computeUnitLimit uint32
+	computeUnitPrice uint64
+
+	// optionErr is the first invalid option value, reported by NewSigner.
+	optionErr error
+}
+
+// Option configures a Signer.
+type Option func(*Signer)
+
+// WithToken adds a token the signer can pay with.
+func WithToken(token x402.TokenConfig) Option {
+	return func(s *Signer) {
+		s.tokens = append(s.tokens, token)
+	}
+}
+
+// WithPriority sets the signer priority (lower numbers are preferred).
+func WithPriority(priority int) Option {
+	return func(s *Signer) {
+		s.priority = priority
+	}
+}
+
+// WithMaxAmount sets the per-call limit in atomic units.
+func WithMaxAmount(amount *big.Int) Option {
+	return func(s *Signer) {
+		s.maxAmount = amount
+	}
+}
+
+// WithBlockhashProvider sets the source of recent blockhashes.
+func WithBlockhashProvider(provider BlockhashProvider) Option {
+	return func(s *Signer) {
+		s.blockhashes = provider
+	}
+}
+
+// WithTokenProgram sets the token program (defaults to SPL Token).
+func WithTokenProgram(programID string) Option {
+	return func(s *Signer) {
+		key, err := svm.ParsePublicKey(programID)
+		if err != nil {
+			if s.optionErr == nil {
+				s.optionErr = fmt.Errorf("%w: token program: %w", x402.ErrInvalidToken, err)
+			}
+			return
+		}
+		s.tokenProgram = key
+	}
+}
+
+// WithComputeUnitLimit sets the compute unit limit instruction value.
+func WithComputeUnitLimit(units uint32) Option {
+	return func(s *Signer) {
+		s.computeUnitLimit = units
+	}
+}
+
+// WithComputeUnitPrice sets the compute unit price in micro-lamports.
+func WithComputeUnitPrice(microLamports uint64) Option {
+	return func(s *Signer) {
+		s.computeUnitPrice = microLamports
+	}
+}
+
+// NewSigner creates a Solana signer for the given network and key.
+// If no token is configured, USDC for the network is used. If no blockhash
+// provider is configured, the public RPC endpoint for the network is used.
+func NewSigner(network string, privateKey ed25519.PrivateKey, opts ...Option) (*Signer, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, x402.ErrInvalidKey
+	}
+
+	chain, rpcURL, err := chainForNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Signer{
+		network:          chain.NetworkID,
+		privateKey:       privateKey,
+		tokenProgram:     svm.TokenProgramID,
+		computeUnitLimit: DefaultComputeUnitLimit,
+		computeUnitPrice: DefaultComputeUnitPrice,
+	}
+	copy(s.owner[:], privateKey.Public().(ed25519.PublicKey))
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.optionErr != nil {
+		return nil, s.optionErr
+	}
+
+	if len(s.tokens) == 0 {
+		s.tokens = []x402.TokenConfig{x402.NewUSDCTokenConfig(chain, 0)}
+	}
+	for _, token := range s.tokens {
+		if _, err := svm.ParsePublicKey(token.Address); err != nil {
+			return nil, fmt.Errorf("%w: %w", x402.ErrInvalidToken, err)
+		}
+	}
+	if s.blockhashes == nil {
+		s.blockhashes = NewRPCBlockhashProvider(rpcURL)
+	}
+
+	return s, nil
+}
+
+// NewSignerFromBase58 creates a signer from a base58-encoded 64-byte keypair,
+// the format exported by most Solana wallets.
+func NewSignerFromBase58(network, secret string, opts ...Option) (*Signer, error) {
+	raw, err := base58.Decode(strings.TrimSpace(secret))
+	if err != nil || len(raw) != ed25519.PrivateKeySize {
+		return nil, x402.ErrInvalidKey
+	}
+	return NewSigner(network, ed25519.PrivateKey(raw), opts...)
+}
+
+// chainForNetwork returns the chain configuration and default RPC URL for a network.
+func chainForNetwork(network string) (x402.ChainConfig, string, error) {
+	switch strings.ToLower(strings.TrimSpace(network)) {
+	case "solana", "solana-mainnet":
+		return x402.SolanaMainnet, MainnetRPCURL, nil
+	case "solana-devnet":
+		return x402.SolanaDevnet, DevnetRPCURL, nil
+	default:
+		return x402.ChainConfig{}, "", fmt.Errorf("%w: %s", x402.ErrInvalidNetwork, network)
+	}
+}
+
+// Address returns the signer's base58 public key.
+func (s *Signer) Address() string {
+	return s.owner.String()
+}
+
+// SignMessage signs a message with the signer's ed25519 key and returns the
+// base58 signature, e.g. for x402 credit authorizations.
+func (s *Signer) SignMessage(message []byte) (string, error) {
+	return base58.Encode(ed25519.Sign(s.privateKey, message)), nil
+}
+
+// Network implements x402.Signer.
+func (s *Signer) Network() string {
+	return s.network
+}
+
+// Scheme implements x402.Signer.
+func (*Signer) Scheme() string {
+	return x402.DefaultScheme
+}
+
+// GetPriority implements x402.Signer.
+func (s *Signer) GetPriority() int {
+	return s.priority
+}
+
+// GetTokens implements x402.Signer.
+func (s *Signer) GetTokens() []x402.TokenConfig {
+	return s.tokens
+}
+
+// GetMaxAmount implements x402.Signer.
+func (s *Signer) GetMaxAmount() *big.Int {
+	return s.maxAmount
+}
+
+// CanSign implements x402.Signer.
+func (s *Signer) CanSign(requirement *x402.PaymentRequirement) bool {
+	if requirement.Network != s.network || requirement.Scheme != x402.DefaultScheme {
+		return false
+	}
+	if feePayer(requirement) == "" {
+		return false
+	}
+	_, ok := s.token(requirement.Asset)
+	return ok
+}
+
+// Sign implements x402.Signer.
+func (s *Signer) Sign(ctx context.Context, requirement *x402.PaymentRequirement) (*x402.PaymentPayload, error) {
+	if !s.CanSign(requirement) {
+		return nil, x402.ErrNoValidSigner
+	}
+
+	tx, err := s.buildTransaction(ctx, requirement)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Sign(s.privateKey); err != nil {
+		return nil, fmt.Errorf("%w: %w", x402.ErrSigningFailed, err)
+	}
+
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", x402.ErrSigningFailed, err)
+	}
+
+	return &x402.PaymentPayload{
+		X402Version: 1,
+		Scheme:      requirement.Scheme,
+		Network:     requirement.Network,
+		Payload: x402.SVMPayload{
+			Transaction: base64.StdEncoding.EncodeToString(raw),
+		},
+	}, nil
+}
+
+// buildTransaction creates the unsigned transfer transaction for a requirement.
+func (s *Signer) buildTransaction(
+	ctx context.Context,
+	requirement *x402.PaymentRequirement,
+) (*svm.Transaction, error) {
+	token, _ := s.token(requirement.Asset)
+
+	amount, err := strconv.ParseUint(requirement.MaxAmountRequired, x402.DecimalBase, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: amount %q", x402.ErrInvalidRequirements, requirement.MaxAmountRequired)
+	}
+	if s.maxAmount != nil && new(big.Int).SetUint64(amount).Cmp(s.maxAmount) > 0 {
+		return nil, x402.ErrAmountExceeded
+	}
+
+	mint, err := svm.ParsePublicKey(requirement.Asset)
+	if err != nil {
+		return nil, fmt.Errorf("%w: asset: %w", x402.ErrInvalidRequirements, err)
+	}
+	payTo, err := svm.ParsePublicKey(requirement.PayTo)
+	if err != nil {
+		return nil, fmt.Errorf("%w: payTo: %w", x402.ErrInvalidRequirements, err)
+	}
+	payer, err := svm.ParsePublicKey(feePayer(requirement))
+	if err != nil {
+		return nil, fmt.Errorf("%w: feePayer: %w", x402.ErrInvalidRequirements, err)
+	}
+
+	source, err := svm.FindAssociatedTokenAddress(s.owner, mint, s.tokenProgram)
+	if err != nil {
+		return nil, fmt.Errorf("derive source account: %w", err)
+	}
+	destination, err := svm.FindAssociatedTokenAddress(payTo, mint, s.tokenProgram)
+	if err != nil {
+		return nil, fmt.Errorf("derive destination account: %w", err)
+	}
+
+	blockhash, err := s.recentBlockhash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	instructions := []svm.Instruction{
+		svm.NewSetComputeUnitLimitInstruction(s.computeUnitLimit),
+		svm.NewSetComputeUnitPriceInstruction(s.computeUnitPrice),
+		svm.NewTransferCheckedInstruction(svm.TransferCheckedParams{
+			TokenProgram: s.tokenProgram,
+			Source:       source,
+			Mint:         mint,
+			Destination:  destination,
+			Owner:        s.owner,
+			Amount:       amount,
+			Decimals:     uint8(token.Decimals), //nolint:gosec // token decimals are small
+		}),
+	}
+
+	return svm.NewTransaction(instructions, payer, blockhash)
+}
+
+// recentBlockhash fetches and decodes a blockhash from the provider.
+func (s *Signer) recentBlockhash(ctx context.Context) ([svm.HashLength]byte, error) {
+	var hash [svm.HashLength]byte
+
+	encoded, err := s.blockhashes.LatestBlockhash(ctx)
+	if err != nil {
+		return hash, fmt.Errorf("%w: get blockhash: %w", x402.ErrNetworkError, err)
+	}
+	raw, err := base58.Decode(encoded)
+	if err != nil || len(raw) != svm.HashLength {
+		return hash, fmt.Errorf("%w: invalid blockhash %q", x402.ErrNetworkError, encoded)
+	}
+	copy(hash[:], raw)
+	return hash, nil
+}
+
+// token finds the configured token for an asset address.
+func (s *Signer) token(asset string) (x402.TokenConfig, bool) {
+	for _, token := range s.tokens {
+		if token.Address == asset {
+			return token, true
+		}
+	}
+	return x402.TokenConfig{}, false
+}
+
+// feePayer extracts extra.feePayer from a requirement.
+func feePayer(requirement *x402.PaymentRequirement) string {
+	if requirement.Extra == nil {
+		return ""
+	}
+	feePayer, _ := requirement.Extra["feePayer"].(string)
+	return strings.TrimSpace(feePayer)
+}
@@ -0,0 +1,149 @@
+package svm
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"testing"
+
+	x402 "github.com/dexfra-fun/x402-go"
+)
+
+const (
+	testBlockhash = "EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N"
+	testFeePayer  = "L54zkaPQFeTn1UsEqieEXBqWrPShiaZEPD7mS5WXfQg"
+	testPayTo     = "2wKupLR9q6wXYppw8Gr2NvWxKBUqm4PPJKkQfoxHDBg4"
+
+	// expectedMessage was produced by github.com/gagliardetto/solana-go for the same inputs.
+	expectedMessage = "AgEDBwTiotZSvCx1Mah7TzGmH51VaoW9BcfKBTGHpWrVnkL1ebVWLo/mVPlAeLES6KmLp5AfhTrmlb7X4OORC60ElmTa" +
+		"00lcjxcJ4BjhwUamMLX4DpWt5anSBkya9lniH/FrUSWLlA4+57RmexXQWtiqPwBr9AOJHNQ5WGVDs8iZ9g8Axvp6877brTo9Zf" +
+		"Nqq8l0MbG75MLS9uDkfKYCA0UvXWEDBkZv5SEXMv/srbpyw5vnvIzlu8X3EmssQ5s6QAAAAAbd9uHXZaGT2cvhRs7reawctIXt" +
+		"X1s3kTqM9YV+/wCpzEkOkozS44c7s0P8ldozF5ymD02/RsLDbpEpnVXU5rkDBQAFAiBOAAAFAAkDAQAAAAAAAAAGBAIEAwEK" +
+		"DOgDAAAAAAAABg=="
+)
+
+func testKey() ed25519.PrivateKey {
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i + 1)
+	}
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+func fakeBlockhash(_ context.Context) (string, error) {
+	return testBlockhash, nil
+}
+
+func testRequirement() *x402.PaymentRequirement {
+	return &x402.PaymentRequirement{
+		Scheme:            "exact",
+		Network:           "solana",
+		MaxAmountRequired: "1000",
+		Asset:             x402.SolanaMainnet.USDCAddress,
+		PayTo:             testPayTo,
+		MaxTimeoutSeconds: 60,
+		Extra:             map[string]any{"feePayer": testFeePayer},
+	}
+}
+
+func TestSigner_Sign(t *testing.T) {
+	key := testKey()
+	signer, err := NewSigner("solana", key, WithBlockhashProvider(BlockhashFunc(fakeBlockhash)))
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+
+	payload, err := signer.Sign(context.Background(), testRequirement())
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	svmPayload, ok := payload.Payload.(x402.SVMPayload)
+	if !ok {
+		t.Fatalf("expected SVMPayload, got %T", payload.Payload)
+	}
+	raw, err := base64.StdEncoding.DecodeString(svmPayload.Transaction)
+	if err != nil {
+		t.Fatalf("decode transaction: %v", err)
+	}
+
+	// Layout: signature count (1 byte), two signatures, message
+	const sigLen = ed25519.SignatureSize
+	if raw[0] != 2 {
+		t.Fatalf("expected 2 signatures, got %d", raw[0])
+	}
+	feePayerSig := raw[1 : 1+sigLen]
+	ownerSig := raw[1+sigLen : 1+2*sigLen]
+	message := raw[1+2*sigLen:]
+
+	if got := base64.StdEncoding.EncodeToString(message); got != expectedMessage {
+		t.Errorf("message mismatch:\n got  %s\n want %s", got, expectedMessage)
+	}
+	for _, b := range feePayerSig {
+		if b != 0 {
+			t.Fatal("expected fee payer signature slot to be empty")
+		}
+	}
+	if !ed25519.Verify(key.Public().(ed25519.PublicKey), message, ownerSig) {
+		t.Error("owner signature does not verify")
+	}
+}
+
+func TestSigner_CanSign(t *testing.T) {
+	signer, err := NewSigner("solana", testKey(), WithBlockhashProvider(BlockhashFunc(fakeBlockhash)))
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*x402.PaymentRequirement)
+		want   bool
+	}{
+		{"matching requirement", func(*x402.PaymentRequirement) {}, true},
+		{"other network", func(r *x402.PaymentRequirement) { r.Network = "solana-devnet" }, false},
+		{"other asset", func(r *x402.PaymentRequirement) { r.Asset = x402.SolanaDevnet.USDCAddress }, false},
+		{"missing fee payer", func(r *x402.PaymentRequirement) { r.Extra = nil }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testRequirement()
+			tt.modify(req)
+			if got := signer.CanSign(req); got != tt.want {
+				t.Errorf("CanSign() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSigner_MaxAmount(t *testing.T) {
+	signer, err := NewSigner("solana", testKey(),
+		WithBlockhashProvider(BlockhashFunc(fakeBlockhash)),
+		WithMaxAmount(big.NewInt(999)),
+	)
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+
+	if _, err := signer.Sign(context.Background(), testRequirement()); !errors.Is(err, x402.ErrAmountExceeded) {
+		t.Errorf("expected ErrAmountExceeded, got %v", err)
+	}
+}
+
+func TestNewSigner_Errors(t *testing.T) {
+	if _, err := NewSigner("solana", ed25519.PrivateKey{1, 2, 3}); !errors.Is(err, x402.ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+	if _, err := NewSigner("base", testKey()); !errors.Is(err, x402.ErrInvalidNetwork) {
+		t.Errorf("expected ErrInvalidNetwork, got %v", err)
+	}
+	if _, err := NewSignerFromBase58("solana", "invalid"); !errors.Is(err, x402.ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+	if _, err := NewSigner("solana", testKey(), WithTokenProgram("not-a-key")); !errors.Is(err, x402.ErrInvalidToken) {
+		t.Errorf("expected ErrInvalidToken for an invalid token program, got %v", err)
+	}
+}
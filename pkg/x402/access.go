@@ -0,0 +1,195 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// defaultAccessTokenTTL is the default lifetime of an access token.
+	defaultAccessTokenTTL = time.Hour
+
+	// minAccessTokenSecret is the minimum length of an access token secret (HS256).
+	minAccessTokenSecret = 32
+
+	// accessTokenIssuer is the issuer of access tokens.
+	accessTokenIssuer = "x402"
+)
+
+// AccessTokenConfig enables pay-once access tokens: a successful settlement
+// returns a signed JWT in the X-Payment-Token header, and requests presenting
+// it in the same header skip payment while the token lasts. A token is valid
+// for the HTTP method it was paid with and the paths in its scope. Payments
+// to credit top-up routes issue no token.
+//
+// Without a Store the remaining calls are a signed counter in the token, and
+// each use returns a token with one call less. This needs no shared state,
+// but a client replaying an older token regains its calls; only the TTL is
+// enforced strictly. Set Store to count calls across replicas.
+type AccessTokenConfig struct {
+	// Secret signs tokens with HMAC-SHA256. It must be at least 32 bytes and
+	// shared by all replicas.
+	Secret []byte
+	// TTL is how long a token is valid. Defaults to one hour.
+	TTL time.Duration
+	// Calls is the number of requests a token pays for after the paid one.
+	// Zero allows any number of requests within the TTL.
+	Calls int
+	// Scope returns the path pattern (path.Match syntax, e.g. "/api/*") a token
+	// issued for a resource is valid for. Defaults to the paid path.
+	Scope func(resource Resource) string
+	// Store counts token uses (optional).
+	Store AccessTokenStore
+}
+
+// AccessTokenStore counts the calls of access tokens, e.g. in a database
+// shared by all replicas. pkg/accesstoken provides implementations.
+type AccessTokenStore interface {
+	// Issue registers a token allowing calls requests until expiresAt.
+	Issue(ctx context.Context, id string, calls int, expiresAt time.Time) error
+	// Consume uses one call of a token and returns the calls left. It returns
+	// ErrAccessTokenExhausted if the token is unknown, expired or used up.
+	Consume(ctx context.Context, id string) (int, error)
+}
+
+// AccessClaims are the claims of an access token. The subject is the payer.
+type AccessClaims struct {
+	jwt.RegisteredClaims
+
+	// Method is the HTTP method the token is valid for.
+	Method string `json:"method"`
+	// Scope is the path pattern the token is valid for.
+	Scope string `json:"scope"`
+	// Calls is the number of calls left if counted in the token.
+	Calls int `json:"calls"`
+	// Network is the network the token was paid on.
+	Network string `json:"network,omitempty"`
+}
+
+// validate checks the access token configuration and sets defaults.
+func (c *AccessTokenConfig) validate() error {
+	if len(c.Secret) < minAccessTokenSecret {
+		return fmt.Errorf("%w: secret must be at least %d bytes", ErrInvalidAccessTokenConfig, minAccessTokenSecret)
+	}
+	if c.Calls < 0 {
+		return fmt.Errorf("%w: calls must not be negative", ErrInvalidAccessTokenConfig)
+	}
+	if c.TTL <= 0 {
+		c.TTL = defaultAccessTokenTTL
+	}
+	if c.Scope == nil {
+		c.Scope = func(resource Resource) string { return resource.Path }
+	}
+	return nil
+}
+
+// counted reports whether the calls of a token are limited.
+func (c *AccessTokenConfig) counted() bool {
+	return c.Calls > 0
+}
+
+// IssueAccessToken issues an access token to the payer of a settled payment
+// for resource, or returns "" if access tokens are disabled or resource tops
+// up credits.
+func (m *Middleware) IssueAccessToken(ctx context.Context, resource Resource, payer, network string) (string, error) {
+	config := m.config.AccessTokens
+	if config == nil || m.IsTopUp(resource) {
+		return "", nil
+	}
+
+	now := m.now()
+	claims := AccessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        randomID(),
+			Issuer:    accessTokenIssuer,
+			Subject:   payer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(config.TTL)),
+		},
+		Method:  strings.ToUpper(resource.Method),
+		Scope:   config.Scope(resource),
+		Network: network,
+	}
+	if config.counted() {
+		claims.Calls = config.Calls
+		if config.Store != nil {
+			if err := config.Store.Issue(ctx, claims.ID, config.Calls, claims.ExpiresAt.Time); err != nil {
+				return "", fmt.Errorf("issue access token: %w", err)
+			}
+		}
+	}
+	return m.signAccessToken(claims)
+}
+
+// RedeemAccessToken checks an access token presented for resource and uses
+// one of its calls. It returns the token's claims and, if the calls are
+// counted in the token, the token to use next. An invalid, expired or
+// out-of-scope token, or one presented for another method or a top-up route,
+// returns ErrInvalidAccessToken; a used up one ErrAccessTokenExhausted.
+func (m *Middleware) RedeemAccessToken(ctx context.Context, resource Resource, token string) (*AccessClaims, string, error) {
+	config := m.config.AccessTokens
+	if config == nil || m.IsTopUp(resource) {
+		return nil, "", ErrInvalidAccessToken
+	}
+
+	var claims AccessClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return config.Secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(accessTokenIssuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(m.now),
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrInvalidAccessToken, err)
+	}
+	if !strings.EqualFold(claims.Method, resource.Method) {
+		return nil, "", fmt.Errorf("%w: token was paid for %s, not %s", ErrInvalidAccessToken, claims.Method, resource.Method)
+	}
+	if matched, _ := path.Match(claims.Scope, resource.Path); !matched {
+		return nil, "", fmt.Errorf("%w: %s is outside the token's scope %s", ErrInvalidAccessToken, resource.Path, claims.Scope)
+	}
+
+	switch {
+	case !config.counted():
+		return &claims, "", nil
+	case config.Store != nil:
+		remaining, err := config.Store.Consume(ctx, claims.ID)
+		if err != nil {
+			return nil, "", err
+		}
+		claims.Calls = remaining
+		return &claims, "", nil
+	case claims.Calls <= 0:
+		return nil, "", ErrAccessTokenExhausted
+	}
+
+	claims.Calls--
+	next, err := m.signAccessToken(claims)
+	if err != nil {
+		return nil, "", err
+	}
+	return &claims, next, nil
+}
+
+// signAccessToken signs claims with the configured secret.
+func (m *Middleware) signAccessToken(claims AccessClaims) (string, error) {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.config.AccessTokens.Secret)
+	if err != nil {
+		return "", fmt.Errorf("sign access token: %w", err)
+	}
+	return token, nil
+}
+
+// IsAccessTokenError reports whether err rejects an access token, as opposed
+// to a failure of the AccessTokenStore.
+func IsAccessTokenError(err error) bool {
+	return errors.Is(err, ErrInvalidAccessToken) || errors.Is(err, ErrAccessTokenExhausted)
+}
@@ -0,0 +1,176 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var testAccessSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// countingStore is an AccessTokenStore keeping counts in a map.
+type countingStore map[string]int
+
+func (s countingStore) Issue(_ context.Context, id string, calls int, _ time.Time) error {
+	s[id] = calls
+	return nil
+}
+
+func (s countingStore) Consume(_ context.Context, id string) (int, error) {
+	if s[id] <= 0 {
+		return 0, ErrAccessTokenExhausted
+	}
+	s[id]--
+	return s[id], nil
+}
+
+func newAccessMiddleware(t *testing.T, config AccessTokenConfig) (*Middleware, *fakeClock) {
+	t.Helper()
+
+	m, err := New(&Config{
+		RecipientAddress: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+		Network:          "base-sepolia",
+		FacilitatorURL:   "http://127.0.0.1:0",
+		PricingStrategy:  fixedPrice(decimal.RequireFromString("0.01")),
+		AccessTokens:     &config,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(m.Close)
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	m.now = clock.Now
+	return m, clock
+}
+
+func TestAccessTokens_StatelessCounter(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newAccessMiddleware(t, AccessTokenConfig{Secret: testAccessSecret, Calls: 2})
+	resource := Resource{Path: "/api/1"}
+
+	token, err := m.IssueAccessToken(ctx, resource, "0xpayer", "base-sepolia")
+	if err != nil || token == "" {
+		t.Fatalf("IssueAccessToken() = %q, %v", token, err)
+	}
+
+	for _, want := range []int{1, 0} {
+		claims, next, err := m.RedeemAccessToken(ctx, resource, token)
+		if err != nil {
+			t.Fatalf("RedeemAccessToken() error = %v", err)
+		}
+		if claims.Calls != want || claims.Subject != "0xpayer" || next == "" {
+			t.Fatalf("claims = %+v, next = %q, want %d calls left", claims, next, want)
+		}
+		token = next
+	}
+	if _, _, err := m.RedeemAccessToken(ctx, resource, token); !errors.Is(err, ErrAccessTokenExhausted) {
+		t.Errorf("used up token: error = %v, want ErrAccessTokenExhausted", err)
+	}
+}
+
+func TestAccessTokens_Store(t *testing.T) {
+	ctx := context.Background()
+	store := countingStore{}
+	m, _ := newAccessMiddleware(t, AccessTokenConfig{Secret: testAccessSecret, Calls: 1, Store: store})
+	resource := Resource{Path: "/api/1"}
+
+	token, err := m.IssueAccessToken(ctx, resource, "0xpayer", "base-sepolia")
+	if err != nil {
+		t.Fatalf("IssueAccessToken() error = %v", err)
+	}
+	if _, next, err := m.RedeemAccessToken(ctx, resource, token); err != nil || next != "" {
+		t.Fatalf("RedeemAccessToken() = %q, %v, want no next token", next, err)
+	}
+	// The same token cannot be replayed when counted in a store
+	if _, _, err := m.RedeemAccessToken(ctx, resource, token); !errors.Is(err, ErrAccessTokenExhausted) {
+		t.Errorf("replayed token: error = %v, want ErrAccessTokenExhausted", err)
+	}
+}
+
+func TestAccessTokens_Rejected(t *testing.T) {
+	ctx := context.Background()
+	m, clock := newAccessMiddleware(t, AccessTokenConfig{
+		Secret: testAccessSecret,
+		TTL:    time.Minute,
+		Scope:  func(Resource) string { return "/api/*" },
+	})
+	token, err := m.IssueAccessToken(ctx, Resource{Path: "/api/1"}, "0xpayer", "base-sepolia")
+	if err != nil {
+		t.Fatalf("IssueAccessToken() error = %v", err)
+	}
+
+	other, _ := newAccessMiddleware(t, AccessTokenConfig{Secret: []byte("another secret of at least 32 bytes")})
+	forged, err := other.IssueAccessToken(ctx, Resource{Path: "/api/1"}, "0xpayer", "base-sepolia")
+	if err != nil {
+		t.Fatalf("IssueAccessToken() error = %v", err)
+	}
+
+	if _, _, err := m.RedeemAccessToken(ctx, Resource{Path: "/api/2"}, token); err != nil {
+		t.Errorf("token in scope: error = %v", err)
+	}
+	if _, _, err := m.RedeemAccessToken(ctx, Resource{Path: "/admin"}, token); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("token out of scope: error = %v, want ErrInvalidAccessToken", err)
+	}
+	if _, _, err := m.RedeemAccessToken(ctx, Resource{Method: "POST", Path: "/api/1"}, token); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("token for another method: error = %v, want ErrInvalidAccessToken", err)
+	}
+	if _, _, err := m.RedeemAccessToken(ctx, Resource{Path: "/api/1"}, forged); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("token signed with another secret: error = %v, want ErrInvalidAccessToken", err)
+	}
+	if _, _, err := m.RedeemAccessToken(ctx, Resource{Path: "/api/1"}, "not-a-token"); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("malformed token: error = %v, want ErrInvalidAccessToken", err)
+	}
+
+	clock.now = clock.now.Add(2 * time.Minute)
+	if _, _, err := m.RedeemAccessToken(ctx, Resource{Path: "/api/1"}, token); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("expired token: error = %v, want ErrInvalidAccessToken", err)
+	}
+}
+
+func TestAccessTokens_TopUp(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newAccessMiddleware(t, AccessTokenConfig{Secret: testAccessSecret, Scope: func(Resource) string { return "/*" }})
+	m.config.Credits = &CreditsConfig{
+		Store: newLedgerStore(),
+		TopUp: func(resource Resource) bool { return resource.Path == "/credits" },
+	}
+	topUp := Resource{Method: "GET", Path: "/credits"}
+
+	if token, err := m.IssueAccessToken(ctx, topUp, "0xpayer", "base-sepolia"); err != nil || token != "" {
+		t.Errorf("IssueAccessToken() for a top-up = %q, %v, want no token", token, err)
+	}
+
+	token, err := m.IssueAccessToken(ctx, Resource{Method: "GET", Path: "/api/1"}, "0xpayer", "base-sepolia")
+	if err != nil {
+		t.Fatalf("IssueAccessToken() error = %v", err)
+	}
+	if _, _, err := m.RedeemAccessToken(ctx, topUp, token); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("token for a top-up: error = %v, want ErrInvalidAccessToken", err)
+	}
+}
+
+func TestAccessTokenConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  AccessTokenConfig
+		wantErr bool
+	}{
+		{"valid", AccessTokenConfig{Secret: testAccessSecret}, false},
+		{"short secret", AccessTokenConfig{Secret: []byte("short")}, true},
+		{"negative calls", AccessTokenConfig{Secret: testAccessSecret, Calls: -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (tt.config.TTL != defaultAccessTokenTTL || tt.config.Scope == nil) {
+				t.Errorf("defaults not set: %+v", tt.config)
+			}
+		})
+	}
+}
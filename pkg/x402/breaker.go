@@ -0,0 +1,273 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultBreakerFailures is the default number of consecutive failures that open a circuit.
+	defaultBreakerFailures = 5
+
+	// defaultBreakerMinRequests is the default number of requests before the failure rate applies.
+	defaultBreakerMinRequests = 20
+
+	// defaultBreakerWindow is the default window over which the failure rate is measured.
+	defaultBreakerWindow = 30 * time.Second
+
+	// defaultBreakerOpenTimeout is the default time a circuit stays open before probing.
+	defaultBreakerOpenTimeout = 30 * time.Second
+)
+
+// ErrCircuitOpen indicates that a facilitator request was not sent because its circuit is open.
+var ErrCircuitOpen = errors.New("x402: facilitator circuit breaker is open")
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects requests until the open timeout elapses.
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe requests through.
+	CircuitHalfOpen
+)
+
+// String returns the state name.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// BreakerConfig configures a facilitator circuit breaker.
+//
+// The circuit opens after ConsecutiveFailures failures in a row, or when at
+// least MinRequests requests were made in the current Window and the share of
+// failures reaches FailureRate. After OpenTimeout it lets HalfOpenRequests
+// probes through: one success closes it, one failure opens it again.
+// Only transport errors and 5xx responses count as failures.
+type BreakerConfig struct {
+	ConsecutiveFailures int           // Optional: defaults to 5
+	FailureRate         float64       // Optional: between 0 and 1, 0 disables the rate threshold
+	MinRequests         int           // Optional: defaults to 20
+	Window              time.Duration // Optional: defaults to 30s
+	OpenTimeout         time.Duration // Optional: defaults to 30s
+	HalfOpenRequests    int           // Optional: defaults to 1
+
+	// OnStateChange is called after every state transition (optional).
+	// It runs on the request path and should return quickly.
+	OnStateChange func(name string, from, to CircuitState)
+}
+
+// withDefaults returns the config with zero fields set to their defaults.
+func (c BreakerConfig) withDefaults() BreakerConfig {
+	if c.ConsecutiveFailures <= 0 {
+		c.ConsecutiveFailures = defaultBreakerFailures
+	}
+	if c.MinRequests <= 0 {
+		c.MinRequests = defaultBreakerMinRequests
+	}
+	if c.Window <= 0 {
+		c.Window = defaultBreakerWindow
+	}
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = defaultBreakerOpenTimeout
+	}
+	if c.HalfOpenRequests <= 0 {
+		c.HalfOpenRequests = 1
+	}
+	return c
+}
+
+// CircuitOpenError is returned instead of sending a request while the circuit is open.
+type CircuitOpenError struct {
+	// Name identifies the facilitator.
+	Name string
+	// RetryAfter is the time until the circuit lets probe requests through.
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%v: %s (retry after %v)", ErrCircuitOpen, e.Name, e.RetryAfter)
+}
+
+// Unwrap returns ErrCircuitOpen.
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// CircuitBreaker stops sending requests to a facilitator that keeps failing.
+type CircuitBreaker struct {
+	name   string
+	config BreakerConfig
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu           sync.Mutex
+	state        CircuitState
+	openedAt     time.Time
+	consecutive  int
+	windowStart  time.Time
+	requests     int
+	failures     int
+	halfOpenUsed int
+	changes      []stateChange
+}
+
+// stateChange is a transition waiting to be reported to OnStateChange.
+type stateChange struct {
+	from, to CircuitState
+}
+
+// NewCircuitBreaker creates a closed circuit breaker. name identifies the
+// facilitator in logs and OnStateChange.
+func NewCircuitBreaker(name string, config BreakerConfig, logger Logger) *CircuitBreaker {
+	return newCircuitBreaker(name, config, newLogger(nil, logger, false))
+}
+
+// newCircuitBreaker creates a closed circuit breaker logging to logger.
+func newCircuitBreaker(name string, config BreakerConfig, logger *slog.Logger) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:   name,
+		config: config.withDefaults(),
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// State returns the current state.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.unlock()
+	b.advance()
+	return b.state
+}
+
+// RetryAfter returns the time until an open circuit lets probes through,
+// or zero if the circuit is not open.
+func (b *CircuitBreaker) RetryAfter() time.Duration {
+	b.mu.Lock()
+	defer b.unlock()
+	b.advance()
+	if b.state != CircuitOpen {
+		return 0
+	}
+	return b.config.OpenTimeout - b.now().Sub(b.openedAt)
+}
+
+// Allow reports whether a request may be sent. It returns a *CircuitOpenError
+// if the circuit is open or all half-open probes are in flight.
+// Every allowed request must be followed by a call to Record.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.unlock()
+	b.advance()
+
+	switch b.state {
+	case CircuitOpen:
+		return &CircuitOpenError{Name: b.name, RetryAfter: b.config.OpenTimeout - b.now().Sub(b.openedAt)}
+	case CircuitHalfOpen:
+		if b.halfOpenUsed >= b.config.HalfOpenRequests {
+			return &CircuitOpenError{Name: b.name}
+		}
+		b.halfOpenUsed++
+	}
+	return nil
+}
+
+// Record records the outcome of an allowed request.
+func (b *CircuitBreaker) Record(err error) {
+	failed := err != nil && isRetryable(err)
+
+	b.mu.Lock()
+	defer b.unlock()
+
+	if b.state == CircuitHalfOpen {
+		if failed {
+			b.transition(CircuitOpen)
+		} else {
+			b.transition(CircuitClosed)
+		}
+		return
+	}
+
+	now := b.now()
+	if now.Sub(b.windowStart) > b.config.Window {
+		b.windowStart, b.requests, b.failures = now, 0, 0
+	}
+	b.requests++
+	if !failed {
+		b.consecutive = 0
+		return
+	}
+	b.failures++
+	b.consecutive++
+
+	if b.state == CircuitClosed && b.shouldTrip() {
+		b.transition(CircuitOpen)
+	}
+}
+
+// shouldTrip reports whether the failure thresholds are reached.
+func (b *CircuitBreaker) shouldTrip() bool {
+	if b.consecutive >= b.config.ConsecutiveFailures {
+		return true
+	}
+	return b.config.FailureRate > 0 && b.requests >= b.config.MinRequests &&
+		float64(b.failures)/float64(b.requests) >= b.config.FailureRate
+}
+
+// advance moves an open circuit to half-open once the open timeout has elapsed.
+func (b *CircuitBreaker) advance() {
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.config.OpenTimeout {
+		b.transition(CircuitHalfOpen)
+	}
+}
+
+// transition changes state, resets counters and reports the change. Called with mu held.
+func (b *CircuitBreaker) transition(to CircuitState) {
+	from := b.state
+	b.state = to
+	b.consecutive, b.requests, b.failures, b.halfOpenUsed = 0, 0, 0, 0
+	b.windowStart = b.now()
+	if to == CircuitOpen {
+		b.openedAt = b.windowStart
+	}
+
+	level := slog.LevelWarn
+	if to == CircuitClosed {
+		level = slog.LevelInfo
+	}
+	b.logger.Log(context.Background(), level, "Facilitator circuit state changed",
+		"circuit", b.name, "from", from.String(), "to", to.String())
+	b.changes = append(b.changes, stateChange{from: from, to: to})
+}
+
+// unlock releases mu, then reports transitions so OnStateChange may call back
+// into the breaker.
+func (b *CircuitBreaker) unlock() {
+	changes := b.changes
+	b.changes = nil
+	b.mu.Unlock()
+
+	if b.config.OnStateChange == nil {
+		return
+	}
+	for _, change := range changes {
+		b.config.OnStateChange(b.name, change.from, change.to)
+	}
+}
@@ -0,0 +1,101 @@
+package x402
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock.
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestBreaker(config BreakerConfig) (*CircuitBreaker, *fakeClock, *[]string) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	var transitions []string
+	config.OnStateChange = func(_ string, from, to CircuitState) {
+		transitions = append(transitions, from.String()+">"+to.String())
+	}
+	b := NewCircuitBreaker("test", config, nil)
+	b.now = clock.Now
+	return b, clock, &transitions
+}
+
+var errUnavailable = &StatusError{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway"}
+
+func TestCircuitBreaker_Lifecycle(t *testing.T) {
+	b, clock, transitions := newTestBreaker(BreakerConfig{ConsecutiveFailures: 2, OpenTimeout: 10 * time.Second})
+
+	// Client errors do not count as failures
+	for range 3 {
+		b.Record(&StatusError{StatusCode: http.StatusBadRequest})
+	}
+	b.Record(errUnavailable)
+	if b.State() != CircuitClosed {
+		t.Fatalf("state = %s after one failure, want closed", b.State())
+	}
+	b.Record(errUnavailable)
+
+	var openErr *CircuitOpenError
+	if err := b.Allow(); !errors.As(err, &openErr) || openErr.RetryAfter != 10*time.Second {
+		t.Fatalf("Allow() = %v, want CircuitOpenError retrying after 10s", err)
+	}
+
+	// After the open timeout one probe is let through; it fails and reopens the circuit
+	clock.now = clock.now.Add(10 * time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() in half-open = %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second Allow() in half-open = %v, want ErrCircuitOpen", err)
+	}
+	b.Record(errUnavailable)
+	if got := b.RetryAfter(); got != 10*time.Second {
+		t.Errorf("RetryAfter() = %v, want 10s", got)
+	}
+
+	// A successful probe closes it
+	clock.now = clock.now.Add(10 * time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() in half-open = %v", err)
+	}
+	b.Record(nil)
+
+	want := []string{"closed>open", "open>half-open", "half-open>open", "open>half-open", "half-open>closed"}
+	if len(*transitions) != len(want) {
+		t.Fatalf("transitions = %v, want %v", *transitions, want)
+	}
+	for i := range want {
+		if (*transitions)[i] != want[i] {
+			t.Errorf("transitions = %v, want %v", *transitions, want)
+			break
+		}
+	}
+}
+
+func TestCircuitBreaker_FailureRate(t *testing.T) {
+	b, clock, _ := newTestBreaker(BreakerConfig{
+		ConsecutiveFailures: 100,
+		FailureRate:         0.5,
+		MinRequests:         4,
+		Window:              time.Minute,
+	})
+
+	// Failures from an expired window are forgotten
+	b.Record(errUnavailable)
+	b.Record(errUnavailable)
+	clock.now = clock.now.Add(2 * time.Minute)
+
+	b.Record(nil)
+	b.Record(errUnavailable)
+	b.Record(nil)
+	if b.State() != CircuitClosed {
+		t.Fatalf("state = %s below MinRequests, want closed", b.State())
+	}
+	b.Record(errUnavailable)
+	if b.State() != CircuitOpen {
+		t.Errorf("state = %s at 50%% failures, want open", b.State())
+	}
+}
@@ -2,82 +2,151 @@
 package x402
 
 import (
+	"context"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/dexfra-fun/x402-go/internal/ttlcache"
+	"github.com/dexfra-fun/x402-go/pkg/facilitator"
 )
 
 // FeePayerCache caches fee payer addresses to reduce facilitator calls.
+// A network may have several fee payers; Get rotates through them.
 type FeePayerCache struct {
-	mu       sync.RWMutex
-	data     map[string]cachedFeePayer
-	cacheTTL time.Duration
+	mu    sync.Mutex // serializes SetAll so rotation carries over
+	cache *ttlcache.Cache[string, *cachedFeePayer]
 }
 
 type cachedFeePayer struct {
-	feePayer  string
-	timestamp time.Time
+	feePayers []string
+	next      atomic.Uint64
 }
 
 // NewFeePayerCache creates a new fee payer cache.
 func NewFeePayerCache(ttl time.Duration) *FeePayerCache {
 	return &FeePayerCache{
-		data:     make(map[string]cachedFeePayer),
-		cacheTTL: ttl,
+		cache: ttlcache.New[string, *cachedFeePayer](ttl),
 	}
 }
 
 // Get retrieves a cached fee payer if it exists and hasn't expired.
+// If the network has several fee payers, each call returns the next one.
 func (c *FeePayerCache) Get(network string) (string, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	if cached, ok := c.data[network]; ok {
-		if time.Since(cached.timestamp) < c.cacheTTL {
-			return cached.feePayer, true
-		}
-		// Expired, will be removed on next cleanup
+	cached, ok := c.lookup(network)
+	if !ok {
+		return "", false
+	}
+	index := (cached.next.Add(1) - 1) % uint64(len(cached.feePayers))
+	return cached.feePayers[index], true
+}
+
+// GetAll retrieves all cached fee payers of a network if they haven't expired.
+func (c *FeePayerCache) GetAll(network string) ([]string, bool) {
+	cached, ok := c.lookup(network)
+	if !ok {
+		return nil, false
 	}
-	return "", false
+	return cached.feePayers, true
+}
+
+// lookup returns the unexpired entry for a network.
+func (c *FeePayerCache) lookup(network string) (*cachedFeePayer, bool) {
+	cached, ok := c.cache.Get(network)
+	if !ok || len(cached.feePayers) == 0 {
+		// Expired entries are removed on next cleanup
+		return nil, false
+	}
+	return cached, true
 }
 
 // Set stores a fee payer in the cache.
 func (c *FeePayerCache) Set(network, feePayer string) {
+	c.SetAll(network, []string{feePayer})
+}
+
+// SetAll stores the fee payers of a network in the cache, replacing any
+// previous entry. Rotation continues where the previous entry left off.
+func (c *FeePayerCache) SetAll(network string, feePayers []string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.data[network] = cachedFeePayer{
-		feePayer:  feePayer,
-		timestamp: time.Now(),
+	cached := &cachedFeePayer{feePayers: feePayers}
+	if previous, ok := c.cache.Get(network); ok {
+		cached.next.Store(previous.next.Load())
 	}
+	c.cache.Set(network, cached)
 }
 
 // Clear removes all entries from the cache.
 func (c *FeePayerCache) Clear() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	c.data = make(map[string]cachedFeePayer)
+	c.cache.Clear()
 }
 
 // CleanupExpired removes expired entries from the cache.
 func (c *FeePayerCache) CleanupExpired() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	now := time.Now()
-	for network, cached := range c.data {
-		if now.Sub(cached.timestamp) >= c.cacheTTL {
-			delete(c.data, network)
-		}
-	}
+	c.cache.CleanupExpired()
 }
 
 // StartCleanupRoutine starts a background goroutine to cleanup expired entries.
+// It runs until Close is called; only the first call starts a goroutine.
 func (c *FeePayerCache) StartCleanupRoutine(interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	go func() {
-		for range ticker.C {
-			c.CleanupExpired()
-		}
-	}()
+	c.cache.StartCleanup(interval)
+}
+
+// Close stops the cleanup routine.
+func (c *FeePayerCache) Close() {
+	c.cache.Close()
+}
+
+// SupportedCache caches /supported responses per facilitator base URL.
+//
+// Expired responses are served for up to a stale period while a single
+// background request refreshes them, and concurrent misses share one request.
+// A cache may be shared by many middlewares, e.g. one per tenant, through
+// Config.SupportedCache; its owner calls Close to stop background refreshes.
+type SupportedCache struct {
+	cache *ttlcache.Cache[string, *facilitator.SupportedResponse]
+}
+
+// NewSupportedCache creates a cache whose responses are fresh for ttl and
+// served stale for up to staleTTL longer while they are refreshed.
+// Expired responses are cleaned up every ttl.
+func NewSupportedCache(ttl, staleTTL time.Duration) *SupportedCache {
+	c := &SupportedCache{
+		cache: ttlcache.New[string, *facilitator.SupportedResponse](ttl, ttlcache.WithStaleTTL(staleTTL)),
+	}
+	c.cache.StartCleanup(ttl)
+	return c
+}
+
+// Get returns the cached response for a facilitator, calling fetch on a miss.
+func (c *SupportedCache) Get(
+	ctx context.Context,
+	baseURL string,
+	fetch func(ctx context.Context) (*facilitator.SupportedResponse, error),
+) (*facilitator.SupportedResponse, error) {
+	switch _, fresh, found := c.cache.Peek(baseURL); {
+	case fresh:
+		cacheEvent(ctx, EventCacheHit, "supported", baseURL)
+	case found:
+		cacheEvent(ctx, EventCacheStale, "supported", baseURL)
+	default:
+		cacheEvent(ctx, EventCacheMiss, "supported", baseURL)
+	}
+	return c.cache.GetOrLoad(ctx, baseURL, fetch)
+}
+
+// Invalidate drops the cached response for a facilitator.
+func (c *SupportedCache) Invalidate(baseURL string) {
+	c.cache.Delete(baseURL)
+}
+
+// Close stops background refreshes and cleanup. The cache stays usable.
+// Closing a nil cache does nothing.
+func (c *SupportedCache) Close() {
+	if c == nil {
+		return
+	}
+	c.cache.Close()
 }
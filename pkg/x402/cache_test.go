@@ -1,8 +1,15 @@
 package x402
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 func TestFeePayerCache(t *testing.T) {
@@ -46,6 +53,20 @@ func TestFeePayerCache(t *testing.T) {
 		}
 	})
 
+	t.Run("rotation", func(t *testing.T) {
+		cache := NewFeePayerCache(5 * time.Minute)
+		cache.SetAll("solana", []string{"feePayer1", "feePayer2"})
+
+		first, _ := cache.Get("solana")
+		cache.SetAll("solana", []string{"feePayer1", "feePayer2"})
+		second, _ := cache.Get("solana")
+		third, _ := cache.Get("solana")
+
+		if first != "feePayer1" || second != "feePayer2" || third != "feePayer1" {
+			t.Errorf("expected rotation across refreshes, got %s, %s, %s", first, second, third)
+		}
+	})
+
 	t.Run("clear", func(t *testing.T) {
 		cache := NewFeePayerCache(5 * time.Minute)
 		cache.Set("solana-devnet", "feePayer1")
@@ -61,3 +82,78 @@ func TestFeePayerCache(t *testing.T) {
 		}
 	})
 }
+
+func TestSupportedCache_Shared(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`{"kinds":[{"x402Version":1,"scheme":"exact","network":"solana"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	cache := NewSupportedCache(time.Minute, time.Minute)
+	defer cache.Close()
+	clients := []*FacilitatorClient{
+		NewFacilitatorClient(server.URL, NewFeePayerCache(0), nil, WithSupportedCache(cache)),
+		NewFacilitatorClient(server.URL, NewFeePayerCache(0), nil, WithSupportedCache(cache)),
+	}
+
+	for _, client := range clients {
+		for range 2 {
+			supported, err := client.Supported(context.Background())
+			if err != nil || len(supported.Kinds) != 1 {
+				t.Fatalf("Supported() = %+v, %v", supported, err)
+			}
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+
+	cache.Invalidate(server.URL)
+	if _, err := clients[0].Supported(context.Background()); err != nil || requests.Load() != 2 {
+		t.Errorf("expected a new request after Invalidate, got %d requests (err %v)", requests.Load(), err)
+	}
+}
+
+func TestNew_SharedSupportedCacheWithInvalidPool(t *testing.T) {
+	cache := NewSupportedCache(time.Minute, time.Minute)
+	defer cache.Close()
+
+	_, err := New(&Config{
+		RecipientAddress: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+		Network:          "base-sepolia",
+		FacilitatorURL:   "http://127.0.0.1:0",
+		PricingStrategy:  fixedPrice(decimal.RequireFromString("0.01")),
+		SupportedCache:   cache,
+		Facilitators:     []FacilitatorEndpoint{{ID: "no-such-facilitator"}},
+	})
+	if !errors.Is(err, ErrMissingFacilitator) {
+		t.Fatalf("New() error = %v, want ErrMissingFacilitator", err)
+	}
+
+	var closed *SupportedCache
+	closed.Close()
+}
+
+func TestMiddleware_CloseWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m, err := New(&Config{
+		Context:          ctx,
+		RecipientAddress: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+		Network:          "base-sepolia",
+		FacilitatorURL:   "http://127.0.0.1:0",
+		PricingStrategy:  fixedPrice(decimal.RequireFromString("0.01")),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	cancel()
+	select {
+	case <-m.ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("middleware was not closed when its context was canceled")
+	}
+	m.Close()
+}
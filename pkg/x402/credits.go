@@ -0,0 +1,301 @@
+package x402
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/internal/evm"
+	"github.com/dexfra-fun/x402-go/internal/svm"
+	"github.com/mr-tron/base58"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// defaultCreditMaxSkew is the default maximum age of a credit authorization.
+	defaultCreditMaxSkew = 5 * time.Minute
+
+	// creditMessageHeader is the first line of a signed credit authorization.
+	creditMessageHeader = "x402 credit authorization"
+)
+
+// BalanceStore keeps prepaid credit balances, in the token units prices are
+// quoted in (e.g. 1.5 for 1.50 USDC). Every credit and debit carries a unique
+// reference, so retried top-ups are not credited twice and signed requests
+// cannot be replayed. pkg/credits provides implementations.
+type BalanceStore interface {
+	// Balance returns the balance of an account, zero if it has none.
+	Balance(ctx context.Context, account string) (decimal.Decimal, error)
+	// Credit adds amount to an account and returns the new balance. A
+	// reference that was already credited leaves the balance unchanged.
+	Credit(ctx context.Context, account string, amount decimal.Decimal, reference string) (decimal.Decimal, error)
+	// Debit subtracts amount and returns the new balance. It returns
+	// ErrInsufficientBalance if the balance does not cover amount, and
+	// ErrDuplicateReference if the reference was already used.
+	Debit(ctx context.Context, account string, amount decimal.Decimal, reference string) (decimal.Decimal, error)
+}
+
+// CreditsConfig enables prepaid credits. Payments to top-up routes credit the
+// payer's balance in the network and asset they were paid in; requests to
+// other paid routes carrying a credit authorization signed by the account's
+// key are paid from its balance in one of the route's accepted networks and
+// assets. If no balance is sufficient, the request falls back to a normal payment.
+type CreditsConfig struct {
+	// Store keeps the balances (required).
+	Store BalanceStore
+	// TopUp reports whether a route tops up the balance (optional). Its price,
+	// from the PricingStrategy, is the amount credited.
+	TopUp func(resource Resource) bool
+	// MaxSkew is the maximum age of a credit authorization, and how far its
+	// timestamp may be in the future. Defaults to 5 minutes.
+	MaxSkew time.Duration
+}
+
+// validate checks the credits configuration and sets defaults.
+func (c *CreditsConfig) validate() error {
+	if c.Store == nil {
+		return fmt.Errorf("%w: store is required", ErrInvalidCreditsConfig)
+	}
+	if c.TopUp == nil {
+		c.TopUp = func(Resource) bool { return false }
+	}
+	if c.MaxSkew <= 0 {
+		c.MaxSkew = defaultCreditMaxSkew
+	}
+	return nil
+}
+
+// CreditAuthorization is a request signed by an account to pay for it from
+// its credit balance, sent base64 JSON encoded in the X-Credit-Authorization header.
+type CreditAuthorization struct {
+	// Account is the EVM (0x...) or Solana (base58) address of the signer.
+	Account   string `json:"account"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Nonce     string `json:"nonce"`
+	Timestamp int64  `json:"timestamp"`
+	// Signature is the personal_sign (EIP-191) hex signature for EVM accounts,
+	// or the base58 ed25519 signature for Solana accounts, of Message.
+	Signature string `json:"signature"`
+}
+
+// Message returns the text the account signs.
+func (a CreditAuthorization) Message() []byte {
+	return []byte(creditMessageHeader +
+		"\naccount: " + a.Account +
+		"\nmethod: " + a.Method +
+		"\npath: " + a.Path +
+		"\nnonce: " + a.Nonce +
+		"\ntimestamp: " + strconv.FormatInt(a.Timestamp, 10))
+}
+
+// MessageSigner signs credit authorizations. The EVM and Solana signers in
+// pkg/signers implement it.
+type MessageSigner interface {
+	Address() string
+	SignMessage(message []byte) (string, error)
+}
+
+// SignCreditAuthorization returns the X-Credit-Authorization header value of
+// a request, signed by signer.
+func SignCreditAuthorization(signer MessageSigner, method, path string) (string, error) {
+	auth := CreditAuthorization{
+		Account:   signer.Address(),
+		Method:    method,
+		Path:      path,
+		Nonce:     randomID(),
+		Timestamp: time.Now().Unix(),
+	}
+	signature, err := signer.SignMessage(auth.Message())
+	if err != nil {
+		return "", fmt.Errorf("sign credit authorization: %w", err)
+	}
+	auth.Signature = signature
+
+	data, err := sonic.Marshal(auth)
+	if err != nil {
+		return "", fmt.Errorf("marshal credit authorization: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// VerifyCreditAuthorization checks a credit authorization header presented
+// for resource and returns its decoded content.
+func (m *Middleware) VerifyCreditAuthorization(resource Resource, header string) (*CreditAuthorization, error) {
+	data, err := base64.StdEncoding.DecodeString(header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCreditAuthorization, err)
+	}
+	var auth CreditAuthorization
+	if err := sonic.Unmarshal(data, &auth); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCreditAuthorization, err)
+	}
+
+	switch {
+	case auth.Nonce == "":
+		return nil, fmt.Errorf("%w: missing nonce", ErrInvalidCreditAuthorization)
+	case !strings.EqualFold(auth.Method, resource.Method) || auth.Path != resource.Path:
+		return nil, fmt.Errorf("%w: signed for %s %s", ErrInvalidCreditAuthorization, auth.Method, auth.Path)
+	}
+	maxSkew := m.config.Credits.MaxSkew
+	if age := m.now().Sub(time.Unix(auth.Timestamp, 0)); age > maxSkew || age < -maxSkew {
+		return nil, fmt.Errorf("%w: timestamp outside the allowed skew", ErrInvalidCreditAuthorization)
+	}
+	if err := verifyMessage(auth.Account, auth.Message(), auth.Signature); err != nil {
+		return nil, err
+	}
+	return &auth, nil
+}
+
+// verifyMessage checks the signature of message by account.
+func verifyMessage(account string, message []byte, signature string) error {
+	if strings.HasPrefix(account, "0x") {
+		address, err := evm.ParseAddress(account)
+		if err != nil {
+			return fmt.Errorf("%w: account: %w", ErrInvalidCreditAuthorization, err)
+		}
+		sig, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+		if err != nil {
+			return fmt.Errorf("%w: signature: %w", ErrInvalidCreditAuthorization, err)
+		}
+		signer, err := evm.Recover(evm.PersonalMessageHash(message), sig)
+		if err != nil || !bytes.Equal(signer[:], address[:]) {
+			return fmt.Errorf("%w: signature does not match account", ErrInvalidCreditAuthorization)
+		}
+		return nil
+	}
+
+	key, err := svm.ParsePublicKey(account)
+	if err != nil {
+		return fmt.Errorf("%w: account: %w", ErrInvalidCreditAuthorization, err)
+	}
+	sig, err := base58.Decode(signature)
+	if err != nil || !ed25519.Verify(key[:], message, sig) {
+		return fmt.Errorf("%w: signature does not match account", ErrInvalidCreditAuthorization)
+	}
+	return nil
+}
+
+// CreditAccount returns the balance account of an address for credits paid
+// in asset on network. Balances are kept per network and asset, so each is in
+// the units of one token and testnet top-ups never pay for mainnet routes. EVM
+// addresses are lowercased, so checksummed and plain forms share one balance.
+func CreditAccount(address, network, asset string) string {
+	return network + ":" + normalizeCreditAddress(asset) + ":" + normalizeCreditAddress(address)
+}
+
+// normalizeCreditAddress lowercases EVM addresses; Solana addresses are case-sensitive.
+func normalizeCreditAddress(address string) string {
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return strings.ToLower(address)
+	}
+	return address
+}
+
+// IsTopUp reports whether payments for resource top up the payer's credits.
+func (m *Middleware) IsTopUp(resource Resource) bool {
+	return m.config.Credits != nil && m.config.Credits.TopUp(resource)
+}
+
+// CreditDebit is a request paid from a credit balance.
+type CreditDebit struct {
+	// Account is the address that signed the credit authorization.
+	Account string
+	// Requirement is the accepted requirement whose network and asset
+	// identify the balance that was debited.
+	Requirement x402.PaymentRequirement
+	// Amount is the amount debited.
+	Amount decimal.Decimal
+	// Balance is the balance left after the debit.
+	Balance decimal.Decimal
+
+	// reference is the store reference of the debit.
+	reference string
+}
+
+// PayFromCredits pays price for resource from the balance of the account that
+// signed the credit authorization header. The first balance, in the order of
+// requirements, that covers price is debited. It returns
+// ErrInvalidCreditAuthorization if the header is invalid or replayed, or
+// ErrInsufficientBalance if no balance covers price.
+func (m *Middleware) PayFromCredits(
+	ctx context.Context,
+	resource Resource,
+	header string,
+	requirements []x402.PaymentRequirement,
+	price decimal.Decimal,
+) (*CreditDebit, error) {
+	if m.config.Credits == nil {
+		return nil, ErrInvalidCreditAuthorization
+	}
+	auth, err := m.VerifyCreditAuthorization(resource, header)
+	if err != nil {
+		return nil, err
+	}
+
+	// The reference does not depend on the balance, so a nonce is spent once
+	reference := "debit:" + normalizeCreditAddress(auth.Account) + ":" + auth.Nonce
+	for _, requirement := range requirements {
+		account := CreditAccount(auth.Account, requirement.Network, requirement.Asset)
+		balance, err := m.config.Credits.Store.Debit(ctx, account, price, reference)
+		switch {
+		case errors.Is(err, ErrInsufficientBalance):
+			continue
+		case errors.Is(err, ErrDuplicateReference):
+			return nil, fmt.Errorf("%w: nonce already used", ErrInvalidCreditAuthorization)
+		case err != nil:
+			return nil, err
+		}
+		return &CreditDebit{
+			Account:     auth.Account,
+			Requirement: requirement,
+			Amount:      price,
+			Balance:     balance,
+			reference:   reference,
+		}, nil
+	}
+	return nil, ErrInsufficientBalance
+}
+
+// RefundCredits reverses debit, for a request whose handler failed, and
+// returns the new balance. A debit is refunded at most once.
+func (m *Middleware) RefundCredits(ctx context.Context, debit *CreditDebit) (decimal.Decimal, error) {
+	if m.config.Credits == nil {
+		return decimal.Zero, ErrInvalidCreditsConfig
+	}
+	account := CreditAccount(debit.Account, debit.Requirement.Network, debit.Requirement.Asset)
+	balance, err := m.config.Credits.Store.Credit(ctx, account, debit.Amount, "refund:"+debit.reference)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("refund credits: %w", err)
+	}
+	return balance, nil
+}
+
+// TopUpCredits credits amount to the balance of payer in the network and
+// asset of requirement, for the settled transaction, and returns the new balance.
+func (m *Middleware) TopUpCredits(
+	ctx context.Context,
+	payer string,
+	requirement x402.PaymentRequirement,
+	amount decimal.Decimal,
+	transaction string,
+) (decimal.Decimal, error) {
+	if m.config.Credits == nil {
+		return decimal.Zero, ErrInvalidCreditsConfig
+	}
+	account := CreditAccount(payer, requirement.Network, requirement.Asset)
+	balance, err := m.config.Credits.Store.Credit(ctx, account, amount, "topup:"+requirement.Network+":"+transaction)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("top up credits: %w", err)
+	}
+	return balance, nil
+}
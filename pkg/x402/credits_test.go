@@ -0,0 +1,235 @@
+package x402
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	x402 "github.com/dexfra-fun/x402-go"
+	evmsigner "github.com/dexfra-fun/x402-go/pkg/signers/evm"
+	svmsigner "github.com/dexfra-fun/x402-go/pkg/signers/svm"
+	"github.com/shopspring/decimal"
+)
+
+// ledgerStore is a BalanceStore keeping balances and references in maps.
+type ledgerStore struct {
+	balances   map[string]decimal.Decimal
+	references map[string]bool
+}
+
+func newLedgerStore() *ledgerStore {
+	return &ledgerStore{balances: map[string]decimal.Decimal{}, references: map[string]bool{}}
+}
+
+func (s *ledgerStore) Balance(_ context.Context, account string) (decimal.Decimal, error) {
+	return s.balances[account], nil
+}
+
+func (s *ledgerStore) Credit(_ context.Context, account string, amount decimal.Decimal, reference string) (decimal.Decimal, error) {
+	if !s.references[reference] {
+		s.references[reference] = true
+		s.balances[account] = s.balances[account].Add(amount)
+	}
+	return s.balances[account], nil
+}
+
+func (s *ledgerStore) Debit(_ context.Context, account string, amount decimal.Decimal, reference string) (decimal.Decimal, error) {
+	switch {
+	case s.references[reference]:
+		return decimal.Zero, ErrDuplicateReference
+	case s.balances[account].LessThan(amount):
+		return decimal.Zero, ErrInsufficientBalance
+	}
+	s.references[reference] = true
+	s.balances[account] = s.balances[account].Sub(amount)
+	return s.balances[account], nil
+}
+
+func newCreditsMiddleware(t *testing.T, store BalanceStore) (*Middleware, *fakeClock) {
+	t.Helper()
+
+	m, err := New(&Config{
+		RecipientAddress: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+		Network:          "base-sepolia",
+		FacilitatorURL:   "http://127.0.0.1:0",
+		PricingStrategy:  fixedPrice(decimal.RequireFromString("0.01")),
+		Credits: &CreditsConfig{
+			Store: store,
+			TopUp: func(resource Resource) bool { return resource.Path == "/credits" },
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(m.Close)
+	clock := &fakeClock{now: time.Now()}
+	m.now = clock.Now
+	return m, clock
+}
+
+func creditSigners(t *testing.T) map[string]MessageSigner {
+	t.Helper()
+
+	evmSigner, err := evmsigner.NewSignerFromHex("base-sepolia",
+		"0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatalf("evm.NewSignerFromHex() error = %v", err)
+	}
+	svmSigner, err := svmsigner.NewSigner("solana", ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)))
+	if err != nil {
+		t.Fatalf("svm.NewSigner() error = %v", err)
+	}
+	return map[string]MessageSigner{"evm": evmSigner, "svm": svmSigner}
+}
+
+func TestCredits_PayFromCredits(t *testing.T) {
+	ctx := context.Background()
+	price := decimal.RequireFromString("0.01")
+	resource := Resource{Method: "GET", Path: "/api/data"}
+	testnet := x402.PaymentRequirement{Network: "base-sepolia", Asset: x402.BaseSepolia.USDCAddress}
+	mainnet := x402.PaymentRequirement{Network: "base", Asset: x402.BaseMainnet.USDCAddress}
+
+	for name, signer := range creditSigners(t) {
+		t.Run(name, func(t *testing.T) {
+			m, _ := newCreditsMiddleware(t, newLedgerStore())
+			if !m.IsTopUp(Resource{Path: "/credits"}) || m.IsTopUp(resource) {
+				t.Fatal("IsTopUp does not follow CreditsConfig.TopUp")
+			}
+
+			header, err := SignCreditAuthorization(signer, "GET", "/api/data")
+			if err != nil {
+				t.Fatalf("SignCreditAuthorization() error = %v", err)
+			}
+			if _, err := m.PayFromCredits(ctx, resource, header, []x402.PaymentRequirement{testnet}, price); !errors.Is(err, ErrInsufficientBalance) {
+				t.Fatalf("PayFromCredits() without balance: error = %v, want ErrInsufficientBalance", err)
+			}
+
+			// Top-ups are credited once per transaction
+			for range 2 {
+				balance, err := m.TopUpCredits(ctx, signer.Address(), testnet, decimal.RequireFromString("0.015"), "0xtx")
+				if err != nil || !balance.Equal(decimal.RequireFromString("0.015")) {
+					t.Fatalf("TopUpCredits() = %s, %v, want 0.015", balance, err)
+				}
+			}
+
+			// A testnet balance does not pay for mainnet routes
+			if _, err := m.PayFromCredits(ctx, resource, header, []x402.PaymentRequirement{mainnet}, price); !errors.Is(err, ErrInsufficientBalance) {
+				t.Fatalf("PayFromCredits() on mainnet: error = %v, want ErrInsufficientBalance", err)
+			}
+
+			debit, err := m.PayFromCredits(ctx, resource, header, []x402.PaymentRequirement{mainnet, testnet}, price)
+			if err != nil {
+				t.Fatalf("PayFromCredits() error = %v", err)
+			}
+			if debit.Account != signer.Address() || debit.Requirement.Network != testnet.Network ||
+				!debit.Balance.Equal(decimal.RequireFromString("0.005")) {
+				t.Errorf("PayFromCredits() = %+v, want %s on %s with 0.005 left", debit, signer.Address(), testnet.Network)
+			}
+			if _, err := m.PayFromCredits(ctx, resource, header, []x402.PaymentRequirement{testnet}, decimal.Zero); !errors.Is(err, ErrInvalidCreditAuthorization) {
+				t.Errorf("replayed authorization: error = %v, want ErrInvalidCreditAuthorization", err)
+			}
+
+			// A refund restores the debit once
+			for range 2 {
+				balance, err := m.RefundCredits(ctx, debit)
+				if err != nil || !balance.Equal(decimal.RequireFromString("0.015")) {
+					t.Errorf("RefundCredits() = %s, %v, want 0.015", balance, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCredits_VerifyRejected(t *testing.T) {
+	signers := creditSigners(t)
+	m, clock := newCreditsMiddleware(t, newLedgerStore())
+	resource := Resource{Method: "GET", Path: "/api/data"}
+
+	header, err := SignCreditAuthorization(signers["evm"], "GET", "/api/data")
+	if err != nil {
+		t.Fatalf("SignCreditAuthorization() error = %v", err)
+	}
+	tamper := func(change func(*CreditAuthorization)) string {
+		data, _ := base64.StdEncoding.DecodeString(header)
+		var auth CreditAuthorization
+		if err := sonic.Unmarshal(data, &auth); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		change(&auth)
+		data, _ = sonic.Marshal(auth)
+		return base64.StdEncoding.EncodeToString(data)
+	}
+
+	if _, err := m.VerifyCreditAuthorization(resource, header); err != nil {
+		t.Fatalf("VerifyCreditAuthorization() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		resource Resource
+		header   string
+	}{
+		{"other path", Resource{Method: "GET", Path: "/api/other"}, header},
+		{"other method", Resource{Method: "POST", Path: "/api/data"}, header},
+		{"malformed", resource, "not base64!"},
+		{"other account", resource, tamper(func(a *CreditAuthorization) { a.Account = signers["svm"].Address() })},
+		{"changed nonce", resource, tamper(func(a *CreditAuthorization) { a.Nonce = "other" })},
+		{"missing nonce", resource, tamper(func(a *CreditAuthorization) { a.Nonce = "" })},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := m.VerifyCreditAuthorization(tt.resource, tt.header); !errors.Is(err, ErrInvalidCreditAuthorization) {
+				t.Errorf("error = %v, want ErrInvalidCreditAuthorization", err)
+			}
+		})
+	}
+
+	clock.now = clock.now.Add(10 * time.Minute)
+	if _, err := m.VerifyCreditAuthorization(resource, header); !errors.Is(err, ErrInvalidCreditAuthorization) {
+		t.Errorf("stale authorization: error = %v, want ErrInvalidCreditAuthorization", err)
+	}
+}
+
+func TestCreditAccount(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		network string
+		asset   string
+		want    string
+	}{
+		{
+			"EVM lowercased", "0x209693Bc6afc0C5328bA36FaF03C514EF312287C", "base", x402.BaseMainnet.USDCAddress,
+			"base:0x833589fcd6edb6e08f4c7c32d4f71b54bda02913:0x209693bc6afc0c5328ba36faf03c514ef312287c",
+		},
+		{
+			"Solana unchanged", "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU", "solana", x402.SolanaMainnet.USDCAddress,
+			"solana:EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v:4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CreditAccount(tt.address, tt.network, tt.asset); got != tt.want {
+				t.Errorf("CreditAccount() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+	if CreditAccount(tests[0].address, "base-sepolia", x402.BaseSepolia.USDCAddress) == tests[0].want {
+		t.Error("CreditAccount() is shared between networks")
+	}
+}
+
+func TestCreditsConfig_Validate(t *testing.T) {
+	if err := (&CreditsConfig{}).validate(); !errors.Is(err, ErrInvalidCreditsConfig) {
+		t.Errorf("validate() without store: error = %v, want ErrInvalidCreditsConfig", err)
+	}
+	config := CreditsConfig{Store: newLedgerStore()}
+	if err := config.validate(); err != nil || config.TopUp == nil || config.MaxSkew != defaultCreditMaxSkew {
+		t.Errorf("validate() = %v, defaults not set: %+v", err, config)
+	}
+}
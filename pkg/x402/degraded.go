@@ -0,0 +1,82 @@
+package x402
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+)
+
+const (
+	// defaultDegradedRetryAfter is the Retry-After sent when the recovery time is unknown.
+	defaultDegradedRetryAfter = 30 * time.Second
+)
+
+// DegradedPolicy decides how requests are handled while the facilitator is
+// unavailable: its circuit breaker is open, it cannot be reached or it fails
+// with a 5xx status.
+//
+// Routes matching FreeRoutes are served without payment. All other paid
+// routes fail closed with 503 Service Unavailable and a Retry-After header.
+type DegradedPolicy struct {
+	// FreeRoutes are path.Match patterns such as "/api/status" or "/api/public/*".
+	FreeRoutes []string
+	// RetryAfter is sent when the time until recovery is unknown. Defaults to 30s.
+	RetryAfter time.Duration
+}
+
+// ServesFree reports whether a resource is served without payment in degraded mode.
+func (p DegradedPolicy) ServesFree(resource Resource) bool {
+	for _, pattern := range p.FreeRoutes {
+		if matched, _ := path.Match(pattern, resource.Path); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks the route patterns and sets defaults.
+func (p *DegradedPolicy) validate() error {
+	for _, pattern := range p.FreeRoutes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("x402: invalid degraded route %q: %w", pattern, err)
+		}
+	}
+	if p.RetryAfter <= 0 {
+		p.RetryAfter = defaultDegradedRetryAfter
+	}
+	return nil
+}
+
+// IsUnavailable reports whether err means the facilitator is unavailable:
+// its circuit is open, it could not be reached, or it answered with a 5xx status.
+func IsUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrFacilitatorUnavailable) {
+		return true
+	}
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return true
+	}
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode >= http.StatusInternalServerError
+}
+
+// availabilityReporter is implemented by facilitators with a circuit breaker.
+type availabilityReporter interface {
+	Unavailable() (time.Duration, bool)
+}
+
+// Unavailable reports whether the facilitator's circuit breaker is open, and
+// how long until it is probed again. It is always false for facilitators
+// without a circuit breaker.
+func (m *Middleware) Unavailable() (time.Duration, bool) {
+	if reporter, ok := m.facilitator.(availabilityReporter); ok {
+		return reporter.Unavailable()
+	}
+	return 0, false
+}
@@ -11,6 +11,8 @@ var (
 	ErrMissingFacilitator = errors.New("x402: facilitator URL is required")
 	// ErrMissingPricing indicates that the pricing strategy is not configured.
 	ErrMissingPricing = errors.New("x402: pricing strategy is required")
+	// ErrMissingAuthorizer indicates that a facilitator requires authentication but has no authorizer.
+	ErrMissingAuthorizer = errors.New("x402: facilitator requires authentication")
 
 	// ErrInvalidFeePayer indicates that the fee payer address is invalid.
 	ErrInvalidFeePayer = errors.New("x402: invalid fee payer address")
@@ -18,10 +20,40 @@ var (
 	ErrMissingFeePayer = errors.New("x402: fee payer is required (not provided by facilitator and not in config)")
 	// ErrFacilitatorUnavailable indicates that the facilitator service is unavailable.
 	ErrFacilitatorUnavailable = errors.New("x402: facilitator service unavailable")
+	// ErrFeePayerMismatch indicates that none of a facilitator's fee payers is registered for it.
+	ErrFeePayerMismatch = errors.New("x402: fee payer is not registered for facilitator")
 	// ErrFeePayerNotFound indicates that no fee payer was found for the specified network.
 	ErrFeePayerNotFound = errors.New("x402: fee payer not found for network")
 	// ErrPaymentVerificationFailed indicates that payment verification failed.
 	ErrPaymentVerificationFailed = errors.New("x402: payment verification failed")
 	// ErrNetworkNotSupported indicates that the network is not supported.
 	ErrNetworkNotSupported = errors.New("x402: network not supported")
+	// ErrInvalidAsset indicates that an accepted asset is misconfigured.
+	ErrInvalidAsset = errors.New("x402: invalid asset configuration")
+	// ErrNonceInFlight indicates that the same payment is currently being processed.
+	ErrNonceInFlight = errors.New("x402: payment is already being processed")
+	// ErrNonceUsed indicates that the payment was already settled.
+	ErrNonceUsed = errors.New("x402: payment has already been used")
+	// ErrNoMatchingRequirement indicates that a payment matches none of the accepted requirements.
+	ErrNoMatchingRequirement = errors.New("x402: payment does not match any accepted requirement")
+	// ErrEventQueueFull indicates that an event was dropped because a subscriber's queue is full.
+	ErrEventQueueFull = errors.New("x402: event queue is full")
+	// ErrEventDispatcherClosed indicates that an event was dispatched after Close.
+	ErrEventDispatcherClosed = errors.New("x402: event dispatcher is closed")
+	// ErrInvalidWebhookSignature indicates that a webhook's signature or timestamp is invalid.
+	ErrInvalidWebhookSignature = errors.New("x402: invalid webhook signature")
+	// ErrInvalidAccessTokenConfig indicates an invalid AccessTokenConfig.
+	ErrInvalidAccessTokenConfig = errors.New("x402: invalid access token configuration")
+	// ErrInvalidAccessToken indicates that an access token is malformed, expired or out of scope.
+	ErrInvalidAccessToken = errors.New("x402: invalid access token")
+	// ErrAccessTokenExhausted indicates that an access token has no calls left.
+	ErrAccessTokenExhausted = errors.New("x402: access token has no calls left")
+	// ErrInvalidCreditsConfig indicates an invalid CreditsConfig.
+	ErrInvalidCreditsConfig = errors.New("x402: invalid credits configuration")
+	// ErrInvalidCreditAuthorization indicates a malformed, stale or wrongly signed credit authorization.
+	ErrInvalidCreditAuthorization = errors.New("x402: invalid credit authorization")
+	// ErrInsufficientBalance indicates that a credit balance does not cover a price.
+	ErrInsufficientBalance = errors.New("x402: insufficient credit balance")
+	// ErrDuplicateReference indicates that a balance entry with the same reference exists.
+	ErrDuplicateReference = errors.New("x402: duplicate balance reference")
 )
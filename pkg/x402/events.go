@@ -0,0 +1,287 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+)
+
+// defaultEventQueueSize is the default number of events buffered per subscriber.
+const defaultEventQueueSize = 1024
+
+// EventType is the kind of a payment event.
+type EventType string
+
+// Payment event types.
+const (
+	// EventPaymentVerified is emitted when the facilitator accepted a payment.
+	EventPaymentVerified EventType = "payment.verified"
+	// EventPaymentSettled is emitted when a payment was settled on chain.
+	EventPaymentSettled EventType = "payment.settled"
+	// EventPaymentFailed is emitted when a payment was rejected or could not be settled.
+	EventPaymentFailed EventType = "payment.failed"
+)
+
+// Event is a payment event delivered to subscribers of an EventDispatcher.
+// Webhooks receive it as their JSON body.
+type Event struct {
+	// ID is unique per event; webhook receivers use it to drop duplicate deliveries.
+	ID        string    `json:"id"`
+	Type      EventType `json:"type"`
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"requestId,omitempty"`
+	Route     string    `json:"route"`
+	Method    string    `json:"method"`
+
+	// PaymentInfo describes the payment option the client used.
+	PaymentInfo *PaymentInfo `json:"paymentInfo,omitempty"`
+	Payer       string       `json:"payer,omitempty"`
+	// Settlement is the facilitator's settlement response (settled events only).
+	Settlement *x402.SettlementResponse `json:"settlement,omitempty"`
+
+	// Step is the failed step of a failed event, EndpointVerify or EndpointSettle.
+	Step         string         `json:"step,omitempty"`
+	ErrorCode    x402.ErrorCode `json:"errorCode,omitempty"`
+	ErrorMessage string         `json:"errorMessage,omitempty"`
+}
+
+// EventHandler handles a payment event. A returned error is retried with the
+// dispatcher's RetryPolicy.
+type EventHandler func(ctx context.Context, event Event) error
+
+// DeadLetter is an event that could not be delivered to a subscriber.
+type DeadLetter struct {
+	Event Event
+	// Subscriber is the name of the handler, or the URL of the webhook.
+	Subscriber string
+	// Attempts is the number of delivery attempts; 0 if the event was dropped
+	// because the subscriber's queue was full or the dispatcher was closed.
+	Attempts int
+	Err      error
+}
+
+// subscriber is a handler with its own bounded queue and delivery goroutine,
+// so a slow webhook neither delays other subscribers nor reorders its events.
+type subscriber struct {
+	name    string
+	handler EventHandler
+	types   []EventType // empty for all types
+	queue   chan Event
+}
+
+// EventDispatcher emits payment events to Go handlers and webhooks (see
+// Config.Events). Dispatch never blocks the request: each subscriber has a
+// bounded queue, and events that overflow it or fail every retry are passed
+// to the dead-letter callback.
+type EventDispatcher struct {
+	subscribers []*subscriber
+	queueSize   int
+	retry       RetryPolicy
+	deadLetter  func(DeadLetter)
+	logger      *slog.Logger
+
+	mu     sync.RWMutex // guards closed against sends on closed queues
+	closed bool
+	ctx    context.Context //nolint:containedctx // canceled by Shutdown to stop retries
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// EventOption configures an EventDispatcher.
+type EventOption func(*EventDispatcher)
+
+// WithEventHandler subscribes a Go handler to events of the given types, or
+// to all events if none are given. The name identifies it in logs and dead letters.
+func WithEventHandler(name string, handler EventHandler, types ...EventType) EventOption {
+	return func(d *EventDispatcher) {
+		d.subscribers = append(d.subscribers, &subscriber{name: name, handler: handler, types: types})
+	}
+}
+
+// WithWebhook subscribes a webhook. Its deliveries are signed with its secret
+// (see VerifyWebhook).
+func WithWebhook(webhook Webhook) EventOption {
+	return WithEventHandler(webhook.URL, webhook.Deliver, webhook.Types...)
+}
+
+// WithEventQueueSize sets the number of events buffered per subscriber (default 1024).
+func WithEventQueueSize(size int) EventOption {
+	return func(d *EventDispatcher) {
+		d.queueSize = size
+	}
+}
+
+// WithEventRetry sets how failed deliveries are retried. Zero fields use the
+// RetryPolicy defaults.
+func WithEventRetry(policy RetryPolicy) EventOption {
+	return func(d *EventDispatcher) {
+		d.retry = policy
+	}
+}
+
+// WithDeadLetter sets the callback for events that could not be delivered,
+// e.g. to store them for replay. It is called from the delivery goroutines
+// and from Dispatch, so it must not block.
+func WithDeadLetter(deadLetter func(DeadLetter)) EventOption {
+	return func(d *EventDispatcher) {
+		d.deadLetter = deadLetter
+	}
+}
+
+// WithEventLogger sets the logger for delivery failures (default: discard).
+func WithEventLogger(logger *slog.Logger) EventOption {
+	return func(d *EventDispatcher) {
+		d.logger = logger
+	}
+}
+
+// NewEventDispatcher creates a dispatcher and starts a delivery goroutine per
+// subscriber. Call Shutdown to stop them.
+func NewEventDispatcher(opts ...EventOption) *EventDispatcher {
+	d := &EventDispatcher{
+		queueSize:  defaultEventQueueSize,
+		deadLetter: func(DeadLetter) {},
+		logger:     slog.New(slog.DiscardHandler),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	d.retry = d.retry.withDefaults()
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+
+	for _, sub := range d.subscribers {
+		sub.queue = make(chan Event, max(d.queueSize, 1))
+		d.wg.Go(func() {
+			for event := range sub.queue {
+				d.deliver(sub, event)
+			}
+		})
+	}
+	return d
+}
+
+// Dispatch queues an event for all subscribers of its type. It assigns an ID
+// and time if the event has none.
+func (d *EventDispatcher) Dispatch(event Event) {
+	if event.ID == "" {
+		event.ID = randomID()
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, sub := range d.subscribers {
+		if len(sub.types) > 0 && !slices.Contains(sub.types, event.Type) {
+			continue
+		}
+		if d.closed {
+			d.drop(sub, event, ErrEventDispatcherClosed)
+			continue
+		}
+		select {
+		case sub.queue <- event:
+		default:
+			d.drop(sub, event, ErrEventQueueFull)
+		}
+	}
+}
+
+// drop passes an undelivered event to the dead-letter callback.
+func (d *EventDispatcher) drop(sub *subscriber, event Event, err error) {
+	d.logger.Warn("Event dropped", "subscriber", sub.name, "event", event.ID, "type", string(event.Type), "error", err)
+	d.deadLetter(DeadLetter{Event: event, Subscriber: sub.name, Err: err})
+}
+
+// deliver calls a subscriber's handler until it succeeds, fails permanently
+// or runs out of attempts.
+func (d *EventDispatcher) deliver(sub *subscriber, event Event) {
+	var err error
+	attempt := 1
+	for ; ; attempt++ {
+		err = d.call(sub, event)
+		if err == nil {
+			return
+		}
+		if attempt >= d.retry.MaxAttempts || IsPermanent(err) {
+			break
+		}
+		d.logger.Debug("Event delivery failed, retrying",
+			"subscriber", sub.name, "event", event.ID, "attempt", attempt, "error", err)
+		if sleep(d.ctx, d.retry.backoff(attempt-1)) != nil {
+			break
+		}
+	}
+
+	d.logger.Error("Event delivery failed",
+		"subscriber", sub.name, "event", event.ID, "type", string(event.Type), "attempts", attempt, "error", err)
+	d.deadLetter(DeadLetter{Event: event, Subscriber: sub.name, Attempts: attempt, Err: err})
+}
+
+// call runs a handler, turning a panic into an error.
+func (d *EventDispatcher) call(sub *subscriber, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("event handler panicked: %v", r)
+		}
+	}()
+	return sub.handler(d.ctx, event)
+}
+
+// Shutdown stops accepting events, delivers the queued ones and waits for the
+// delivery goroutines to return. Events dispatched afterwards are dead-lettered.
+// If ctx is done first, pending retries are abandoned and ctx's error returned.
+func (d *EventDispatcher) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, sub := range d.subscribers {
+			close(sub.queue)
+		}
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// permanentError marks an error that retrying will not fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an EventHandler error as not worth retrying; the event goes
+// straight to the dead-letter callback.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
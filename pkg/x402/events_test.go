@@ -0,0 +1,241 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	x402 "github.com/dexfra-fun/x402-go"
+)
+
+// fastRetry retries three times without noticeable delay.
+var fastRetry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+// deadLetters collects dead letters.
+type deadLetters struct {
+	mu      sync.Mutex
+	letters []DeadLetter
+}
+
+func (d *deadLetters) add(letter DeadLetter) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.letters = append(d.letters, letter)
+}
+
+func (d *deadLetters) all() []DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]DeadLetter(nil), d.letters...)
+}
+
+func TestEventDispatcher_Handlers(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		all      []EventType
+		settled  []EventType
+		attempts atomic.Int32
+	)
+	dead := &deadLetters{}
+	dispatcher := NewEventDispatcher(
+		WithEventHandler("all", func(_ context.Context, event Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			all = append(all, event.Type)
+			return nil
+		}),
+		WithEventHandler("settled", func(_ context.Context, event Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			settled = append(settled, event.Type)
+			return nil
+		}, EventPaymentSettled),
+		WithEventHandler("flaky", func(context.Context, Event) error {
+			if attempts.Add(1) < 3 {
+				return errors.New("temporarily unavailable")
+			}
+			return nil
+		}, EventPaymentVerified),
+		WithEventHandler("panics", func(context.Context, Event) error {
+			panic("boom")
+		}, EventPaymentFailed),
+		WithEventRetry(fastRetry),
+		WithDeadLetter(dead.add),
+	)
+
+	for _, eventType := range []EventType{EventPaymentVerified, EventPaymentSettled, EventPaymentFailed} {
+		dispatcher.Dispatch(Event{Type: eventType})
+	}
+	if err := dispatcher.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	want := []EventType{EventPaymentVerified, EventPaymentSettled, EventPaymentFailed}
+	if len(all) != len(want) || all[0] != want[0] || all[1] != want[1] || all[2] != want[2] {
+		t.Errorf("all = %v, want %v in order", all, want)
+	}
+	if len(settled) != 1 || settled[0] != EventPaymentSettled {
+		t.Errorf("settled = %v, want only the settled event", settled)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("flaky handler attempts = %d, want 3", got)
+	}
+
+	letters := dead.all()
+	if len(letters) != 1 || letters[0].Subscriber != "panics" || letters[0].Attempts != fastRetry.MaxAttempts {
+		t.Fatalf("dead letters = %+v, want the panicking handler after %d attempts", letters, fastRetry.MaxAttempts)
+	}
+	if letters[0].Event.ID == "" || letters[0].Event.Time.IsZero() {
+		t.Errorf("dead letter event = %+v, want an ID and time", letters[0].Event)
+	}
+
+	dispatcher.Dispatch(Event{Type: EventPaymentSettled})
+	if letters := dead.all(); len(letters) != 3 || !errors.Is(letters[2].Err, ErrEventDispatcherClosed) {
+		t.Errorf("dead letters after shutdown = %+v, want closed errors", letters)
+	}
+}
+
+func TestEventDispatcher_QueueFull(t *testing.T) {
+	release := make(chan struct{})
+	dead := &deadLetters{}
+	dispatcher := NewEventDispatcher(
+		WithEventHandler("slow", func(context.Context, Event) error {
+			<-release
+			return nil
+		}),
+		WithEventQueueSize(1),
+		WithDeadLetter(dead.add),
+	)
+
+	// The first event may be taken by the delivery goroutine, the next one
+	// fills the queue; at most two are accepted
+	for range 4 {
+		dispatcher.Dispatch(Event{Type: EventPaymentSettled})
+	}
+	close(release)
+	if err := dispatcher.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	letters := dead.all()
+	if len(letters) < 2 {
+		t.Fatalf("dead letters = %d, want at least 2", len(letters))
+	}
+	for _, letter := range letters {
+		if !errors.Is(letter.Err, ErrEventQueueFull) || letter.Attempts != 0 {
+			t.Errorf("dead letter = %+v, want a queue full drop", letter)
+		}
+	}
+}
+
+func TestEventDispatcher_ShutdownTimeout(t *testing.T) {
+	dead := &deadLetters{}
+	dispatcher := NewEventDispatcher(
+		WithEventHandler("failing", func(context.Context, Event) error {
+			return errors.New("down")
+		}),
+		WithEventRetry(RetryPolicy{MaxAttempts: 100, InitialBackoff: time.Hour, MaxBackoff: time.Hour}),
+		WithDeadLetter(dead.add),
+	)
+	dispatcher.Dispatch(Event{Type: EventPaymentSettled})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := dispatcher.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown = %v, want deadline exceeded", err)
+	}
+	if letters := dead.all(); len(letters) != 1 {
+		t.Errorf("dead letters = %d, want the abandoned event", len(letters))
+	}
+}
+
+func TestWebhook_Deliver(t *testing.T) {
+	secret := []byte("webhook-secret")
+	tests := []struct {
+		name      string
+		status    int
+		wantErr   bool
+		permanent bool
+	}{
+		{"ok", http.StatusNoContent, false, false},
+		{"server error", http.StatusBadGateway, true, false},
+		{"rate limited", http.StatusTooManyRequests, true, false},
+		{"rejected", http.StatusBadRequest, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received Event
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if err := VerifyWebhook(secret, r.Header, body, time.Minute); err != nil {
+					t.Errorf("VerifyWebhook: %v", err)
+				}
+				if err := sonic.Unmarshal(body, &received); err != nil {
+					t.Errorf("unmarshal event: %v", err)
+				}
+				if r.Header.Get(HeaderWebhookID) != received.ID || r.Header.Get(HeaderWebhookEvent) != string(received.Type) {
+					t.Errorf("event headers do not match body %+v", received)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			event := Event{
+				ID:         "evt-1",
+				Type:       EventPaymentSettled,
+				Route:      "/api",
+				Settlement: &x402.SettlementResponse{Success: true, Transaction: "0xabc", Network: "base-sepolia"},
+			}
+			err := Webhook{URL: server.URL, Secret: secret}.Deliver(context.Background(), event)
+			if (err != nil) != tt.wantErr || IsPermanent(err) != tt.permanent {
+				t.Errorf("Deliver = %v, want error %v permanent %v", err, tt.wantErr, tt.permanent)
+			}
+			if received.Settlement == nil || received.Settlement.Transaction != "0xabc" {
+				t.Errorf("received = %+v, want the settlement", received)
+			}
+		})
+	}
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	secret := []byte("webhook-secret")
+	body := []byte(`{"id":"evt-1"}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		wantErr   bool
+	}{
+		{"valid", now, SignWebhook(secret, now, body), false},
+		{"wrong secret", now, SignWebhook([]byte("other"), now, body), true},
+		{"stale", old, SignWebhook(secret, old, body), true},
+		{"missing timestamp", "", SignWebhook(secret, "", body), true},
+		{"missing signature", now, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set(HeaderWebhookTimestamp, tt.timestamp)
+			header.Set(HeaderWebhookSignature, tt.signature)
+			err := VerifyWebhook(secret, header, body, 5*time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyWebhook = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidWebhookSignature) {
+				t.Errorf("VerifyWebhook = %v, want ErrInvalidWebhookSignature", err)
+			}
+		})
+	}
+}
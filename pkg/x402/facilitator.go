@@ -1,149 +1,337 @@
 package x402
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"path"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/bytedance/sonic"
 	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/pkg/facilitator"
+	"github.com/dexfra-fun/x402-go/pkg/facilitators"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
-	// defaultHTTPTimeout is the default timeout for HTTP requests to facilitator.
-	defaultHTTPTimeout = 10 * time.Second
+	// maxErrorBodySize is the maximum number of bytes of an error response kept in StatusError.
+	maxErrorBodySize = 4 << 10
 )
 
-// FacilitatorClient handles communication with x402 facilitator services.
+// FacilitatorClient handles communication with x402 facilitator services over HTTP.
 type FacilitatorClient struct {
 	baseURL    string
 	httpClient *http.Client
 	cache      *FeePayerCache
-	logger     Logger
+	logger     *slog.Logger
+	timeouts   x402.TimeoutConfig
+	retry      RetryPolicy
+	breaker    *CircuitBreaker
+	authorizer facilitator.RequestAuthorizer
+	transport  http.RoundTripper
+	feePayers  *feePayerResolver
+	supported  *SupportedCache
+	metrics    Metrics
+	tracer     trace.Tracer
+
+	slog        *slog.Logger
+	logPayloads bool
+
+	breakerConfig *BreakerConfig
+	registry      *facilitators.Facilitator
+	feePayerCheck FeePayerCheck
 }
 
-// SupportedResponse represents the /supported endpoint response.
-type SupportedResponse struct {
-	Kinds []Kind `json:"kinds"`
+// ClientOption configures a FacilitatorClient.
+type ClientOption func(*FacilitatorClient)
+
+// WithTimeouts sets the per-attempt timeouts of verify and settle requests
+// (/supported uses VerifyTimeout) and the overall RequestTimeout of a call
+// including retries. Zero fields use x402.NewDefaultTimeouts.
+func WithTimeouts(timeouts x402.TimeoutConfig) ClientOption {
+	return func(c *FacilitatorClient) {
+		c.timeouts = timeouts
+	}
 }
 
-// Kind represents a supported payment kind.
-type Kind struct {
-	X402Version int     `json:"x402Version"`
-	Scheme      string  `json:"scheme"`
-	Network     string  `json:"network"`
-	Extra       *Extras `json:"extra,omitempty"`
+// WithRetryPolicy sets the retry policy. Zero fields use the defaults.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *FacilitatorClient) {
+		c.retry = policy
+	}
 }
 
-// Extras contains additional metadata.
-type Extras struct {
-	FeePayer string `json:"feePayer"`
+// WithCircuitBreaker stops requests to the facilitator while it keeps failing,
+// returning a *CircuitOpenError instead of waiting for timeouts.
+func WithCircuitBreaker(config BreakerConfig) ClientOption {
+	return func(c *FacilitatorClient) {
+		c.breakerConfig = &config
+	}
 }
 
-// NewFacilitatorClient creates a new facilitator client.
-func NewFacilitatorClient(baseURL string, cache *FeePayerCache, logger Logger) *FacilitatorClient {
-	if logger == nil {
-		logger = &DefaultLogger{}
+// WithHTTPClient sets the HTTP client used for facilitator requests.
+// Its Timeout should be zero or longer than the configured timeouts.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(c *FacilitatorClient) {
+		c.httpClient = client
 	}
+}
 
-	return &FacilitatorClient{
-		baseURL: strings.TrimSuffix(baseURL, "/"),
-		httpClient: &http.Client{
-			Timeout: defaultHTTPTimeout,
-		},
-		cache:  cache,
-		logger: logger,
+// WithTransport sets the transport used for facilitator requests, e.g. to
+// route them through a proxy or add mTLS, without replacing the HTTP client.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *FacilitatorClient) {
+		c.transport = transport
 	}
 }
 
-// GetFeePayer retrieves the fee payer for a given network
-// Uses cache if available, otherwise fetches from facilitator.
-func (c *FacilitatorClient) GetFeePayer(ctx context.Context, network string) (string, error) {
-	// Try cache first
-	if feePayer, found := c.cache.Get(network); found {
-		c.logger.Printf("[x402] Fee payer cache hit: network=%s feePayer=%s", network, feePayer)
-		return feePayer, nil
+// WithMetrics reports the duration and outcome of each request attempt.
+func WithMetrics(metrics Metrics) ClientOption {
+	return func(c *FacilitatorClient) {
+		if metrics != nil {
+			c.metrics = metrics
+		}
 	}
+}
 
-	c.logger.Printf("[x402] Fee payer cache miss: network=%s, fetching from facilitator", network)
+// WithSlog sets the structured logger, which takes precedence over the
+// Logger passed to NewFacilitatorClient. Signatures and signed transactions
+// are redacted unless WithLogPayloads is set.
+func WithSlog(logger *slog.Logger) ClientOption {
+	return func(c *FacilitatorClient) {
+		c.slog = logger
+	}
+}
 
-	// Cache miss - fetch from facilitator
-	feePayer, found, err := c.fetchFeePayer(ctx, network)
-	if err != nil {
-		return "", fmt.Errorf("fetch fee payer: %w", err)
+// WithLogPayloads logs request bodies unredacted at debug level, including
+// signed transactions. Never enable it in production.
+func WithLogPayloads(enabled bool) ClientOption {
+	return func(c *FacilitatorClient) {
+		c.logPayloads = enabled
 	}
+}
 
-	if !found {
-		return "", ErrFeePayerNotFound
+// WithTracerProvider traces each request attempt with spans from provider and
+// propagates the trace context to the facilitator. The global provider is used
+// by default.
+func WithTracerProvider(provider trace.TracerProvider) ClientOption {
+	return func(c *FacilitatorClient) {
+		if provider != nil {
+			c.tracer = provider.Tracer(TracerName)
+		}
 	}
+}
 
-	// Cache the result
-	c.cache.Set(network, feePayer)
-	c.logger.Printf("[x402] Fee payer cached: network=%s feePayer=%s", network, feePayer)
+// WithSupportedCache caches /supported responses, e.g. in a cache shared by
+// several clients of the same facilitator.
+func WithSupportedCache(cache *SupportedCache) ClientOption {
+	return func(c *FacilitatorClient) {
+		c.supported = cache
+	}
+}
 
-	return feePayer, nil
+// WithFeePayerCheck sets how fee payers from /supported are checked against
+// the facilitator's registry entry.
+func WithFeePayerCheck(check FeePayerCheck) ClientOption {
+	return func(c *FacilitatorClient) {
+		c.feePayerCheck = check
+	}
 }
 
-// findFeePayerInKinds searches for a fee payer in the list of supported kinds.
-func findFeePayerInKinds(kinds []Kind, network string) (string, bool) {
-	target := strings.ToLower(strings.TrimSpace(network))
-	for _, k := range kinds {
-		if strings.ToLower(k.Network) == target {
-			if k.Extra != nil && strings.TrimSpace(k.Extra.FeePayer) != "" {
-				return k.Extra.FeePayer, true
-			}
-			// Network found but no fee payer
-			return "", false
+// WithRegistryFacilitator sets the registry entry fee payers are checked
+// against. By default it is looked up by the client's base URL.
+func WithRegistryFacilitator(f *facilitators.Facilitator) ClientOption {
+	return func(c *FacilitatorClient) {
+		c.registry = f
+	}
+}
+
+// WithAuthorizer authenticates every request attempt, e.g. with
+// cdp.NewAuthorizer for the Coinbase facilitator.
+func WithAuthorizer(authorizer facilitator.RequestAuthorizer) ClientOption {
+	return func(c *FacilitatorClient) {
+		c.authorizer = authorizer
+	}
+}
+
+// StatusError is returned when the facilitator responds with a non-200 status.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	// Body is the response body, truncated to 4 KiB.
+	Body string
+	// Reason is the error reason decoded from Body, if any.
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *StatusError) Error() string {
+	if e.Reason != "" {
+		return "unexpected status " + e.Status + ": " + e.Reason
+	}
+	return "unexpected status " + e.Status
+}
+
+// errorBody is the union of the error fields used by facilitator implementations.
+type errorBody struct {
+	InvalidReason string `json:"invalidReason"`
+	ErrorReason   string `json:"errorReason"`
+	Error         string `json:"error"`
+	Message       string `json:"message"`
+	Payer         string `json:"payer"`
+	Transaction   string `json:"transaction"`
+	Network       string `json:"network"`
+}
+
+// reason returns the most specific reason in the body.
+func (b *errorBody) reason() string {
+	for _, reason := range []string{b.InvalidReason, b.ErrorReason, b.Error, b.Message} {
+		if reason != "" {
+			return reason
 		}
 	}
-	// Network not found in supported list
-	return "", false
+	return ""
 }
 
-// fetchFeePayer fetches fee payer from facilitator's /supported endpoint.
-func (c *FacilitatorClient) fetchFeePayer(
-	ctx context.Context,
-	network string,
-) (string, bool, error) {
-	u, err := url.Parse(c.baseURL)
-	if err != nil {
-		return "", false, fmt.Errorf("parse facilitator URL: %w", err)
+// readStatusError reads a non-200 response into a StatusError, keeping the
+// decoded body so callers can recover a verify or settle result from it.
+func readStatusError(resp *http.Response) (*StatusError, errorBody) {
+	statusErr := &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	var body errorBody
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil || len(raw) == 0 {
+		return statusErr, body
 	}
+	statusErr.Body = string(raw)
+	if sonic.Unmarshal(raw, &body) == nil {
+		statusErr.Reason = body.reason()
+	}
+	return statusErr, body
+}
 
-	u.Path = path.Join(u.Path, "supported")
+// TransportError is returned when a facilitator request fails without a response.
+type TransportError struct {
+	Err error
+	// RequestSent reports whether the request was fully written to the connection.
+	// If false, the facilitator cannot have acted on it.
+	RequestSent bool
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-	if err != nil {
-		return "", false, fmt.Errorf("create request: %w", err)
+// Error implements the error interface.
+func (e *TransportError) Error() string {
+	return "http request: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// NewFacilitatorClient creates a new facilitator client. logger may be nil;
+// use WithSlog for structured logs.
+func NewFacilitatorClient(
+	baseURL string,
+	cache *FeePayerCache,
+	logger Logger,
+	opts ...ClientOption,
+) *FacilitatorClient {
+	c := &FacilitatorClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{},
+		cache:      cache,
+		metrics:    NopMetrics{},
+		tracer:     otel.GetTracerProvider().Tracer(TracerName),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.logger = newLogger(c.slog, logger, c.logPayloads).With("facilitator", c.baseURL)
+	if c.transport != nil {
+		client := *c.httpClient
+		client.Transport = c.transport
+		c.httpClient = &client
+	}
+	c.timeouts = timeoutsWithDefaults(c.timeouts)
+	c.retry = c.retry.withDefaults()
+	if c.breakerConfig != nil {
+		c.breaker = newCircuitBreaker(c.baseURL, *c.breakerConfig, c.logger)
+	}
+	if c.registry == nil {
+		c.registry = facilitators.FindByURL(c.baseURL)
+	}
+	c.feePayers = &feePayerResolver{
+		facilitator: c,
+		cache:       cache,
+		logger:      c.logger,
+		registry:    c.registry,
+		check:       c.feePayerCheck,
+	}
+	return c
+}
+
+// Unavailable reports whether requests currently fail fast because the
+// circuit breaker is open, and how long until it probes the facilitator again.
+func (c *FacilitatorClient) Unavailable() (time.Duration, bool) {
+	if c.breaker == nil || c.breaker.State() != CircuitOpen {
+		return 0, false
 	}
-	req.Header.Set("Accept", "application/json")
+	return c.breaker.RetryAfter(), true
+}
+
+var _ facilitator.Interface = (*FacilitatorClient)(nil)
+
+// do sends a request, recording whether it was written before any transport failure.
+func (c *FacilitatorClient) do(req *http.Request) (*http.Response, error) {
+	var sent atomic.Bool
+	trace := &httptrace.ClientTrace{
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			if info.Err == nil {
+				sent.Store(true)
+			}
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", false, fmt.Errorf("http request: %w", err)
+		return nil, &TransportError{Err: err, RequestSent: sent.Load()}
 	}
-	defer func() {
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			c.logger.Errorf("[x402] Error closing response body: %v", closeErr)
-		}
-	}()
+	return resp, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", false, fmt.Errorf("unexpected status %s", resp.Status)
-	}
+// GetFeePayer retrieves the fee payer for a given network, rotating through
+// them if the facilitator has several. Uses cache if available, otherwise
+// fetches from facilitator.
+func (c *FacilitatorClient) GetFeePayer(ctx context.Context, network string) (string, error) {
+	return c.feePayers.feePayer(ctx, network)
+}
 
-	var data SupportedResponse
-	if err := sonic.ConfigDefault.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return "", false, fmt.Errorf("decode json: %w", err)
-	}
+// RefreshFeePayers fetches the facilitator's fee payers for networks into the cache.
+func (c *FacilitatorClient) RefreshFeePayers(ctx context.Context, networks ...string) error {
+	return c.feePayers.refresh(ctx, networks...)
+}
+
+// hasFeePayer reports whether feePayer is one of the facilitator's fee payers for a network.
+func (c *FacilitatorClient) hasFeePayer(ctx context.Context, network, feePayer string) bool {
+	return c.feePayers.hasFeePayer(ctx, network, feePayer)
+}
 
-	// Find matching network and fee payer
-	feePayer, found := findFeePayerInKinds(data.Kinds, network)
-	return feePayer, found, nil
+// isClientError reports whether status is a 4xx status code.
+func isClientError(status int) bool {
+	return status >= http.StatusBadRequest && status < http.StatusInternalServerError
 }
 
 // extractPayerFromPayment attempts to extract the payer address from payment payload.
@@ -161,108 +349,189 @@ func extractPayerFromPayment(payment x402.PaymentPayload) string {
 	return ""
 }
 
-// GetSupported fetches all supported payment kinds from the facilitator.
-func (c *FacilitatorClient) GetSupported(ctx context.Context) ([]Kind, error) {
+// request describes a facilitator call.
+type request struct {
+	name      string
+	method    string
+	endpoint  string
+	body      []byte
+	timeout   time.Duration
+	retryable func(error) bool
+}
+
+// call sends a request to the facilitator, retrying failed attempts that
+// r.retryable allows. handle decodes each response and returns the attempt's error.
+func (c *FacilitatorClient) call(ctx context.Context, r request, handle func(*http.Response) error) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeouts.RequestTimeout)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		err := c.guardedAttempt(ctx, r, handle)
+		if errors.Is(err, ErrCircuitOpen) {
+			return err
+		}
+		if err == nil || attempt >= c.retry.MaxAttempts || !r.retryable(err) {
+			return err
+		}
+
+		delay := c.retry.backoff(attempt - 1)
+		c.logger.Warn("Facilitator request failed, retrying",
+			"request", r.name, "attempt", attempt, "delay", delay, "error", err)
+		if sleep(ctx, delay) != nil {
+			return err
+		}
+	}
+}
+
+// guardedAttempt sends a single request through the circuit breaker, if any.
+func (c *FacilitatorClient) guardedAttempt(ctx context.Context, r request, handle func(*http.Response) error) error {
+	if c.breaker == nil {
+		return c.timedAttempt(ctx, r, handle)
+	}
+	if err := c.breaker.Allow(); err != nil {
+		return err
+	}
+	err := c.timedAttempt(ctx, r, handle)
+	c.breaker.Record(err)
+	return err
+}
+
+// timedAttempt sends a single request in its own span and reports its
+// duration to metrics.
+func (c *FacilitatorClient) timedAttempt(ctx context.Context, r request, handle func(*http.Response) error) error {
+	ctx, span := c.tracer.Start(ctx, "x402.facilitator."+r.endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(AttrFacilitator.String(c.baseURL), AttrEndpoint.String(r.endpoint)))
+	start := time.Now()
+	err := c.attempt(ctx, r, handle)
+	c.metrics.FacilitatorRequest(c.baseURL, r.endpoint, time.Since(start), err)
+	endSpan(span, err)
+	return err
+}
+
+// attempt sends a single request with its own timeout.
+func (c *FacilitatorClient) attempt(ctx context.Context, r request, handle func(*http.Response) error) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
 	u, err := url.Parse(c.baseURL)
 	if err != nil {
-		return nil, fmt.Errorf("parse facilitator URL: %w", err)
+		return fmt.Errorf("parse facilitator URL: %w", err)
 	}
+	u.Path = path.Join(u.Path, r.endpoint)
 
-	u.Path = path.Join(u.Path, "supported")
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, u.String(), body)
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return fmt.Errorf("create request: %w", err)
+	}
+	if r.body != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
 	req.Header.Set("Accept", "application/json")
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if c.authorizer != nil {
+		if err := c.authorizer.Authorize(req); err != nil {
+			return fmt.Errorf("authorize request: %w", err)
+		}
+	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("http request: %w", err)
+		return err
 	}
+	setUsedFacilitator(ctx, c.baseURL)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
 	defer func() {
 		if closeErr := resp.Body.Close(); closeErr != nil {
-			c.logger.Errorf("[x402] Error closing response body: %v", closeErr)
+			c.logger.Error("Error closing response body", "error", closeErr)
 		}
 	}()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status %s", resp.Status)
-	}
-
-	var data SupportedResponse
-	if err := sonic.ConfigDefault.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return nil, fmt.Errorf("decode json: %w", err)
-	}
-
-	return data.Kinds, nil
+	return handle(resp)
 }
 
-// VerifyResult contains the results of payment verification.
-type VerifyResult struct {
-	IsValid       bool
-	InvalidReason string
-	Payer         string
+// Supported fetches all supported payment kinds from the facilitator, or
+// from the supported cache if one is configured.
+func (c *FacilitatorClient) Supported(ctx context.Context) (*facilitator.SupportedResponse, error) {
+	if c.supported != nil {
+		return c.supported.Get(ctx, c.baseURL, c.fetchSupported)
+	}
+	return c.fetchSupported(ctx)
 }
 
-// buildVerifyRequest creates the HTTP request for payment verification.
-func (c *FacilitatorClient) buildVerifyRequest(
-	ctx context.Context,
-	payment x402.PaymentPayload,
-	requirement x402.PaymentRequirement,
-) (*http.Request, error) {
-	u, err := url.Parse(c.baseURL)
+// fetchSupported requests /supported from the facilitator.
+func (c *FacilitatorClient) fetchSupported(ctx context.Context) (*facilitator.SupportedResponse, error) {
+	var data facilitator.SupportedResponse
+	err := c.call(ctx, request{
+		name:      "Supported",
+		method:    http.MethodGet,
+		endpoint:  EndpointSupported,
+		timeout:   c.timeouts.VerifyTimeout,
+		retryable: isRetryable,
+	}, func(resp *http.Response) error {
+		if resp.StatusCode != http.StatusOK {
+			statusErr, _ := readStatusError(resp)
+			return statusErr
+		}
+		if err := sonic.ConfigDefault.NewDecoder(resp.Body).Decode(&data); err != nil {
+			return fmt.Errorf("decode json: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("parse facilitator URL: %w", err)
+		return nil, err
 	}
-	u.Path = path.Join(u.Path, "verify")
+	return &data, nil
+}
 
-	reqBody := map[string]any{
+// marshalRequest encodes the body of a verify or settle request.
+func marshalRequest(payment x402.PaymentPayload, requirement x402.PaymentRequirement) ([]byte, error) {
+	// Create request body matching facilitator API spec
+	body, err := sonic.Marshal(map[string]any{
 		"x402Version":         1,
 		"paymentPayload":      payment,
 		"paymentRequirements": requirement,
-	}
-
-	jsonBytes, err := sonic.Marshal(reqBody)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
-	c.logger.Printf("[x402] Verify request: %s", string(jsonBytes))
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(string(jsonBytes)))
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	return req, nil
+	return body, nil
 }
 
 // parseVerifyResponse decodes and processes the verification response.
-func (c *FacilitatorClient) parseVerifyResponse(resp *http.Response, payment x402.PaymentPayload) (bool, string, string, error) {
+// A 4xx response carrying an invalidReason is returned as an invalid result
+// rather than an error, since the facilitator did judge the payment.
+func (c *FacilitatorClient) parseVerifyResponse(
+	resp *http.Response,
+	payment x402.PaymentPayload,
+) (*facilitator.VerifyResponse, error) {
 	if resp.StatusCode != http.StatusOK {
-		c.logger.Printf("[x402] Verify failed: status=%s", resp.Status)
-		return false, "", "", fmt.Errorf("unexpected status %s", resp.Status)
+		statusErr, body := readStatusError(resp)
+		c.logger.Warn("Verify failed", "status", resp.StatusCode, "body", statusErr.Body)
+		if isClientError(resp.StatusCode) && body.InvalidReason != "" {
+			return &facilitator.VerifyResponse{InvalidReason: body.InvalidReason, Payer: body.Payer}, nil
+		}
+		return nil, statusErr
 	}
 
-	var result struct {
-		IsValid       bool   `json:"isValid"`
-		InvalidReason string `json:"invalidReason,omitempty"`
-		Payer         string `json:"payer"`
-	}
+	var result facilitator.VerifyResponse
 	if err := sonic.ConfigDefault.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return false, "", "", fmt.Errorf("decode json: %w", err)
+		return nil, fmt.Errorf("decode json: %w", err)
 	}
 
-	c.logger.Printf("[x402] Verify response: isValid=%v payer=%s invalidReason=%s",
-		result.IsValid, result.Payer, result.InvalidReason)
+	c.logger.Debug("Verify response",
+		"isValid", result.IsValid, "payer", result.Payer, "invalidReason", result.InvalidReason)
 
 	if result.Payer == "" && result.IsValid {
 		result.Payer = extractPayerFromPayment(payment)
-		c.logger.Printf("[x402] Payer fallback: %s", result.Payer)
+		c.logger.Debug("Payer fallback", "payer", result.Payer)
 	}
 
-	return result.IsValid, result.InvalidReason, result.Payer, nil
+	return &result, nil
 }
 
 // Verify verifies a payment with the facilitator.
@@ -270,88 +539,88 @@ func (c *FacilitatorClient) Verify(
 	ctx context.Context,
 	payment x402.PaymentPayload,
 	requirement x402.PaymentRequirement,
-) (bool, string, string, error) {
-	req, err := c.buildVerifyRequest(ctx, payment, requirement)
+) (*facilitator.VerifyResponse, error) {
+	body, err := marshalRequest(payment, requirement)
 	if err != nil {
-		return false, "", "", err
-	}
-
-	resp, err := c.httpClient.Do(req)
+		return nil, err
+	}
+	c.logger.Debug("Verify request", "body", jsonLog(body))
+
+	var result *facilitator.VerifyResponse
+	err = c.call(ctx, request{
+		name:      "Verify",
+		method:    http.MethodPost,
+		endpoint:  EndpointVerify,
+		body:      body,
+		timeout:   c.timeouts.VerifyTimeout,
+		retryable: isRetryable,
+	}, func(resp *http.Response) error {
+		var parseErr error
+		result, parseErr = c.parseVerifyResponse(resp, payment)
+		return parseErr
+	})
 	if err != nil {
-		return false, "", "", fmt.Errorf("http request: %w", err)
+		return nil, err
 	}
-	defer func() {
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			c.logger.Errorf("[x402] Error closing response body: %v", closeErr)
+	return result, nil
+}
+
+// parseSettleResponse decodes the settlement response.
+// A 4xx response carrying an errorReason is returned as a failed settlement.
+func (c *FacilitatorClient) parseSettleResponse(resp *http.Response) (*x402.SettlementResponse, error) {
+	if resp.StatusCode != http.StatusOK {
+		statusErr, body := readStatusError(resp)
+		c.logger.Warn("Settle failed", "status", resp.StatusCode, "body", statusErr.Body)
+		if isClientError(resp.StatusCode) && body.ErrorReason != "" {
+			return &x402.SettlementResponse{
+				ErrorReason: body.ErrorReason,
+				Transaction: body.Transaction,
+				Network:     body.Network,
+				Payer:       body.Payer,
+			}, nil
 		}
-	}()
+		return nil, statusErr
+	}
 
-	return c.parseVerifyResponse(resp, payment)
+	var settlement x402.SettlementResponse
+	if err := sonic.ConfigDefault.NewDecoder(resp.Body).Decode(&settlement); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+
+	c.logger.Debug("Settle response", "tx", settlement.Transaction, "errorReason", settlement.ErrorReason)
+
+	return &settlement, nil
 }
 
 // Settle settles a payment with the facilitator.
+// Failed attempts are only retried if the facilitator cannot have acted on them.
 func (c *FacilitatorClient) Settle(
 	ctx context.Context,
 	payment x402.PaymentPayload,
 	requirement x402.PaymentRequirement,
 ) (*x402.SettlementResponse, error) {
-	u, err := url.Parse(c.baseURL)
+	body, err := marshalRequest(payment, requirement)
 	if err != nil {
-		return nil, fmt.Errorf("parse facilitator URL: %w", err)
-	}
-
-	u.Path = path.Join(u.Path, "settle")
-
-	// Create request body matching facilitator API spec
-	reqBody := map[string]any{
-		"x402Version":         1,
-		"paymentPayload":      payment,
-		"paymentRequirements": requirement,
-	}
-
-	jsonBytes, err := sonic.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("marshal request: %w", err)
-	}
-
-	// LOG: Debug request being sent
-	c.logger.Printf("[x402] Settle request: %s", string(jsonBytes))
-
-	req, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodPost,
-		u.String(),
-		strings.NewReader(string(jsonBytes)),
-	)
+		return nil, err
+	}
+
+	c.logger.Debug("Settle request", "body", jsonLog(body))
+
+	var settlement *x402.SettlementResponse
+	err = c.call(ctx, request{
+		name:      "Settle",
+		method:    http.MethodPost,
+		endpoint:  EndpointSettle,
+		body:      body,
+		timeout:   c.timeouts.SettleTimeout,
+		retryable: isSettleRetryable,
+	}, func(resp *http.Response) error {
+		var parseErr error
+		settlement, parseErr = c.parseSettleResponse(resp)
+		return parseErr
+	})
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("http request: %w", err)
-	}
-	defer func() {
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			c.logger.Errorf("[x402] Error closing response body: %v", closeErr)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		// LOG: Debug error response
-		c.logger.Printf("[x402] Settle failed: status=%s", resp.Status)
-		return nil, fmt.Errorf("unexpected status %s", resp.Status)
-	}
-
-	var settlement x402.SettlementResponse
-	if err := sonic.ConfigDefault.NewDecoder(resp.Body).Decode(&settlement); err != nil {
-		return nil, fmt.Errorf("decode json: %w", err)
-	}
-
-	// LOG: Debug response received
-	c.logger.Printf("[x402] Settle response: txHash=%s", settlement.Transaction)
-
-	return &settlement, nil
+	return settlement, nil
 }
@@ -0,0 +1,226 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/pkg/facilitator"
+)
+
+func newStatusFacilitator(t *testing.T, status int, body string) *FacilitatorClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return NewFacilitatorClient(server.URL, NewFeePayerCache(0), nil, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+}
+
+func TestFacilitatorClient_VerifyErrorResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantReason  string
+		wantErrBody string
+		wantErrWhy  string
+	}{
+		{
+			"invalid result", http.StatusBadRequest,
+			`{"isValid":false,"invalidReason":"insufficient_funds"}`, "insufficient_funds", "", "",
+		},
+		{
+			"error body", http.StatusBadRequest,
+			`{"error":"invalid_network"}`, "", `{"error":"invalid_network"}`, "invalid_network",
+		},
+		{"plain text", http.StatusBadGateway, "upstream down", "", "upstream down", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newStatusFacilitator(t, tt.status, tt.body)
+			resp, err := client.Verify(context.Background(), x402.PaymentPayload{}, poolRequirement)
+
+			if tt.wantReason != "" {
+				if err != nil || resp.IsValid || resp.InvalidReason != tt.wantReason {
+					t.Fatalf("Verify() = %+v, %v; want invalid with reason %s", resp, err, tt.wantReason)
+				}
+				return
+			}
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) {
+				t.Fatalf("Verify() error = %v, want *StatusError", err)
+			}
+			if statusErr.StatusCode != tt.status || statusErr.Body != tt.wantErrBody || statusErr.Reason != tt.wantErrWhy {
+				t.Errorf("StatusError = %+v, want status %d body %q reason %q",
+					statusErr, tt.status, tt.wantErrBody, tt.wantErrWhy)
+			}
+		})
+	}
+}
+
+func TestFacilitatorClient_SettleErrorResponse(t *testing.T) {
+	client := newStatusFacilitator(t, http.StatusBadRequest,
+		`{"success":false,"errorReason":"invalid_exact_evm_payload_signature","network":"base"}`)
+
+	resp, err := client.Settle(context.Background(), x402.PaymentPayload{}, poolRequirement)
+	if err != nil {
+		t.Fatalf("Settle() error = %v", err)
+	}
+	if resp.Success || resp.ErrorReason != "invalid_exact_evm_payload_signature" || resp.Network != "base" {
+		t.Errorf("Settle() = %+v", resp)
+	}
+}
+
+// flakyFacilitator fails the first failures requests with fail, then succeeds.
+func flakyFacilitator(t *testing.T, failures int32, fail func(w http.ResponseWriter)) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			fail(w)
+			return
+		}
+		if r.URL.Path == "/verify" {
+			_, _ = w.Write([]byte(`{"isValid":true,"payer":"payer"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true,"transaction":"tx","network":"base","payer":"payer"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// resetConnection closes the connection without responding.
+func resetConnection(w http.ResponseWriter) {
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err == nil {
+		_ = conn.Close()
+	}
+}
+
+func TestFacilitatorClient_Retry(t *testing.T) {
+	unavailable := func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) }
+	serverError := func(w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) }
+
+	tests := []struct {
+		name         string
+		settle       bool
+		fail         func(w http.ResponseWriter)
+		wantErr      bool
+		wantRequests int32
+	}{
+		{"verify retries connection reset", false, resetConnection, false, 2},
+		{"verify retries server error", false, serverError, false, 2},
+		{"settle retries unavailable", true, unavailable, false, 2},
+		{"settle does not retry reset after sending", true, resetConnection, true, 1},
+		{"settle does not retry server error", true, serverError, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := flakyFacilitator(t, 1, tt.fail)
+			client := NewFacilitatorClient(server.URL, NewFeePayerCache(0), nil,
+				WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}))
+
+			var err error
+			if tt.settle {
+				_, err = client.Settle(context.Background(), x402.PaymentPayload{}, poolRequirement)
+			} else {
+				_, err = client.Verify(context.Background(), x402.PaymentPayload{}, poolRequirement)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestFacilitatorClient_Authorizer(t *testing.T) {
+	server, _ := flakyFacilitator(t, 1, resetConnection)
+
+	var tokens []string
+	authorizer := facilitator.AuthorizerFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer token-%d", len(tokens)))
+		return nil
+	})
+	var trips atomic.Int32
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		trips.Add(1)
+		tokens = append(tokens, req.Header.Get("Authorization"))
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	client := NewFacilitatorClient(server.URL, NewFeePayerCache(0), nil,
+		WithAuthorizer(authorizer),
+		WithTransport(transport),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}))
+
+	if _, err := client.Verify(context.Background(), x402.PaymentPayload{}, poolRequirement); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if trips.Load() != 2 || len(tokens) != 2 || tokens[0] != "Bearer token-0" || tokens[1] != "Bearer token-1" {
+		t.Errorf("tokens = %v, want a fresh token for each of 2 attempts", tokens)
+	}
+
+	failing := NewFacilitatorClient(server.URL, NewFeePayerCache(0), nil,
+		WithAuthorizer(facilitator.AuthorizerFunc(func(*http.Request) error { return errors.New("no key") })))
+	if _, err := failing.Verify(context.Background(), x402.PaymentPayload{}, poolRequirement); err == nil {
+		t.Error("Verify() succeeded, want authorization error")
+	}
+}
+
+func TestFacilitatorClient_VerifyTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	client := NewFacilitatorClient(server.URL, NewFeePayerCache(0), nil,
+		WithTimeouts(x402.TimeoutConfig{VerifyTimeout: 20 * time.Millisecond}),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+
+	start := time.Now()
+	_, err := client.Verify(context.Background(), x402.PaymentPayload{}, poolRequirement)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Verify() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Verify() took %v, want the 20ms attempt timeout to apply", elapsed)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}.withDefaults()
+	for retry, limit := range []time.Duration{10, 20, 40, 50, 50} {
+		for range 20 {
+			if d := policy.backoff(retry); d < 0 || d > limit*time.Millisecond {
+				t.Fatalf("backoff(%d) = %v, want within [0, %v]", retry, d, limit*time.Millisecond)
+			}
+		}
+	}
+	if d := policy.backoff(100); d > policy.MaxBackoff {
+		t.Errorf("backoff(100) = %v, want at most %v", d, policy.MaxBackoff)
+	}
+}
@@ -0,0 +1,227 @@
+package x402
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/internal/svm"
+	"github.com/dexfra-fun/x402-go/pkg/facilitator"
+	"github.com/dexfra-fun/x402-go/pkg/facilitators"
+)
+
+// FeePayerCheck controls how the fee payers a facilitator reports in /supported
+// are checked against the addresses listed for it in pkg/facilitators. A fee
+// payer missing from the registry may mean the facilitator URL was hijacked.
+// Facilitators outside the registry, and networks it lists no addresses for,
+// are not checked.
+type FeePayerCheck int
+
+const (
+	// FeePayerCheckWarn logs unregistered fee payers but still uses them.
+	FeePayerCheckWarn FeePayerCheck = iota
+	// FeePayerCheckEnforce refuses unregistered fee payers.
+	FeePayerCheckEnforce
+	// FeePayerCheckOff disables the check.
+	FeePayerCheckOff
+)
+
+// feePayerResolver caches a facilitator's fee payers from its supported kinds,
+// checked against the facilitator's registry entry.
+type feePayerResolver struct {
+	facilitator facilitator.Interface
+	cache       *FeePayerCache
+	logger      *slog.Logger
+	registry    *facilitators.Facilitator // nil if the facilitator is not registered
+	check       FeePayerCheck
+}
+
+// feePayer returns the next fee payer for a network, fetching the facilitator's
+// supported kinds on a cache miss.
+func (r *feePayerResolver) feePayer(ctx context.Context, network string) (string, error) {
+	if feePayer, found := r.cache.Get(network); found {
+		r.logger.Debug("Fee payer cache hit", "network", network, "feePayer", feePayer)
+		cacheEvent(ctx, EventCacheHit, "fee_payer", network)
+		return feePayer, nil
+	}
+
+	cacheEvent(ctx, EventCacheMiss, "fee_payer", network)
+	r.logger.Debug("Fee payer cache miss, fetching from facilitator", "network", network)
+	if err := r.refresh(ctx, network); err != nil {
+		return "", err
+	}
+	feePayer, _ := r.cache.Get(network)
+	return feePayer, nil
+}
+
+// hasFeePayer reports whether feePayer is one of the facilitator's fee payers for a network.
+func (r *feePayerResolver) hasFeePayer(ctx context.Context, network, feePayer string) bool {
+	feePayers, found := r.cache.GetAll(network)
+	if !found {
+		if err := r.refresh(ctx, network); err != nil {
+			return false
+		}
+		feePayers, _ = r.cache.GetAll(network)
+	}
+	return slices.Contains(feePayers, feePayer)
+}
+
+// refresh fetches the facilitator's supported kinds once and caches the
+// checked fee payers of each network.
+func (r *feePayerResolver) refresh(ctx context.Context, networks ...string) error {
+	supported, err := r.facilitator.Supported(ctx)
+	if err != nil {
+		return fmt.Errorf("fetch fee payer: %w", err)
+	}
+
+	var errs []error
+	for _, network := range networks {
+		feePayers, err := r.checked(network, supported.FeePayers(network))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r.cache.SetAll(network, feePayers)
+		r.logger.Debug("Fee payer cached", "network", network, "feePayers", feePayers)
+	}
+	return errors.Join(errs...)
+}
+
+// checked returns the fee payers that pass the registry check.
+func (r *feePayerResolver) checked(network string, feePayers []string) ([]string, error) {
+	if len(feePayers) == 0 {
+		return nil, ErrFeePayerNotFound
+	}
+	registryNetwork := facilitators.Network(network)
+	if r.registry == nil || r.check == FeePayerCheckOff || len(r.registry.FeePayers(registryNetwork)) == 0 {
+		return feePayers, nil
+	}
+
+	valid := make([]string, 0, len(feePayers))
+	for _, feePayer := range feePayers {
+		if r.registry.HasFeePayer(registryNetwork, feePayer) {
+			valid = append(valid, feePayer)
+			continue
+		}
+		r.logger.Warn("Fee payer is not registered for facilitator",
+			"feePayer", feePayer, "network", network, "registryID", r.registry.ID)
+		if r.check == FeePayerCheckWarn {
+			valid = append(valid, feePayer)
+		}
+	}
+	if len(valid) == 0 {
+		return nil, fmt.Errorf("%w: %s on %s", ErrFeePayerMismatch, r.registry.ID, network)
+	}
+	return valid, nil
+}
+
+// feePayerRefresher is implemented by facilitators that cache fee payers themselves.
+type feePayerRefresher interface {
+	RefreshFeePayers(ctx context.Context, networks ...string) error
+}
+
+// feePayerSet is implemented by facilitators that know all of their fee payers.
+type feePayerSet interface {
+	hasFeePayer(ctx context.Context, network, feePayer string) bool
+}
+
+// RefreshFeePayers fetches the fee payers of all configured Solana networks
+// from the facilitator. It runs in the background every FeePayerRefreshInterval,
+// and may be called at startup to fill the cache before serving requests.
+func (m *Middleware) RefreshFeePayers(ctx context.Context) error {
+	networks := m.feePayerNetworks()
+	if len(networks) == 0 {
+		return nil
+	}
+	if refresher, ok := m.facilitator.(feePayerRefresher); ok {
+		return refresher.RefreshFeePayers(ctx, networks...)
+	}
+	return m.feePayers.refresh(ctx, networks...)
+}
+
+// feePayerNetworks returns the distinct networks whose requirements carry a fee payer.
+func (m *Middleware) feePayerNetworks() []string {
+	var networks []string
+	for _, option := range m.options {
+		if !option.chain.IsEVM() && !slices.Contains(networks, option.chain.NetworkID) {
+			networks = append(networks, option.chain.NetworkID)
+		}
+	}
+	return networks
+}
+
+// startFeePayerRefresh refreshes fee payers now and then every interval until
+// Close is called, so requests are served from the cache.
+func (m *Middleware) startFeePayerRefresh(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	m.wg.Go(func() {
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(m.ctx, interval)
+			if err := m.RefreshFeePayers(ctx); err != nil && m.ctx.Err() == nil {
+				m.config.Slog.Error("Fee payer refresh failed", "error", err)
+			}
+			cancel()
+			m.cache.CleanupExpired()
+
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// BindFeePayer points a Solana requirement at the fee payer the payment's
+// transaction was built for. A facilitator with several fee payers may have
+// quoted a different one in the 402 response than the current requirements
+// carry; the transaction's fee payer is kept if the facilitator still uses it.
+func (m *Middleware) BindFeePayer(
+	ctx context.Context,
+	payment x402.PaymentPayload,
+	requirement *x402.PaymentRequirement,
+) {
+	current, _ := requirement.Extra["feePayer"].(string)
+	if current == "" {
+		return
+	}
+	feePayer, ok := transactionFeePayer(payment)
+	if !ok || feePayer == current || !m.hasFeePayer(ctx, requirement.Network, feePayer) {
+		return
+	}
+
+	requirement.Extra = maps.Clone(requirement.Extra)
+	requirement.Extra["feePayer"] = feePayer
+}
+
+// hasFeePayer reports whether the facilitator uses feePayer on a network.
+func (m *Middleware) hasFeePayer(ctx context.Context, network, feePayer string) bool {
+	if set, ok := m.facilitator.(feePayerSet); ok {
+		return set.hasFeePayer(ctx, network, feePayer)
+	}
+	return m.feePayers.hasFeePayer(ctx, network, feePayer)
+}
+
+// transactionFeePayer returns the fee payer of a Solana payment's transaction.
+func transactionFeePayer(payment x402.PaymentPayload) (string, bool) {
+	payload, err := payment.DecodeSVMPayload()
+	if err != nil {
+		return "", false
+	}
+	raw, err := base64.StdEncoding.DecodeString(payload.Transaction)
+	if err != nil {
+		return "", false
+	}
+	var tx svm.Transaction
+	if err := tx.UnmarshalBinary(raw); err != nil || len(tx.Message.AccountKeys) == 0 {
+		return "", false
+	}
+	return tx.Message.AccountKeys[0].String(), true
+}
@@ -0,0 +1,149 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/pkg/facilitator"
+	"github.com/dexfra-fun/x402-go/pkg/facilitators"
+	"github.com/shopspring/decimal"
+)
+
+// supportedFacilitator is an in-process facilitator that only lists fee payers.
+type supportedFacilitator struct {
+	facilitator.Interface
+	feePayers []string
+}
+
+func (f *supportedFacilitator) Supported(context.Context) (*facilitator.SupportedResponse, error) {
+	supported := &facilitator.SupportedResponse{}
+	for _, feePayer := range f.feePayers {
+		supported.Kinds = append(supported.Kinds, facilitator.SupportedKind{
+			X402Version: 1,
+			Scheme:      "exact",
+			Network:     "solana",
+			Extra:       map[string]any{"feePayer": feePayer},
+		})
+	}
+	return supported, nil
+}
+
+func TestFeePayerResolver_Check(t *testing.T) {
+	registered := facilitators.Coinbase.FeePayers(facilitators.NetworkSolana)[0]
+	const unknown = "11111111111111111111111111111112"
+
+	tests := []struct {
+		name      string
+		check     FeePayerCheck
+		registry  *facilitators.Facilitator
+		network   string
+		feePayers []string
+		want      []string
+		wantErr   error
+	}{
+		{"registered", FeePayerCheckEnforce, facilitators.Coinbase, "solana", []string{registered}, []string{registered}, nil},
+		{"warn keeps unknown", FeePayerCheckWarn, facilitators.Coinbase, "solana",
+			[]string{unknown, registered}, []string{unknown, registered}, nil},
+		{"enforce drops unknown", FeePayerCheckEnforce, facilitators.Coinbase, "solana",
+			[]string{unknown, registered}, []string{registered}, nil},
+		{"enforce refuses mismatch", FeePayerCheckEnforce, facilitators.Coinbase, "solana",
+			[]string{unknown}, nil, ErrFeePayerMismatch},
+		{"off", FeePayerCheckOff, facilitators.Coinbase, "solana", []string{unknown}, []string{unknown}, nil},
+		{"unregistered facilitator", FeePayerCheckEnforce, nil, "solana", []string{unknown}, []string{unknown}, nil},
+		{"no registered addresses", FeePayerCheckEnforce, facilitators.Coinbase, "solana-devnet",
+			[]string{unknown}, []string{unknown}, nil},
+		{"not found", FeePayerCheckEnforce, facilitators.Coinbase, "solana", nil, nil, ErrFeePayerNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &feePayerResolver{registry: tt.registry, check: tt.check, logger: slog.New(slog.DiscardHandler)}
+			got, err := resolver.checked(tt.network, tt.feePayers)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("checked() error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("checked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeePayerResolver_Rotation(t *testing.T) {
+	resolver := &feePayerResolver{
+		facilitator: &supportedFacilitator{feePayers: []string{"payerA", "payerB", "payerA"}},
+		cache:       NewFeePayerCache(time.Minute),
+		logger:      slog.New(slog.DiscardHandler),
+	}
+
+	var got []string
+	for range 3 {
+		feePayer, err := resolver.feePayer(context.Background(), "solana")
+		if err != nil {
+			t.Fatalf("feePayer() error = %v", err)
+		}
+		got = append(got, feePayer)
+	}
+	if want := []string{"payerA", "payerB", "payerA"}; !slices.Equal(got, want) {
+		t.Errorf("fee payers = %v, want %v", got, want)
+	}
+	if !resolver.hasFeePayer(context.Background(), "solana", "payerB") ||
+		resolver.hasFeePayer(context.Background(), "solana", "payerC") {
+		t.Error("hasFeePayer() does not match the facilitator's fee payers")
+	}
+}
+
+func TestFacilitatorPool_CandidatesByFeePayer(t *testing.T) {
+	first := newFakeFacilitator(t, http.StatusOK, "payerA")
+	second := newFakeFacilitator(t, http.StatusOK, "payerB")
+	pool := newTestPool(t, FacilitatorEndpoint{URL: first.URL}, FacilitatorEndpoint{URL: second.URL})
+
+	requirement := x402.PaymentRequirement{Network: "solana", Extra: map[string]any{"feePayer": "payerB"}}
+	candidates := pool.candidates(context.Background(), requirement)
+	if len(candidates) != 1 || candidates[0].name != second.URL {
+		t.Errorf("candidates = %d, want only the facilitator using payerB", len(candidates))
+	}
+	if err := pool.RefreshFeePayers(context.Background(), "solana"); err != nil {
+		t.Errorf("RefreshFeePayers() error = %v", err)
+	}
+}
+
+// countingFacilitator counts /supported requests.
+type countingFacilitator struct {
+	supportedFacilitator
+	requests atomic.Int32
+}
+
+func (f *countingFacilitator) Supported(ctx context.Context) (*facilitator.SupportedResponse, error) {
+	f.requests.Add(1)
+	return f.supportedFacilitator.Supported(ctx)
+}
+
+func TestMiddleware_FeePayerRefreshOptIn(t *testing.T) {
+	for _, interval := range []time.Duration{0, time.Millisecond} {
+		f := &countingFacilitator{supportedFacilitator: supportedFacilitator{feePayers: []string{"FeePayer1"}}}
+		m, err := New(&Config{
+			RecipientAddress:        "9xQeWvG816bUx9EPjHmaT23yvVM2ZWbrrpZb9PusVFin",
+			Network:                 "solana",
+			Facilitator:             f,
+			PricingStrategy:         fixedPrice(decimal.RequireFromString("0.01")),
+			FeePayerRefreshInterval: interval,
+		})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+		m.Close()
+
+		if got := f.requests.Load(); (got > 0) != (interval > 0) {
+			t.Errorf("interval %v: %d background requests", interval, got)
+		}
+	}
+}
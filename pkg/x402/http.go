@@ -17,6 +17,17 @@ const (
 	// HeaderPaymentResponse is the HTTP header name for x402 settlement response.
 	// Uses canonical form for HTTP headers.
 	HeaderPaymentResponse = "X-Payment-Response"
+	// HeaderRequestID is the HTTP header identifying a request in payment records.
+	HeaderRequestID = "X-Request-ID"
+	// HeaderAccessToken is the HTTP header carrying pay-once access tokens,
+	// in responses to a paid request and in the requests using them.
+	HeaderAccessToken = "X-Payment-Token"
+	// HeaderCreditAuthorization is the HTTP header of a signed request paid
+	// from the signer's credit balance.
+	HeaderCreditAuthorization = "X-Credit-Authorization"
+	// HeaderCreditBalance is the HTTP header carrying the credit balance left
+	// after a request was paid from it or a top-up.
+	HeaderCreditBalance = "X-Credit-Balance"
 )
 
 // EncodePaymentRequirement encodes a payment requirement as a base64 JSON string.
@@ -95,12 +106,13 @@ func SetPaymentRequiredHeader(w http.ResponseWriter, req x402.PaymentRequirement
 }
 
 // WritePaymentRequired writes a 402 Payment Required response with proper x402 format.
-func WritePaymentRequired(w http.ResponseWriter, req x402.PaymentRequirement) error {
+// Each requirement is listed in `accepts` in the given order.
+func WritePaymentRequired(w http.ResponseWriter, reqs ...x402.PaymentRequirement) error {
 	// Create proper x402 response body according to specification
 	response := x402.PaymentRequirementsResponse{
 		X402Version: 1,
 		Error:       "Payment required for this resource",
-		Accepts:     []x402.PaymentRequirement{req},
+		Accepts:     reqs,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -108,6 +120,43 @@ func WritePaymentRequired(w http.ResponseWriter, req x402.PaymentRequirement) er
 	return sonic.ConfigDefault.NewEncoder(w).Encode(response)
 }
 
+// ErrorResponse is the JSON body of a failed payment request.
+// Error is a machine-readable x402.ErrorCode such as "INSUFFICIENT_FUNDS".
+type ErrorResponse struct {
+	X402Version int                       `json:"x402Version"`
+	Error       string                    `json:"error"`
+	Message     string                    `json:"message,omitempty"`
+	Reason      string                    `json:"reason,omitempty"`
+	Accepts     []x402.PaymentRequirement `json:"accepts,omitempty"`
+}
+
+// NewErrorResponse builds the response body for a payment error. Requirements
+// are listed in `accepts` so the client can retry with a new payment.
+func NewErrorResponse(perr *x402.PaymentError, reqs ...x402.PaymentRequirement) ErrorResponse {
+	response := ErrorResponse{
+		X402Version: 1,
+		Error:       string(perr.Code),
+		Message:     perr.Message,
+		Accepts:     reqs,
+	}
+	if reason, ok := perr.Details["reason"].(string); ok {
+		response.Reason = reason
+	}
+	return response
+}
+
+// WritePaymentError writes a JSON error response for a payment error.
+func WritePaymentError(
+	w http.ResponseWriter,
+	status int,
+	perr *x402.PaymentError,
+	reqs ...x402.PaymentRequirement,
+) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return sonic.ConfigDefault.NewEncoder(w).Encode(NewErrorResponse(perr, reqs...))
+}
+
 // EncodeSettlement encodes a settlement response as a base64 JSON string.
 func EncodeSettlement(settlement x402.SettlementResponse) (string, error) {
 	jsonBytes, err := sonic.Marshal(settlement)
@@ -0,0 +1,14 @@
+package x402
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// randomID returns a random 128-bit hex ID, used for event IDs, access token
+// IDs and credit authorization nonces.
+func randomID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
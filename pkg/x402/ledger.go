@@ -0,0 +1,105 @@
+package x402
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/shopspring/decimal"
+)
+
+// RecordKind is the payment step a PaymentRecord describes.
+type RecordKind string
+
+// Payment record kinds.
+const (
+	// RecordRequirementIssued is a 402 response to a request without payment.
+	RecordRequirementIssued RecordKind = "requirement_issued"
+	// RecordVerified is a payment attempt, accepted or rejected before settlement.
+	RecordVerified RecordKind = "verified"
+	// RecordSettled is a settlement, successful or not.
+	RecordSettled RecordKind = "settled"
+	// RecordCreditDebited is a request paid from a credit balance.
+	RecordCreditDebited RecordKind = "credit_debited"
+)
+
+// PaymentRecord is an entry of the payment ledger. Settled records with an
+// empty ErrorCode are revenue and can be reconciled against Transaction on chain.
+type PaymentRecord struct {
+	Kind      RecordKind `json:"kind"`
+	Time      time.Time  `json:"time"`
+	RequestID string     `json:"requestId,omitempty"`
+	Route     string     `json:"route"`
+	Method    string     `json:"method"`
+
+	// Price is in token units, e.g. 0.01 USDC; Amount is in atomic units.
+	Price    decimal.Decimal `json:"price"`
+	Amount   string          `json:"amount,omitempty"`
+	Asset    string          `json:"asset,omitempty"`
+	Currency string          `json:"currency,omitempty"`
+	Network  string          `json:"network,omitempty"`
+	Scheme   string          `json:"scheme,omitempty"`
+	PayTo    string          `json:"payTo,omitempty"`
+
+	Payer       string `json:"payer,omitempty"`
+	Transaction string `json:"transaction,omitempty"`
+	// Facilitator is the base URL of the facilitator that answered, or empty
+	// for custom facilitators.
+	Facilitator string `json:"facilitator,omitempty"`
+	// VerifiedAt is when the payment was verified (settled records only).
+	VerifiedAt time.Time `json:"verifiedAt,omitzero"`
+
+	// ErrorCode and ErrorMessage are empty if the step succeeded.
+	ErrorCode    x402.ErrorCode `json:"errorCode,omitempty"`
+	ErrorMessage string         `json:"errorMessage,omitempty"`
+}
+
+// Success reports whether the step succeeded.
+func (r PaymentRecord) Success() bool {
+	return r.ErrorCode == ""
+}
+
+// PaymentRecorder keeps a ledger of payment records (see Config.Recorder).
+// Record is called on the request path after each step; a failure is logged
+// and does not fail the request.
+type PaymentRecorder interface {
+	Record(ctx context.Context, record PaymentRecord) error
+}
+
+// usedFacilitatorKey is the context key of the facilitator tracked by TrackFacilitator.
+type usedFacilitatorKey struct{}
+
+// usedFacilitator is the base URL of the last facilitator that answered.
+type usedFacilitator struct {
+	mu      sync.Mutex
+	baseURL string
+}
+
+// TrackFacilitator returns a context in which FacilitatorClient remembers its
+// base URL after each response, for UsedFacilitator. Within a FacilitatorPool
+// this is the member that answered.
+func TrackFacilitator(ctx context.Context) context.Context {
+	return context.WithValue(ctx, usedFacilitatorKey{}, &usedFacilitator{})
+}
+
+// UsedFacilitator returns the base URL of the facilitator that last answered
+// a request made with ctx, or "" if none did or ctx is not tracked.
+func UsedFacilitator(ctx context.Context) string {
+	used, ok := ctx.Value(usedFacilitatorKey{}).(*usedFacilitator)
+	if !ok {
+		return ""
+	}
+	used.mu.Lock()
+	defer used.mu.Unlock()
+	return used.baseURL
+}
+
+// setUsedFacilitator records that baseURL answered a request made with ctx.
+func setUsedFacilitator(ctx context.Context, baseURL string) {
+	if used, ok := ctx.Value(usedFacilitatorKey{}).(*usedFacilitator); ok {
+		used.mu.Lock()
+		used.baseURL = baseURL
+		used.mu.Unlock()
+	}
+}
@@ -0,0 +1,225 @@
+package x402
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+)
+
+// Redacted replaces sensitive values in logs.
+const Redacted = "[REDACTED]"
+
+// RedactedKeys are the attribute keys whose values RedactHandler replaces:
+// payment signatures, signed transactions and encoded payment headers.
+var RedactedKeys = []string{"signature", "transaction", "paymentHeader"}
+
+// RedactHandler is a slog.Handler that replaces the values of RedactedKeys,
+// at any depth of groups, before passing records on. Facilitator request
+// bodies are logged as nested groups so their signatures are caught as well.
+type RedactHandler struct {
+	handler slog.Handler
+}
+
+// NewRedactHandler wraps handler with redaction.
+func NewRedactHandler(handler slog.Handler) *RedactHandler {
+	if redact, ok := handler.(*RedactHandler); ok {
+		return redact
+	}
+	return &RedactHandler{handler: handler}
+}
+
+// Enabled implements slog.Handler.
+func (h *RedactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *RedactHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redact(attr))
+		return true
+	})
+	return h.handler.Handle(ctx, redacted)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = redact(attr)
+	}
+	return &RedactHandler{handler: h.handler.WithAttrs(redacted)}
+}
+
+// WithGroup implements slog.Handler.
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	return &RedactHandler{handler: h.handler.WithGroup(name)}
+}
+
+// redact replaces the value of a sensitive attribute and redacts groups recursively.
+func redact(attr slog.Attr) slog.Attr {
+	if slices.Contains(RedactedKeys, attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+	attr.Value = attr.Value.Resolve()
+	if attr.Value.Kind() != slog.KindGroup {
+		return attr
+	}
+	group := attr.Value.Group()
+	redacted := make([]slog.Attr, len(group))
+	for i, member := range group {
+		redacted[i] = redact(member)
+	}
+	return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redacted...)}
+}
+
+// jsonLog logs a JSON document as nested groups, so RedactHandler sees its
+// keys. It is only decoded if the record is handled.
+type jsonLog []byte
+
+// LogValue implements slog.LogValuer.
+func (j jsonLog) LogValue() slog.Value {
+	var v any
+	if err := sonic.Unmarshal(j, &v); err != nil {
+		return slog.StringValue(fmt.Sprintf("<invalid JSON: %v>", err))
+	}
+	return jsonValue(v)
+}
+
+// jsonValue converts a decoded JSON value to a slog value.
+func jsonValue(v any) slog.Value {
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		attrs := make([]slog.Attr, len(keys))
+		for i, key := range keys {
+			attrs[i] = slog.Attr{Key: key, Value: jsonValue(v[key])}
+		}
+		return slog.GroupValue(attrs...)
+	case []any:
+		attrs := make([]slog.Attr, len(v))
+		for i, item := range v {
+			attrs[i] = slog.Attr{Key: strconv.Itoa(i), Value: jsonValue(item)}
+		}
+		return slog.GroupValue(attrs...)
+	default:
+		return slog.AnyValue(v)
+	}
+}
+
+// LoggerHandler is a slog.Handler writing to a Logger, for Logger
+// implementations written before slog support. Records at Warn and above go
+// to Errorf, others to Printf, formatted as "[x402] message key=value ...".
+type LoggerHandler struct {
+	logger Logger
+	level  slog.Leveler
+	attrs  string // preformatted attributes from WithAttrs
+	group  string // key prefix from WithGroup
+}
+
+// NewLoggerHandler creates a handler writing records at level and above to
+// logger. A nil level logs everything, like Logger did before.
+func NewLoggerHandler(logger Logger, level slog.Leveler) *LoggerHandler {
+	if level == nil {
+		level = slog.LevelDebug
+	}
+	return &LoggerHandler{logger: logger, level: level}
+}
+
+// Enabled implements slog.Handler.
+func (h *LoggerHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle implements slog.Handler.
+func (h *LoggerHandler) Handle(_ context.Context, record slog.Record) error {
+	var b strings.Builder
+	b.WriteString("[x402] ")
+	b.WriteString(record.Message)
+	b.WriteString(h.attrs)
+	record.Attrs(func(attr slog.Attr) bool {
+		appendAttr(&b, h.group, attr)
+		return true
+	})
+
+	if record.Level >= slog.LevelWarn {
+		h.logger.Errorf("%s", b.String())
+	} else {
+		h.logger.Printf("%s", b.String())
+	}
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *LoggerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	b.WriteString(h.attrs)
+	for _, attr := range attrs {
+		appendAttr(&b, h.group, attr)
+	}
+	clone := *h
+	clone.attrs = b.String()
+	return &clone
+}
+
+// WithGroup implements slog.Handler.
+func (h *LoggerHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.group += name + "."
+	return &clone
+}
+
+// appendAttr writes " key=value", flattening groups into dotted keys.
+func appendAttr(b *strings.Builder, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			appendAttr(b, prefix, member)
+		}
+		return
+	}
+	b.WriteString(" ")
+	b.WriteString(prefix)
+	b.WriteString(attr.Key)
+	b.WriteString("=")
+	b.WriteString(attr.Value.String())
+}
+
+// newLogger returns the structured logger for a configuration: slogger if
+// set, otherwise logger through LoggerHandler, otherwise a discarding logger.
+// Unless payload logging is enabled, sensitive values are redacted.
+func newLogger(slogger *slog.Logger, logger Logger, logPayloads bool) *slog.Logger {
+	switch {
+	case slogger != nil:
+	case logger != nil:
+		slogger = slog.New(NewLoggerHandler(logger, nil))
+	default:
+		return slog.New(slog.DiscardHandler)
+	}
+	if logPayloads {
+		return slogger
+	}
+	return slog.New(NewRedactHandler(slogger.Handler()))
+}
+
+// Log returns the configuration's logger. Unlike Slog it may be used before
+// Validate, e.g. to report an invalid configuration.
+func (c *Config) Log() *slog.Logger {
+	return newLogger(c.Slog, c.Logger, c.LogPayloads)
+}
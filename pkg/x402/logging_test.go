@@ -0,0 +1,97 @@
+package x402
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	x402 "github.com/dexfra-fun/x402-go"
+)
+
+const (
+	testSignature   = "0xdeadbeefsignature"
+	testTransaction = "AQABAsignedtransaction"
+)
+
+func TestFacilitatorClient_LogRedaction(t *testing.T) {
+	payments := []x402.PaymentPayload{
+		{
+			X402Version: 1, Scheme: "exact", Network: "base-sepolia",
+			Payload: x402.EVMPayload{
+				Signature:     testSignature,
+				Authorization: x402.EVMAuthorization{From: "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"},
+			},
+		},
+		{
+			X402Version: 1, Scheme: "exact", Network: "solana-devnet",
+			Payload: x402.SVMPayload{Transaction: testTransaction},
+		},
+	}
+	tests := []struct {
+		name        string
+		logPayloads bool
+	}{
+		{"redacted", false},
+		{"payload logging", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			server := newStatusFacilitator(t, http.StatusOK, `{"isValid":true}`)
+			client := NewFacilitatorClient(server.baseURL, NewFeePayerCache(0), nil,
+				WithSlog(logger), WithLogPayloads(tt.logPayloads))
+
+			for _, payment := range payments {
+				if _, err := client.Verify(context.Background(), payment, poolRequirement); err != nil {
+					t.Fatalf("Verify() error = %v", err)
+				}
+			}
+
+			logs := buf.String()
+			for _, secret := range []string{testSignature, testTransaction} {
+				if got := strings.Contains(logs, secret); got != tt.logPayloads {
+					t.Errorf("logs contain %s = %v, want %v:\n%s", secret, got, tt.logPayloads, logs)
+				}
+			}
+			if !strings.Contains(logs, "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23") {
+				t.Errorf("logs lost non-sensitive fields:\n%s", logs)
+			}
+		})
+	}
+}
+
+type recordingLogger struct {
+	printed, errored []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...any) {
+	l.printed = append(l.printed, strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (l *recordingLogger) Errorf(format string, v ...any) {
+	l.errored = append(l.errored, strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func TestLoggerHandler(t *testing.T) {
+	recorder := &recordingLogger{}
+	logger := slog.New(NewRedactHandler(NewLoggerHandler(recorder, slog.LevelInfo)))
+
+	logger.Debug("hidden")
+	logger.With("network", "base").Info("Payment verified", "payer", "0xabc")
+	logger.WithGroup("payment").Warn("Settlement failed", "signature", testSignature)
+
+	wantPrinted := []string{"[x402] Payment verified network=base payer=0xabc"}
+	wantErrored := []string{"[x402] Settlement failed payment.signature=" + Redacted}
+	if strings.Join(recorder.printed, "\n") != strings.Join(wantPrinted, "\n") {
+		t.Errorf("Printf = %q, want %q", recorder.printed, wantPrinted)
+	}
+	if strings.Join(recorder.errored, "\n") != strings.Join(wantErrored, "\n") {
+		t.Errorf("Errorf = %q, want %q", recorder.errored, wantErrored)
+	}
+}
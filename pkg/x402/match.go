@@ -0,0 +1,99 @@
+package x402
+
+import (
+	"encoding/base64"
+	"strings"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/internal/svm"
+	evmsigner "github.com/dexfra-fun/x402-go/pkg/signers/evm"
+)
+
+// MatchRequirement selects the requirement a payment was made against.
+//
+// Requirements are first filtered by scheme and network. If several assets are
+// accepted on the payment's network, the asset is identified from the payload:
+// the TransferChecked mint for Solana transactions, or the EIP-712 domain that
+// the signature recovers against for EVM authorizations.
+func MatchRequirement(
+	payment x402.PaymentPayload,
+	requirements []x402.PaymentRequirement,
+) (*x402.PaymentRequirement, error) {
+	candidates := make([]*x402.PaymentRequirement, 0, len(requirements))
+	for i := range requirements {
+		if BasicPaymentCheck(payment, requirements[i]) {
+			candidates = append(candidates, &requirements[i])
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, ErrNoMatchingRequirement
+	case 1:
+		return candidates[0], nil
+	}
+
+	if chain, ok := x402.GetChainConfig(candidates[0].Network); ok && chain.IsEVM() {
+		return matchEVMRequirement(payment, candidates)
+	}
+	return matchSVMRequirement(payment, candidates)
+}
+
+// matchSVMRequirement matches on the mint of the transaction's token transfer.
+func matchSVMRequirement(
+	payment x402.PaymentPayload,
+	candidates []*x402.PaymentRequirement,
+) (*x402.PaymentRequirement, error) {
+	payload, err := payment.DecodeSVMPayload()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(payload.Transaction)
+	if err != nil {
+		return nil, ErrNoMatchingRequirement
+	}
+
+	var tx svm.Transaction
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, ErrNoMatchingRequirement
+	}
+	transfer, ok := tx.Message.FindTransferChecked()
+	if !ok {
+		return nil, ErrNoMatchingRequirement
+	}
+
+	mint := transfer.Mint.String()
+	for _, candidate := range candidates {
+		if candidate.Asset == mint {
+			return candidate, nil
+		}
+	}
+	return nil, ErrNoMatchingRequirement
+}
+
+// matchEVMRequirement matches on the token contract whose EIP-712 domain the
+// authorization was signed for.
+func matchEVMRequirement(
+	payment x402.PaymentPayload,
+	candidates []*x402.PaymentRequirement,
+) (*x402.PaymentRequirement, error) {
+	payload, err := payment.DecodeEVMPayload()
+	if err != nil {
+		return nil, err
+	}
+	// The payload is untrusted; reject out-of-range fields before hashing it
+	if _, err := evmsigner.ParseAuthorization(payload.Authorization); err != nil {
+		return nil, ErrNoMatchingRequirement
+	}
+
+	for _, candidate := range candidates {
+		if !strings.EqualFold(payload.Authorization.To, candidate.PayTo) {
+			continue
+		}
+		signer, err := evmsigner.RecoverSigner(*payload, *candidate)
+		if err == nil && strings.EqualFold(signer, payload.Authorization.From) {
+			return candidate, nil
+		}
+	}
+	return nil, ErrNoMatchingRequirement
+}
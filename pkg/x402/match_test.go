@@ -0,0 +1,124 @@
+package x402
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	evmsigner "github.com/dexfra-fun/x402-go/pkg/signers/evm"
+	svmsigner "github.com/dexfra-fun/x402-go/pkg/signers/svm"
+)
+
+func TestMatchRequirement_EVMAsset(t *testing.T) {
+	const eurc = "0x808456652fdb597867f38412077a9182bf77359f"
+
+	requirements := []x402.PaymentRequirement{
+		{
+			Scheme: "exact", Network: "base-sepolia", MaxAmountRequired: "1000",
+			Asset: x402.BaseSepolia.USDCAddress, PayTo: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+			MaxTimeoutSeconds: 60, Extra: map[string]any{"name": "USDC", "version": "2"},
+		},
+		{
+			Scheme: "exact", Network: "base-sepolia", MaxAmountRequired: "1000",
+			Asset: eurc, PayTo: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+			MaxTimeoutSeconds: 60, Extra: map[string]any{"name": "EURC", "version": "2"},
+		},
+	}
+
+	signer, err := evmsigner.NewSignerFromHex("base-sepolia",
+		"4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318",
+		evmsigner.WithToken(x402.TokenConfig{Address: eurc, Symbol: "EURC", Decimals: 6}),
+	)
+	if err != nil {
+		t.Fatalf("NewSignerFromHex() error = %v", err)
+	}
+	payment, err := signer.Sign(context.Background(), &requirements[1])
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	matched, err := MatchRequirement(*payment, requirements)
+	if err != nil {
+		t.Fatalf("MatchRequirement() error = %v", err)
+	}
+	if matched.Asset != eurc {
+		t.Errorf("matched asset = %s, want %s", matched.Asset, eurc)
+	}
+
+	payload, _ := payment.DecodeEVMPayload()
+	payload.Authorization.Value = "1" + strings.Repeat("0", 90)
+	payment.Payload = *payload
+	if _, err := MatchRequirement(*payment, requirements); !errors.Is(err, ErrNoMatchingRequirement) {
+		t.Errorf("MatchRequirement() with oversized value: error = %v, want ErrNoMatchingRequirement", err)
+	}
+}
+
+func TestMatchRequirement_SVMAsset(t *testing.T) {
+	const otherMint = "2wKupLR9q6wXYppw8Gr2NvWxKBUqm4PPJKkQfoxHDBg4"
+
+	extra := map[string]any{"feePayer": "L54zkaPQFeTn1UsEqieEXBqWrPShiaZEPD7mS5WXfQg"}
+	requirements := []x402.PaymentRequirement{
+		{
+			Scheme: "exact", Network: "solana-devnet", MaxAmountRequired: "1000",
+			Asset: x402.SolanaDevnet.USDCAddress, PayTo: otherMint, Extra: extra,
+		},
+		{
+			Scheme: "exact", Network: "solana-devnet", MaxAmountRequired: "1000",
+			Asset: otherMint, PayTo: otherMint, Extra: extra,
+		},
+	}
+
+	seed := make([]byte, ed25519.SeedSize)
+	signer, err := svmsigner.NewSigner("solana-devnet", ed25519.NewKeyFromSeed(seed),
+		svmsigner.WithToken(x402.TokenConfig{Address: otherMint, Decimals: 6}),
+		svmsigner.WithBlockhashProvider(svmsigner.BlockhashFunc(func(context.Context) (string, error) {
+			return "EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N", nil
+		})),
+	)
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	payment, err := signer.Sign(context.Background(), &requirements[1])
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	matched, err := MatchRequirement(*payment, requirements)
+	if err != nil {
+		t.Fatalf("MatchRequirement() error = %v", err)
+	}
+	if matched.Asset != otherMint {
+		t.Errorf("matched asset = %s, want %s", matched.Asset, otherMint)
+	}
+}
+
+func TestMatchRequirement_NoMatch(t *testing.T) {
+	requirements := []x402.PaymentRequirement{
+		{Scheme: "exact", Network: "base"},
+		{Scheme: "exact", Network: "solana"},
+	}
+
+	tests := []struct {
+		name    string
+		payment x402.PaymentPayload
+	}{
+		{"other network", x402.PaymentPayload{Scheme: "exact", Network: "polygon"}},
+		{"other scheme", x402.PaymentPayload{Scheme: "upto", Network: "base"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := MatchRequirement(tt.payment, requirements); !errors.Is(err, ErrNoMatchingRequirement) {
+				t.Errorf("MatchRequirement() error = %v, want ErrNoMatchingRequirement", err)
+			}
+		})
+	}
+
+	matched, err := MatchRequirement(x402.PaymentPayload{Scheme: "exact", Network: "solana"}, requirements)
+	if err != nil || matched.Network != "solana" {
+		t.Errorf("MatchRequirement() = %v, %v; want solana requirement", matched, err)
+	}
+}
@@ -0,0 +1,83 @@
+package x402
+
+import (
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/shopspring/decimal"
+)
+
+// Facilitator endpoints reported to Metrics.FacilitatorRequest.
+const (
+	EndpointSupported = "supported"
+	EndpointVerify    = "verify"
+	EndpointSettle    = "settle"
+)
+
+// PaymentEvent describes the outcome of verifying or settling a payment.
+type PaymentEvent struct {
+	Resource Resource
+	Network  string
+	// Asset is the token symbol, or its address if the symbol is unknown.
+	Asset string
+	// Amount is the price in token units, e.g. 0.01 for one cent of USDC.
+	Amount decimal.Decimal
+	// Code is empty on success, otherwise the error code of the failure,
+	// e.g. INSUFFICIENT_FUNDS or FACILITATOR_UNAVAILABLE.
+	Code x402.ErrorCode
+}
+
+// Success reports whether the step succeeded.
+func (e PaymentEvent) Success() bool {
+	return e.Code == ""
+}
+
+// Metrics receives payment events from the middleware (see Config.Metrics).
+// pkg/metrics/prometheus provides a Prometheus implementation. Methods are
+// called on the request path and must not block; embed NopMetrics to
+// implement only some of them.
+type Metrics interface {
+	// RequirementsIssued is called when a request without payment gets a 402 response.
+	RequirementsIssued(resource Resource, requirements []x402.PaymentRequirement)
+	// PaymentVerified is called once per payment attempt, after it was verified
+	// or rejected before settlement (invalid, replayed or unverifiable payments).
+	PaymentVerified(event PaymentEvent)
+	// PaymentSettled is called after settlement succeeded or failed.
+	PaymentSettled(event PaymentEvent)
+	// FacilitatorRequest is called after each HTTP request to a facilitator,
+	// identified by its base URL, with the endpoint and the request's error.
+	FacilitatorRequest(facilitator, endpoint string, duration time.Duration, err error)
+}
+
+// NopMetrics is a Metrics that discards all events.
+type NopMetrics struct{}
+
+// RequirementsIssued implements Metrics.
+func (NopMetrics) RequirementsIssued(Resource, []x402.PaymentRequirement) {}
+
+// PaymentVerified implements Metrics.
+func (NopMetrics) PaymentVerified(PaymentEvent) {}
+
+// PaymentSettled implements Metrics.
+func (NopMetrics) PaymentSettled(PaymentEvent) {}
+
+// FacilitatorRequest implements Metrics.
+func (NopMetrics) FacilitatorRequest(string, string, time.Duration, error) {}
+
+// NewPaymentEvent describes a payment step for a resource; perr is the
+// step's failure, or nil on success.
+func NewPaymentEvent(resource Resource, info *PaymentInfo, perr *x402.PaymentError) PaymentEvent {
+	event := PaymentEvent{Resource: resource}
+	if info != nil {
+		event.Network = info.Network
+		event.Asset = info.Currency
+		if event.Asset == "" {
+			event.Asset = info.Asset
+		}
+		event.Amount = info.Amount
+	}
+	if perr != nil {
+		event.Code = perr.Code
+	}
+	return event
+}
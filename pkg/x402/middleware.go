@@ -5,18 +5,31 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/pkg/facilitator"
 	"github.com/mr-tron/base58"
 	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Middleware handles x402 payment verification.
 type Middleware struct {
 	config      *Config
-	facilitator *FacilitatorClient
+	facilitator facilitator.Interface
 	cache       *FeePayerCache
-	chainConfig x402.ChainConfig
+	feePayers   *feePayerResolver
+	supported   *SupportedCache // owned by the middleware, nil if shared
+	options     []paymentOption
+	tracer      trace.Tracer
+	now         func() time.Time
+
+	ctx       context.Context //nolint:containedctx // canceled by Close to stop background work
+	cancel    context.CancelFunc
+	stopAfter func() bool
+	wg        sync.WaitGroup
 }
 
 // New creates a new x402 middleware instance.
@@ -26,47 +39,97 @@ func New(config *Config) (*Middleware, error) {
 		return nil, err
 	}
 
-	// Map network to chain config
-	chainConfig, err := MapNetworkToChain(config.Network)
+	// Expand configured networks into payment options
+	options, err := resolvePaymentOptions(config)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create cache
+	// Create caches; /supported responses go to the shared cache if configured
 	cache := NewFeePayerCache(config.CacheTTL)
+	m := &Middleware{
+		config:  config,
+		cache:   cache,
+		options: options,
+		tracer:  config.TracerProvider.Tracer(TracerName),
+		now:     time.Now,
+	}
+	supported := config.SupportedCache
+	if supported == nil {
+		m.supported = NewSupportedCache(config.CacheTTL, config.CacheTTL)
+		supported = m.supported
+	}
+	clientOptions := append(config.clientOptions(), WithSupportedCache(supported))
 
-	// Start cleanup routine (runs every CacheTTL/2)
-	const cacheCleanupDivisor = 2
-	cache.StartCleanupRoutine(config.CacheTTL / cacheCleanupDivisor)
-
-	// Create facilitator client
-	facilitator := NewFacilitatorClient(config.FacilitatorURL, cache, config.Logger)
+	// Use the configured facilitator, a failover pool, or a client for FacilitatorURL
+	client := config.Facilitator
+	switch {
+	case client != nil:
+	case len(config.Facilitators) > 0:
+		pool, err := newFacilitatorPool(config, clientOptions...)
+		if err != nil {
+			if m.supported != nil {
+				m.supported.Close()
+			}
+			return nil, err
+		}
+		client = pool
+	default:
+		opts := append(clientOptions, WithRetryPolicy(config.Retry), WithAuthorizer(config.FacilitatorAuthorizer))
+		client = NewFacilitatorClient(config.FacilitatorURL, cache, nil, opts...)
+	}
 
-	return &Middleware{
-		config:      config,
-		facilitator: facilitator,
+	m.facilitator = client
+	m.feePayers = &feePayerResolver{
+		facilitator: client,
 		cache:       cache,
-		chainConfig: chainConfig,
-	}, nil
+		logger:      config.Slog,
+		check:       FeePayerCheckOff,
+	}
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	if config.Context != nil {
+		m.stopAfter = context.AfterFunc(config.Context, m.Close)
+	}
+
+	// Keep fee payers fresh in the background instead of on the request path
+	if config.FeePayerRefreshInterval > 0 && len(m.feePayerNetworks()) > 0 {
+		m.startFeePayerRefresh(config.FeePayerRefreshInterval)
+	}
+	return m, nil
 }
 
-// getFeePayer retrieves the fee payer, trying facilitator first then config fallback.
-func (m *Middleware) getFeePayer(ctx context.Context) (string, error) {
+// getFeePayer retrieves the fee payer for an option, trying facilitator first then config fallback.
+func (m *Middleware) getFeePayer(ctx context.Context, option paymentOption) (string, error) {
 	// Try facilitator first
-	feePayer, err := m.facilitator.GetFeePayer(ctx, m.config.Network)
+	feePayer, err := m.facilitatorFeePayer(ctx, option.chain.NetworkID)
 	if err != nil && !errors.Is(err, ErrFeePayerNotFound) {
 		return "", fmt.Errorf("get fee payer: %w", err)
 	}
 
 	// Use fallback if facilitator doesn't have one
-	if errors.Is(err, ErrFeePayerNotFound) && m.config.FeePayer != "" {
-		m.config.Logger.Printf("[x402] Facilitator doesn't provide fee payer, using config fallback")
-		return m.config.FeePayer, nil
+	if errors.Is(err, ErrFeePayerNotFound) && option.feePayer != "" {
+		m.config.Slog.Info("Facilitator doesn't provide a fee payer, using config fallback",
+			"network", option.chain.NetworkID)
+		return option.feePayer, nil
 	}
 
 	return feePayer, nil
 }
 
+// feePayerProvider is implemented by facilitators that resolve fee payers themselves.
+type feePayerProvider interface {
+	GetFeePayer(ctx context.Context, network string) (string, error)
+}
+
+// facilitatorFeePayer returns the facilitator's fee payer for a network.
+// Custom facilitators are queried through Supported, with results cached.
+func (m *Middleware) facilitatorFeePayer(ctx context.Context, network string) (string, error) {
+	if provider, ok := m.facilitator.(feePayerProvider); ok {
+		return provider.GetFeePayer(ctx, network)
+	}
+	return m.feePayers.feePayer(ctx, network)
+}
+
 // validateFeePayer validates the fee payer address.
 func (m *Middleware) validateFeePayer(feePayer string) error {
 	feePayer = strings.TrimSpace(feePayer)
@@ -74,46 +137,131 @@ func (m *Middleware) validateFeePayer(feePayer string) error {
 		return ErrMissingFeePayer
 	}
 	if _, err := base58.Decode(feePayer); err != nil {
-		m.config.Logger.Errorf("[x402] Invalid fee payer (not base58): %q: %v", feePayer, err)
+		m.config.Slog.Error("Invalid fee payer (not base58)", "feePayer", feePayer, "error", err)
 		return ErrInvalidFeePayer
 	}
 	return nil
 }
 
-// addRequirementMetadata adds schema and extra metadata to requirement.
-func (m *Middleware) addRequirementMetadata(
+// resolveFeePayer returns the validated fee payer for an option (empty for EVM options).
+// Results are memoized per network in feePayers for the duration of a request.
+func (m *Middleware) resolveFeePayer(
 	ctx context.Context,
+	option paymentOption,
+	feePayers map[string]string,
+) (string, error) {
+	if option.chain.IsEVM() {
+		return "", nil
+	}
+	if feePayer, ok := feePayers[option.chain.NetworkID]; ok {
+		return feePayer, nil
+	}
+
+	ctx, span := m.tracer.Start(ctx, "x402.ResolveFeePayer",
+		trace.WithAttributes(AttrNetwork.String(option.chain.NetworkID)))
+	feePayer, err := m.getFeePayer(ctx, option)
+	if err == nil {
+		feePayer = strings.TrimSpace(feePayer)
+		err = m.validateFeePayer(feePayer)
+	}
+	endSpan(span, err)
+	if err != nil {
+		return "", err
+	}
+
+	feePayers[option.chain.NetworkID] = feePayer
+	return feePayer, nil
+}
+
+// getPrice returns the price of a resource from the pricing strategy.
+func (m *Middleware) getPrice(ctx context.Context, resource Resource) (decimal.Decimal, error) {
+	ctx, span := m.tracer.Start(ctx, "x402.GetPrice")
+	price, err := m.config.PricingStrategy.GetPrice(ctx, resource)
+	if err != nil {
+		err = fmt.Errorf("get price: %w", err)
+	} else {
+		span.SetAttributes(AttrAmount.String(price.String()))
+	}
+	endSpan(span, err)
+	return price, err
+}
+
+// getSchema returns the endpoint schema if a SchemaProvider is configured.
+func (m *Middleware) getSchema(ctx context.Context, resource Resource) *x402.EndpointSchema {
+	if m.config.SchemaProvider == nil {
+		return nil
+	}
+	ctx, span := m.tracer.Start(ctx, "x402.GetSchema")
+	schema, err := m.config.SchemaProvider.GetSchema(ctx, resource)
+	endSpan(span, err)
+	if err != nil {
+		m.config.Slog.Warn("Failed to get schema", "route", resource.Path, "error", err)
+		return nil
+	}
+	return schema
+}
+
+// newRequirement creates the payment requirement for an option.
+// The price is converted to atomic units of the option's asset using banker's rounding.
+func newRequirement(
+	option paymentOption,
+	price decimal.Decimal,
+	resourceURL string,
+	description string,
+) x402.PaymentRequirement {
+	requirement := x402.PaymentRequirement{
+		Scheme:            x402.DefaultScheme,
+		Network:           option.chain.NetworkID,
+		MaxAmountRequired: price.Shift(int32(option.asset.Decimals)).RoundBank(0).String(), //nolint:gosec // asset decimals are small
+		Asset:             option.asset.Address,
+		PayTo:             option.recipient,
+		Resource:          resourceURL,
+		Description:       description,
+		MimeType:          x402.DefaultMimeType,
+		MaxTimeoutSeconds: x402.DefaultMaxTimeoutSeconds,
+	}
+
+	// Populate EIP-712 domain for EVM tokens
+	if option.asset.EIP712Name != "" {
+		requirement.Extra = map[string]any{
+			"name":    option.asset.EIP712Name,
+			"version": option.asset.EIP712Version,
+		}
+	}
+
+	return requirement
+}
+
+// addRequirementMetadata adds schema and extra metadata to requirement.
+func addRequirementMetadata(
 	requirement *x402.PaymentRequirement,
-	resource Resource,
+	schema *x402.EndpointSchema,
 	feePayer string,
 ) {
 	// Add fee payer to extra metadata
-	if requirement.Extra == nil {
-		requirement.Extra = make(map[string]any)
+	if feePayer != "" {
+		if requirement.Extra == nil {
+			requirement.Extra = make(map[string]any)
+		}
+		requirement.Extra["feePayer"] = feePayer
 	}
-	requirement.Extra["feePayer"] = feePayer
 
-	// Add schema if SchemaProvider is configured
-	if m.config.SchemaProvider != nil {
-		schema, err := m.config.SchemaProvider.GetSchema(ctx, resource)
-		if err != nil {
-			m.config.Logger.Printf("[x402] Failed to get schema: %v", err)
-		} else if schema != nil {
-			requirement.OutputSchema = schema
-			m.config.Logger.Printf("[x402] Schema added to payment requirement")
-		}
+	if schema != nil {
+		requirement.OutputSchema = schema
 	}
 }
 
-// ProcessRequest handles payment requirement for a resource.
+// ProcessRequest handles payment requirements for a resource.
+// It returns one requirement per configured network and asset, in preference
+// order, or nil requirements for free endpoints.
 func (m *Middleware) ProcessRequest(
 	ctx context.Context,
 	resource Resource,
-) (*x402.PaymentRequirement, *PaymentInfo, error) {
+) ([]x402.PaymentRequirement, *PaymentInfo, error) {
 	// Get price for this resource
-	price, err := m.config.PricingStrategy.GetPrice(ctx, resource)
+	price, err := m.getPrice(ctx, resource)
 	if err != nil {
-		return nil, nil, fmt.Errorf("get price: %w", err)
+		return nil, nil, err
 	}
 
 	// Free endpoint - no payment required
@@ -121,19 +269,8 @@ func (m *Middleware) ProcessRequest(
 		return nil, nil, nil
 	}
 
-	m.config.Logger.Printf("[x402] Payment required: path=%s method=%s price=%s USDC",
-		resource.Path, resource.Method, price.String())
-
-	// Get and validate fee payer
-	feePayer, err := m.getFeePayer(ctx)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	feePayer = strings.TrimSpace(feePayer)
-	if err := m.validateFeePayer(feePayer); err != nil {
-		return nil, nil, err
-	}
+	m.config.Slog.Debug("Payment required",
+		"route", resource.Path, "method", resource.Method, "amount", price.String())
 
 	// Get resource URL and description if ResourceProvider is configured
 	resourceURL := ""
@@ -141,37 +278,61 @@ func (m *Middleware) ProcessRequest(
 	if m.config.ResourceProvider != nil {
 		resourceURL, err = m.config.ResourceProvider.GetResourceURL(ctx, resource)
 		if err != nil {
-			m.config.Logger.Printf("[x402] Failed to get resource URL: %v", err)
+			m.config.Slog.Warn("Failed to get resource URL", "route", resource.Path, "error", err)
 		}
 		description, err = m.config.ResourceProvider.GetDescription(ctx, resource)
 		if err != nil {
-			m.config.Logger.Printf("[x402] Failed to get description: %v", err)
+			m.config.Slog.Warn("Failed to get description", "route", resource.Path, "error", err)
 		}
 	}
 
-	// Create USDC payment requirement
-	requirement, err := x402.NewUSDCPaymentRequirement(x402.USDCRequirementConfig{
-		Chain:            m.chainConfig,
-		Amount:           price.String(),
-		RecipientAddress: m.config.RecipientAddress,
-		Resource:         resourceURL,
-		Description:      description,
-	})
-	if err != nil {
-		return nil, nil, fmt.Errorf("create payment requirement: %w", err)
+	schema := m.getSchema(ctx, resource)
+
+	requirements := make([]x402.PaymentRequirement, 0, len(m.options))
+	feePayers := make(map[string]string)
+	var firstErr error
+	for _, option := range m.options {
+		// Get and validate fee payer (EVM payments have no fee payer)
+		feePayer, err := m.resolveFeePayer(ctx, option, feePayers)
+		if err != nil {
+			// Skip this option but keep offering the others
+			m.config.Slog.Error("Skipping payment option", "network", option.chain.NetworkID, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		requirement := newRequirement(option, price, resourceURL, description)
+		addRequirementMetadata(&requirement, schema, feePayer)
+		requirements = append(requirements, requirement)
 	}
 
-	// Add metadata (feePayer in extra, schema if configured)
-	m.addRequirementMetadata(ctx, &requirement, resource, feePayer)
+	if len(requirements) == 0 {
+		return nil, nil, firstErr
+	}
+
+	return requirements, m.PaymentInfoFor(price, requirements[0]), nil
+}
 
-	paymentInfo := &PaymentInfo{
+// PaymentInfoFor describes a payment of price made against requirement.
+func (m *Middleware) PaymentInfoFor(price decimal.Decimal, requirement x402.PaymentRequirement) *PaymentInfo {
+	info := &PaymentInfo{
 		Amount:    price,
-		Currency:  "USDC",
-		Recipient: m.config.RecipientAddress,
-		FeePayer:  feePayer,
+		Recipient: requirement.PayTo,
+		Network:   requirement.Network,
+		Asset:     requirement.Asset,
 	}
-
-	return &requirement, paymentInfo, nil
+	if feePayer, ok := requirement.Extra["feePayer"].(string); ok {
+		info.FeePayer = feePayer
+	}
+	for _, option := range m.options {
+		if option.chain.NetworkID == requirement.Network && strings.EqualFold(option.asset.Address, requirement.Asset) {
+			info.Currency = option.asset.Symbol
+			break
+		}
+	}
+	return info
 }
 
 // GetConfig returns the middleware configuration.
@@ -179,7 +340,24 @@ func (m *Middleware) GetConfig() *Config {
 	return m.config
 }
 
-// GetFacilitator returns the facilitator client.
-func (m *Middleware) GetFacilitator() *FacilitatorClient {
+// GetFacilitator returns the facilitator used to verify and settle payments.
+func (m *Middleware) GetFacilitator() facilitator.Interface {
 	return m.facilitator
 }
+
+// Close stops background work such as fee payer refresh and facilitator health checks.
+// It waits for background goroutines to return and may be called more than once.
+func (m *Middleware) Close() {
+	if m.stopAfter != nil {
+		m.stopAfter()
+	}
+	m.cancel()
+	m.wg.Wait()
+	if pool, ok := m.facilitator.(*FacilitatorPool); ok {
+		pool.Close()
+	}
+	m.cache.Close()
+	if m.supported != nil {
+		m.supported.Close()
+	}
+}
@@ -0,0 +1,171 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/shopspring/decimal"
+)
+
+type fixedPrice decimal.Decimal
+
+func (p fixedPrice) GetPrice(context.Context, Resource) (decimal.Decimal, error) {
+	return decimal.Decimal(p), nil
+}
+
+func TestProcessRequest_EVMHasNoFeePayer(t *testing.T) {
+	m, err := New(&Config{
+		RecipientAddress: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+		Network:          "base-sepolia",
+		// Never contacted: EVM requirements don't need a fee payer
+		FacilitatorURL:  "http://127.0.0.1:0",
+		PricingStrategy: fixedPrice(decimal.RequireFromString("0.01")),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	requirements, info, err := m.ProcessRequest(context.Background(), Resource{Path: "/api", Method: "GET"})
+	if err != nil {
+		t.Fatalf("ProcessRequest() error = %v", err)
+	}
+	if len(requirements) != 1 {
+		t.Fatalf("expected 1 requirement, got %d", len(requirements))
+	}
+	requirement := requirements[0]
+
+	if requirement.MaxAmountRequired != "10000" {
+		t.Errorf("expected 10000 atomic units, got %s", requirement.MaxAmountRequired)
+	}
+	if _, ok := requirement.Extra["feePayer"]; ok {
+		t.Error("expected no feePayer for EVM requirement")
+	}
+	if requirement.Extra["name"] != "USDC" || requirement.Extra["version"] != "2" {
+		t.Errorf("expected EIP-712 domain in extra, got %v", requirement.Extra)
+	}
+	if info.FeePayer != "" {
+		t.Errorf("expected empty fee payer in payment info, got %s", info.FeePayer)
+	}
+}
+
+func TestProcessRequest_MultipleNetworks(t *testing.T) {
+	const (
+		baseRecipient    = "0x209693Bc6afc0C5328bA36FaF03C514EF312287C"
+		polygonRecipient = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+		eurcAddress      = "0x808456652fdb597867f38412077A9182bf77359F"
+	)
+
+	m, err := New(&Config{
+		RecipientAddress: baseRecipient,
+		Network:          "base-sepolia",
+		FacilitatorURL:   "http://127.0.0.1:0",
+		PricingStrategy:  fixedPrice(decimal.RequireFromString("0.5")),
+		Networks: map[string]NetworkConfig{
+			"base-sepolia": {
+				Assets: []AssetConfig{
+					NewUSDCAsset(x402.BaseSepolia),
+					{Address: eurcAddress, Symbol: "EURC", Decimals: 6, EIP712Name: "EURC", EIP712Version: "2"},
+				},
+			},
+			"polygon-amoy":   {RecipientAddress: polygonRecipient, Priority: 2},
+			"avalanche-fuji": {Priority: 1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	requirements, info, err := m.ProcessRequest(context.Background(), Resource{Path: "/api", Method: "GET"})
+	if err != nil {
+		t.Fatalf("ProcessRequest() error = %v", err)
+	}
+
+	want := []struct {
+		network string
+		asset   string
+		payTo   string
+		name    string
+	}{
+		{"base-sepolia", x402.BaseSepolia.USDCAddress, baseRecipient, "USDC"},
+		{"base-sepolia", eurcAddress, baseRecipient, "EURC"},
+		{"avalanche-fuji", x402.AvalancheFuji.USDCAddress, baseRecipient, "USD Coin"},
+		{"polygon-amoy", x402.PolygonAmoy.USDCAddress, polygonRecipient, "USDC"},
+	}
+	if len(requirements) != len(want) {
+		t.Fatalf("expected %d requirements, got %d", len(want), len(requirements))
+	}
+	for i, w := range want {
+		got := requirements[i]
+		if got.Network != w.network || got.Asset != w.asset || got.PayTo != w.payTo || got.Extra["name"] != w.name {
+			t.Errorf("requirement %d = %s/%s to %s (%v), want %s/%s to %s (%s)",
+				i, got.Network, got.Asset, got.PayTo, got.Extra["name"], w.network, w.asset, w.payTo, w.name)
+		}
+		if got.MaxAmountRequired != "500000" {
+			t.Errorf("requirement %d amount = %s, want 500000", i, got.MaxAmountRequired)
+		}
+	}
+
+	if info.Network != "base-sepolia" || info.Currency != "USDC" {
+		t.Errorf("payment info = %+v, want primary option", info)
+	}
+	if eurc := m.PaymentInfoFor(info.Amount, requirements[1]); eurc.Currency != "EURC" {
+		t.Errorf("PaymentInfoFor() currency = %s, want EURC", eurc.Currency)
+	}
+}
+
+func TestNew_NetworksWithoutPrimary(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		wantErr  error
+		wantNets int
+	}{
+		{
+			name: "networks only",
+			config: Config{
+				Networks: map[string]NetworkConfig{
+					"base": {RecipientAddress: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C"},
+				},
+			},
+			wantNets: 1,
+		},
+		{
+			name: "network without recipient",
+			config: Config{
+				Networks: map[string]NetworkConfig{"base": {}},
+			},
+			wantErr: ErrMissingRecipient,
+		},
+		{
+			name: "unknown network",
+			config: Config{
+				RecipientAddress: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+				Networks:         map[string]NetworkConfig{"ethereum-classic": {}},
+			},
+			wantErr: ErrNetworkNotSupported,
+		},
+		{
+			name:    "nothing configured",
+			config:  Config{},
+			wantErr: ErrMissingRecipient,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.FacilitatorURL = "http://127.0.0.1:0"
+			config.PricingStrategy = fixedPrice(decimal.RequireFromString("1"))
+
+			m, err := New(&config)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("New() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && len(m.options) != tt.wantNets {
+				t.Errorf("expected %d options, got %d", tt.wantNets, len(m.options))
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package x402
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 
 	x402 "github.com/dexfra-fun/x402-go"
@@ -15,6 +17,18 @@ func MapNetworkToChain(network string) (x402.ChainConfig, error) {
 		return x402.SolanaDevnet, nil
 	case "solana-mainnet", "solana":
 		return x402.SolanaMainnet, nil
+	case "base":
+		return x402.BaseMainnet, nil
+	case "base-sepolia":
+		return x402.BaseSepolia, nil
+	case "polygon":
+		return x402.PolygonMainnet, nil
+	case "polygon-amoy":
+		return x402.PolygonAmoy, nil
+	case "avalanche":
+		return x402.AvalancheMainnet, nil
+	case "avalanche-fuji":
+		return x402.AvalancheFuji, nil
 	default:
 		return x402.ChainConfig{}, ErrNetworkNotSupported
 	}
@@ -33,6 +47,36 @@ func GetDefaultNetworks() map[string]NetworkConfig {
 			Name:        "Solana Mainnet",
 			ChainConfig: x402.SolanaMainnet,
 		},
+		"base": {
+			ChainID:     "8453",
+			Name:        "Base",
+			ChainConfig: x402.BaseMainnet,
+		},
+		"base-sepolia": {
+			ChainID:     "84532",
+			Name:        "Base Sepolia",
+			ChainConfig: x402.BaseSepolia,
+		},
+		"polygon": {
+			ChainID:     "137",
+			Name:        "Polygon",
+			ChainConfig: x402.PolygonMainnet,
+		},
+		"polygon-amoy": {
+			ChainID:     "80002",
+			Name:        "Polygon Amoy",
+			ChainConfig: x402.PolygonAmoy,
+		},
+		"avalanche": {
+			ChainID:     "43114",
+			Name:        "Avalanche C-Chain",
+			ChainConfig: x402.AvalancheMainnet,
+		},
+		"avalanche-fuji": {
+			ChainID:     "43113",
+			Name:        "Avalanche Fuji",
+			ChainConfig: x402.AvalancheFuji,
+		},
 	}
 }
 
@@ -49,3 +93,92 @@ func IsNetworkSupported(network string) bool {
 
 	return false
 }
+
+// paymentOption is a single network/asset combination offered in 402 responses.
+type paymentOption struct {
+	chain     x402.ChainConfig
+	asset     AssetConfig
+	recipient string
+	feePayer  string
+}
+
+// resolvePaymentOptions expands Config.Network and Config.Networks into payment options.
+// The primary network comes first, followed by Networks ordered by priority and then name.
+func resolvePaymentOptions(config *Config) ([]paymentOption, error) {
+	type entry struct {
+		key     string
+		network NetworkConfig
+	}
+
+	entries := make([]entry, 0, len(config.Networks)+1)
+	for key, network := range config.Networks {
+		entries = append(entries, entry{key: key, network: network})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].network.Priority != entries[j].network.Priority {
+			return entries[i].network.Priority < entries[j].network.Priority
+		}
+		return entries[i].key < entries[j].key
+	})
+
+	if config.Network != "" {
+		primary := entry{key: config.Network}
+		for i, e := range entries {
+			if strings.EqualFold(e.key, config.Network) {
+				primary = e
+				entries = append(entries[:i], entries[i+1:]...)
+				break
+			}
+		}
+		entries = append([]entry{primary}, entries...)
+	}
+
+	var options []paymentOption
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		chain := e.network.ChainConfig
+		if chain.NetworkID == "" {
+			var err error
+			if chain, err = MapNetworkToChain(e.key); err != nil {
+				return nil, fmt.Errorf("%w: %s", err, e.key)
+			}
+		}
+
+		recipient := e.network.RecipientAddress
+		if recipient == "" {
+			recipient = config.RecipientAddress
+		}
+		if recipient == "" {
+			return nil, fmt.Errorf("%w: %s", ErrMissingRecipient, e.key)
+		}
+
+		feePayer := e.network.FeePayer
+		if feePayer == "" {
+			feePayer = config.FeePayer
+		}
+
+		assets := e.network.Assets
+		if len(assets) == 0 {
+			assets = []AssetConfig{NewUSDCAsset(chain)}
+		}
+
+		for _, asset := range assets {
+			if asset.Address == "" || asset.Decimals < 0 {
+				return nil, fmt.Errorf("%w: %s %q", ErrInvalidAsset, chain.NetworkID, asset.Address)
+			}
+			id := chain.NetworkID + "/" + strings.ToLower(asset.Address)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			options = append(options, paymentOption{
+				chain:     chain,
+				asset:     asset,
+				recipient: recipient,
+				feePayer:  feePayer,
+			})
+		}
+	}
+
+	return options, nil
+}
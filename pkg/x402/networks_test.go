@@ -11,6 +11,10 @@ func TestMapNetworkToChain(t *testing.T) {
 		{"solana devnet", "solana-devnet", false},
 		{"solana mainnet", "solana-mainnet", false},
 		{"solana alias", "solana", false},
+		{"base", "base", false},
+		{"base sepolia", "base-sepolia", false},
+		{"polygon", "polygon", false},
+		{"avalanche", "avalanche", false},
 		{"unknown network", "unknown", true},
 		{"empty string", "", true},
 	}
@@ -33,6 +37,8 @@ func TestIsNetworkSupported(t *testing.T) {
 	}{
 		{"solana devnet", "solana-devnet", true},
 		{"solana mainnet", "solana-mainnet", true},
+		{"base", "base", true},
+		{"avalanche fuji", "avalanche-fuji", true},
 		{"unknown", "ethereum", false},
 		{"empty", "", false},
 	}
@@ -0,0 +1,70 @@
+package x402
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/internal/svm"
+)
+
+// NonceStore records payment nonces so that a payment header cannot be
+// verified or settled twice, including concurrently across replicas.
+//
+// A key is reserved before verification, committed after successful
+// settlement, and released when the payment is rejected so it may be retried.
+type NonceStore interface {
+	// Reserve claims key for ttl. It returns ErrNonceInFlight if the key is
+	// reserved by another request, or ErrNonceUsed if it was already committed.
+	Reserve(ctx context.Context, key string, ttl time.Duration) error
+
+	// Commit marks a reserved key as used for the remainder of its TTL.
+	Commit(ctx context.Context, key string) error
+
+	// Release removes a reservation that was not committed.
+	Release(ctx context.Context, key string) error
+}
+
+// PaymentNonceKey returns the replay-protection key for a payment made against requirement.
+//
+// EVM payments are keyed on the token, authorizer and EIP-3009 nonce. Solana
+// payments are keyed on the client's transaction signature.
+func PaymentNonceKey(payment x402.PaymentPayload, requirement x402.PaymentRequirement) (string, error) {
+	if chain, ok := x402.GetChainConfig(requirement.Network); ok && chain.IsEVM() {
+		payload, err := payment.DecodeEVMPayload()
+		if err != nil {
+			return "", err
+		}
+		if payload.Authorization.Nonce == "" || payload.Authorization.From == "" {
+			return "", fmt.Errorf("%w: missing authorization nonce", x402.ErrMalformedHeader)
+		}
+		return strings.ToLower(strings.Join([]string{
+			"evm", requirement.Network, requirement.Asset,
+			payload.Authorization.From, payload.Authorization.Nonce,
+		}, ":")), nil
+	}
+
+	payload, err := payment.DecodeSVMPayload()
+	if err != nil {
+		return "", err
+	}
+	raw, err := base64.StdEncoding.DecodeString(payload.Transaction)
+	if err != nil {
+		return "", fmt.Errorf("%w: transaction: %w", x402.ErrMalformedHeader, err)
+	}
+	var tx svm.Transaction
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return "", fmt.Errorf("%w: %w", x402.ErrMalformedHeader, err)
+	}
+
+	// The fee payer slot is empty until settlement; the client's signature is unique
+	for _, signature := range tx.Signatures {
+		if !signature.IsZero() {
+			return "svm:" + requirement.Network + ":" + signature.String(), nil
+		}
+	}
+	return "", fmt.Errorf("%w: transaction is not signed", x402.ErrMalformedHeader)
+}
@@ -0,0 +1,79 @@
+package x402
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	evmsigner "github.com/dexfra-fun/x402-go/pkg/signers/evm"
+	svmsigner "github.com/dexfra-fun/x402-go/pkg/signers/svm"
+)
+
+func TestPaymentNonceKey_EVM(t *testing.T) {
+	requirement := x402.PaymentRequirement{
+		Scheme: "exact", Network: "base-sepolia", MaxAmountRequired: "1000",
+		Asset: x402.BaseSepolia.USDCAddress, PayTo: "0x209693Bc6afc0C5328bA36FaF03C514EF312287C",
+		MaxTimeoutSeconds: 60,
+	}
+	signer, err := evmsigner.NewSignerFromHex("base-sepolia",
+		"4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatalf("NewSignerFromHex() error = %v", err)
+	}
+
+	first, _ := signer.Sign(context.Background(), &requirement)
+	second, _ := signer.Sign(context.Background(), &requirement)
+
+	key, err := PaymentNonceKey(*first, requirement)
+	if err != nil {
+		t.Fatalf("PaymentNonceKey() error = %v", err)
+	}
+	payload, _ := first.DecodeEVMPayload()
+	if !strings.HasPrefix(key, "evm:base-sepolia:") || !strings.HasSuffix(key, payload.Authorization.Nonce) {
+		t.Errorf("unexpected key %q", key)
+	}
+
+	// Re-encoding the same payment yields the same key; a new signature does not
+	again, _ := PaymentNonceKey(*first, requirement)
+	other, _ := PaymentNonceKey(*second, requirement)
+	if key != again || key == other {
+		t.Errorf("keys: first=%q again=%q other=%q", key, again, other)
+	}
+}
+
+func TestPaymentNonceKey_SVM(t *testing.T) {
+	const payTo = "2wKupLR9q6wXYppw8Gr2NvWxKBUqm4PPJKkQfoxHDBg4"
+	requirement := x402.PaymentRequirement{
+		Scheme: "exact", Network: "solana-devnet", MaxAmountRequired: "1000",
+		Asset: x402.SolanaDevnet.USDCAddress, PayTo: payTo,
+		Extra: map[string]any{"feePayer": "L54zkaPQFeTn1UsEqieEXBqWrPShiaZEPD7mS5WXfQg"},
+	}
+	signer, err := svmsigner.NewSigner("solana-devnet", ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)),
+		svmsigner.WithBlockhashProvider(svmsigner.BlockhashFunc(func(context.Context) (string, error) {
+			return "EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N", nil
+		})),
+	)
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	payment, err := signer.Sign(context.Background(), &requirement)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	key, err := PaymentNonceKey(*payment, requirement)
+	if err != nil {
+		t.Fatalf("PaymentNonceKey() error = %v", err)
+	}
+	if !strings.HasPrefix(key, "svm:solana-devnet:") || len(key) < len("svm:solana-devnet:")+80 {
+		t.Errorf("unexpected key %q", key)
+	}
+
+	malformed := x402.PaymentPayload{Payload: x402.SVMPayload{Transaction: "AAAA"}}
+	if _, err := PaymentNonceKey(malformed, requirement); !errors.Is(err, x402.ErrMalformedHeader) {
+		t.Errorf("PaymentNonceKey() error = %v, want ErrMalformedHeader", err)
+	}
+}
@@ -0,0 +1,491 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/pkg/facilitator"
+	"github.com/dexfra-fun/x402-go/pkg/facilitators"
+)
+
+const (
+	// defaultUnhealthyCooldown is how long a failing facilitator is deprioritized.
+	defaultUnhealthyCooldown = 30 * time.Second
+)
+
+// FacilitatorEndpoint identifies a facilitator in a FacilitatorPool.
+// Exactly one of URL, ID or Enum is used, in that order of precedence.
+type FacilitatorEndpoint struct {
+	// URL is the facilitator base URL.
+	URL string
+	// ID is a registry ID from pkg/facilitators (e.g. "coinbase").
+	ID string
+	// Enum is a registry FacilitatorID.
+	Enum x402.FacilitatorID
+	// Weight spreads traffic across healthy facilitators in proportion to its
+	// value. Facilitators with zero weight are tried in order after weighted ones.
+	Weight int
+	// Authorizer authenticates requests to the facilitator. It is required for
+	// registry facilitators that declare an auth scheme, such as "coinbase".
+	Authorizer facilitator.RequestAuthorizer
+}
+
+// resolve returns the endpoint's display name and base URL.
+func (e FacilitatorEndpoint) resolve() (string, string, error) {
+	if e.URL != "" {
+		return e.URL, e.URL, nil
+	}
+
+	id := e.ID
+	if id == "" {
+		id = e.Enum.IDString()
+	}
+	if id == "" {
+		return "", "", fmt.Errorf("%w: endpoint has no URL, ID or Enum", ErrMissingFacilitator)
+	}
+	f := facilitators.Get(id)
+	if f == nil {
+		return "", "", fmt.Errorf("%w: unknown facilitator %q", ErrMissingFacilitator, id)
+	}
+	if f.RequiresAuth() && e.Authorizer == nil {
+		return "", "", fmt.Errorf("%w: %q needs a %s authorizer", ErrMissingAuthorizer, f.ID, f.Auth)
+	}
+	return f.ID, f.URL, nil
+}
+
+// poolMember is a facilitator in the pool with its health state.
+type poolMember struct {
+	name   string
+	weight int
+	client *FacilitatorClient
+
+	mu             sync.Mutex
+	unhealthyUntil time.Time
+}
+
+// FacilitatorPool spreads requests over several facilitators and fails over
+// when one is unreachable or returns a 5xx status.
+//
+// Verification is idempotent and fails over on any transport error or 5xx.
+// Settlement only fails over when the facilitator cannot have acted on the
+// request: the request was never sent, or the facilitator answered 503.
+// Solana payments are only sent to facilitators whose fee payer matches the
+// requirement's extra.feePayer, since only that facilitator can co-sign.
+//
+// Members make a single attempt per request by default: failing over to the
+// next facilitator takes the place of retrying the same one.
+type FacilitatorPool struct {
+	members       []*poolMember
+	weighted      bool
+	cooldown      time.Duration
+	cacheTTL      time.Duration
+	logger        *slog.Logger
+	now           func() time.Time
+	clientOptions []ClientOption
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// PoolOption configures a FacilitatorPool.
+type PoolOption func(*FacilitatorPool)
+
+// WithPoolLogger sets the pool logger.
+//
+// Deprecated: use WithPoolSlog.
+func WithPoolLogger(logger Logger) PoolOption {
+	return func(p *FacilitatorPool) {
+		p.logger = newLogger(nil, logger, false)
+	}
+}
+
+// WithPoolSlog sets the structured logger of the pool and its members.
+func WithPoolSlog(logger *slog.Logger) PoolOption {
+	return func(p *FacilitatorPool) {
+		if logger != nil {
+			p.logger = logger
+		}
+	}
+}
+
+// WithUnhealthyCooldown sets how long a failing facilitator is tried last.
+func WithUnhealthyCooldown(cooldown time.Duration) PoolOption {
+	return func(p *FacilitatorPool) {
+		p.cooldown = cooldown
+	}
+}
+
+// WithPoolCacheTTL sets the fee payer cache TTL of each member.
+func WithPoolCacheTTL(ttl time.Duration) PoolOption {
+	return func(p *FacilitatorPool) {
+		p.cacheTTL = ttl
+	}
+}
+
+// WithPoolClientOptions sets options for each member's FacilitatorClient,
+// e.g. WithTimeouts or WithRetryPolicy to retry members before failing over.
+func WithPoolClientOptions(opts ...ClientOption) PoolOption {
+	return func(p *FacilitatorPool) {
+		p.clientOptions = append(p.clientOptions, opts...)
+	}
+}
+
+// NewFacilitatorPool creates a pool from an ordered or weighted list of facilitators.
+func NewFacilitatorPool(endpoints []FacilitatorEndpoint, opts ...PoolOption) (*FacilitatorPool, error) {
+	if len(endpoints) == 0 {
+		return nil, ErrMissingFacilitator
+	}
+
+	p := &FacilitatorPool{
+		cooldown: defaultUnhealthyCooldown,
+		cacheTTL: defaultCacheTTL,
+		logger:   newLogger(nil, nil, false),
+		now:      time.Now,
+		stop:     make(chan struct{}),
+
+		clientOptions: []ClientOption{WithRetryPolicy(RetryPolicy{MaxAttempts: 1})},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	for _, endpoint := range endpoints {
+		name, baseURL, err := endpoint.resolve()
+		if err != nil {
+			return nil, err
+		}
+		if endpoint.Weight > 0 {
+			p.weighted = true
+		}
+		clientOptions := append([]ClientOption{WithSlog(p.logger)}, p.clientOptions...)
+		if endpoint.Authorizer != nil {
+			clientOptions = append(clientOptions[:len(clientOptions):len(clientOptions)], WithAuthorizer(endpoint.Authorizer))
+		}
+		p.members = append(p.members, &poolMember{
+			name:   name,
+			weight: endpoint.Weight,
+			client: NewFacilitatorClient(baseURL, NewFeePayerCache(p.cacheTTL), nil, clientOptions...),
+		})
+	}
+
+	return p, nil
+}
+
+var _ facilitator.Interface = (*FacilitatorPool)(nil)
+
+// newFacilitatorPool builds the pool described by a config.
+func newFacilitatorPool(config *Config, clientOptions ...ClientOption) (*FacilitatorPool, error) {
+	endpoints := config.Facilitators
+	if config.FacilitatorURL != "" {
+		primary := FacilitatorEndpoint{URL: config.FacilitatorURL, Authorizer: config.FacilitatorAuthorizer}
+		endpoints = append([]FacilitatorEndpoint{primary}, endpoints...)
+	}
+
+	pool, err := NewFacilitatorPool(endpoints,
+		WithPoolSlog(config.Slog),
+		WithPoolCacheTTL(config.CacheTTL),
+		WithPoolClientOptions(clientOptions...),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if config.FacilitatorHealthInterval > 0 {
+		pool.StartHealthChecks(config.FacilitatorHealthInterval)
+	}
+	return pool, nil
+}
+
+// GetFeePayer returns the fee payer of the first available facilitator supporting network.
+func (p *FacilitatorPool) GetFeePayer(ctx context.Context, network string) (string, error) {
+	err := ErrFeePayerNotFound
+	for _, member := range p.order() {
+		feePayer, memberErr := member.client.GetFeePayer(ctx, network)
+		if memberErr == nil {
+			return feePayer, nil
+		}
+		if !errors.Is(memberErr, ErrFeePayerNotFound) {
+			p.fail(member, "get fee payer", memberErr)
+			err = fmt.Errorf("%w: %w", ErrFacilitatorUnavailable, memberErr)
+		}
+	}
+	return "", err
+}
+
+// RefreshFeePayers fetches the fee payers of every member for networks.
+func (p *FacilitatorPool) RefreshFeePayers(ctx context.Context, networks ...string) error {
+	var errs []error
+	for _, member := range p.members {
+		if err := member.client.RefreshFeePayers(ctx, networks...); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", member.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Supported returns the supported kinds of the first available facilitator.
+func (p *FacilitatorPool) Supported(ctx context.Context) (*facilitator.SupportedResponse, error) {
+	var err error
+	for _, member := range p.order() {
+		supported, memberErr := member.client.Supported(ctx)
+		if memberErr == nil {
+			return supported, nil
+		}
+		p.fail(member, "get supported", memberErr)
+		err = memberErr
+	}
+	return nil, fmt.Errorf("%w: %w", ErrFacilitatorUnavailable, err)
+}
+
+// Verify verifies a payment, failing over on transport errors and 5xx responses.
+func (p *FacilitatorPool) Verify(
+	ctx context.Context,
+	payment x402.PaymentPayload,
+	requirement x402.PaymentRequirement,
+) (*facilitator.VerifyResponse, error) {
+	members := p.candidates(ctx, requirement)
+	if len(members) == 0 {
+		return nil, ErrFacilitatorUnavailable
+	}
+
+	var err error
+	for _, member := range members {
+		verification, memberErr := member.client.Verify(ctx, payment, requirement)
+		if memberErr == nil {
+			return verification, nil
+		}
+		if !isRetryable(memberErr) || ctx.Err() != nil {
+			return nil, memberErr
+		}
+		p.fail(member, "verify", memberErr)
+		err = memberErr
+	}
+	return nil, fmt.Errorf("%w: %w", ErrFacilitatorUnavailable, err)
+}
+
+// Settle settles a payment, failing over only when the facilitator cannot
+// have acted on the request, so a payment is never settled twice.
+func (p *FacilitatorPool) Settle(
+	ctx context.Context,
+	payment x402.PaymentPayload,
+	requirement x402.PaymentRequirement,
+) (*x402.SettlementResponse, error) {
+	members := p.candidates(ctx, requirement)
+	if len(members) == 0 {
+		return nil, ErrFacilitatorUnavailable
+	}
+
+	var err error
+	for _, member := range members {
+		settlement, memberErr := member.client.Settle(ctx, payment, requirement)
+		if memberErr == nil {
+			return settlement, nil
+		}
+		if isRetryable(memberErr) {
+			p.fail(member, "settle", memberErr)
+		}
+		if !isSettleRetryable(memberErr) || ctx.Err() != nil {
+			return nil, memberErr
+		}
+		err = memberErr
+	}
+	return nil, fmt.Errorf("%w: %w", ErrFacilitatorUnavailable, err)
+}
+
+// Unavailable reports whether the circuits of all members are open, and the
+// shortest time until one of them is probed again.
+func (p *FacilitatorPool) Unavailable() (time.Duration, bool) {
+	var shortest time.Duration
+	for i, member := range p.members {
+		retryAfter, down := member.client.Unavailable()
+		if !down {
+			return 0, false
+		}
+		if i == 0 || retryAfter < shortest {
+			shortest = retryAfter
+		}
+	}
+	return shortest, true
+}
+
+// CheckHealth probes every facilitator's /supported endpoint and updates its health.
+func (p *FacilitatorPool) CheckHealth(ctx context.Context) {
+	for _, member := range p.members {
+		// Probe the facilitator itself, not a cached response
+		if _, err := member.client.fetchSupported(ctx); err != nil {
+			p.fail(member, "health check", err)
+			continue
+		}
+		member.mu.Lock()
+		member.unhealthyUntil = time.Time{}
+		member.mu.Unlock()
+	}
+}
+
+// StartHealthChecks runs CheckHealth every interval until Close is called.
+func (p *FacilitatorPool) StartHealthChecks(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	p.wg.Go(func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				p.CheckHealth(ctx)
+				cancel()
+			}
+		}
+	})
+}
+
+// Close stops background health checks and waits for a probe in flight to
+// finish. It may be called more than once.
+func (p *FacilitatorPool) Close() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+	p.wg.Wait()
+}
+
+// candidates returns the members that may handle a requirement, in preference order.
+// For requirements with a fee payer, only facilitators using that fee payer qualify.
+func (p *FacilitatorPool) candidates(ctx context.Context, requirement x402.PaymentRequirement) []*poolMember {
+	members := p.order()
+
+	feePayer, _ := requirement.Extra["feePayer"].(string)
+	if feePayer == "" {
+		return members
+	}
+
+	matching := make([]*poolMember, 0, len(members))
+	for _, member := range members {
+		if member.client.hasFeePayer(ctx, requirement.Network, feePayer) {
+			matching = append(matching, member)
+		}
+	}
+	return matching
+}
+
+// hasFeePayer reports whether any member uses feePayer on a network.
+func (p *FacilitatorPool) hasFeePayer(ctx context.Context, network, feePayer string) bool {
+	for _, member := range p.members {
+		if member.client.hasFeePayer(ctx, network, feePayer) {
+			return true
+		}
+	}
+	return false
+}
+
+// order returns healthy members first, weighted-shuffled or in configured
+// order, followed by members in their unhealthy cooldown.
+func (p *FacilitatorPool) order() []*poolMember {
+	now := p.now()
+	healthy := make([]*poolMember, 0, len(p.members))
+	var unhealthy []*poolMember
+	for _, member := range p.members {
+		member.mu.Lock()
+		down := now.Before(member.unhealthyUntil)
+		member.mu.Unlock()
+		if down {
+			unhealthy = append(unhealthy, member)
+		} else {
+			healthy = append(healthy, member)
+		}
+	}
+
+	if p.weighted {
+		healthy = weightedShuffle(healthy)
+	}
+	return append(healthy, unhealthy...)
+}
+
+// fail records a failure and puts the member in cooldown if the error is retryable.
+func (p *FacilitatorPool) fail(member *poolMember, op string, err error) {
+	if !isRetryable(err) {
+		return
+	}
+	p.logger.Warn("Facilitator failed, trying the next one", "facilitator", member.name, "op", op, "error", err)
+
+	member.mu.Lock()
+	member.unhealthyUntil = p.now().Add(p.cooldown)
+	member.mu.Unlock()
+}
+
+// weightedShuffle orders weighted members by weighted random sampling,
+// followed by zero-weight members in their original order.
+func weightedShuffle(members []*poolMember) []*poolMember {
+	var weighted, unweighted []*poolMember
+	total := 0
+	for _, member := range members {
+		if member.weight > 0 {
+			weighted = append(weighted, member)
+			total += member.weight
+		} else {
+			unweighted = append(unweighted, member)
+		}
+	}
+
+	ordered := make([]*poolMember, 0, len(members))
+	for len(weighted) > 0 {
+		pick := rand.IntN(total) //nolint:gosec // load spreading, not security sensitive
+		for i, member := range weighted {
+			if pick < member.weight {
+				ordered = append(ordered, member)
+				total -= member.weight
+				weighted = append(weighted[:i], weighted[i+1:]...)
+				break
+			}
+			pick -= member.weight
+		}
+	}
+	return append(ordered, unweighted...)
+}
+
+// isRetryable reports whether another facilitator may succeed where this one failed.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return true
+	}
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode >= http.StatusInternalServerError
+}
+
+// isSettleRetryable reports whether a failed settlement certainly did not reach
+// the facilitator or was explicitly not processed, so another may settle it.
+func isSettleRetryable(err error) bool {
+	if errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return !transportErr.RequestSent && !errors.Is(err, context.Canceled)
+	}
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusServiceUnavailable
+}
+
+// String returns the facilitator names in configured order.
+func (p *FacilitatorPool) String() string {
+	names := make([]string, len(p.members))
+	for i, member := range p.members {
+		names[i] = member.name
+	}
+	return "FacilitatorPool[" + strings.Join(names, ", ") + "]"
+}
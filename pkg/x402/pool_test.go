@@ -0,0 +1,223 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/pkg/facilitator"
+)
+
+// fakeFacilitator is a facilitator test server that counts requests per endpoint.
+type fakeFacilitator struct {
+	*httptest.Server
+	status   int
+	feePayer string
+	verifies atomic.Int32
+	settles  atomic.Int32
+}
+
+func newFakeFacilitator(t *testing.T, status int, feePayer string) *fakeFacilitator {
+	t.Helper()
+	f := &fakeFacilitator{status: status, feePayer: feePayer}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/supported":
+			fmt.Fprintf(w, `{"kinds":[{"x402Version":1,"scheme":"exact","network":"solana","extra":{"feePayer":%q}}]}`,
+				f.feePayer)
+			return
+		case "/verify":
+			f.verifies.Add(1)
+		case "/settle":
+			f.settles.Add(1)
+		}
+		if f.status != http.StatusOK {
+			w.WriteHeader(f.status)
+			return
+		}
+		if r.URL.Path == "/verify" {
+			_, _ = w.Write([]byte(`{"isValid":true,"payer":"payer"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true,"transaction":"tx","network":"solana","payer":"payer"}`))
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// unreachableURL returns the URL of a closed server, so connections are refused.
+func unreachableURL() string {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	return server.URL
+}
+
+func newTestPool(t *testing.T, endpoints ...FacilitatorEndpoint) *FacilitatorPool {
+	t.Helper()
+	pool, err := NewFacilitatorPool(endpoints)
+	if err != nil {
+		t.Fatalf("NewFacilitatorPool() error = %v", err)
+	}
+	return pool
+}
+
+var poolRequirement = x402.PaymentRequirement{Scheme: "exact", Network: "base"}
+
+func TestFacilitatorPool_VerifyFailover(t *testing.T) {
+	failing := newFakeFacilitator(t, http.StatusBadGateway, "")
+	healthy := newFakeFacilitator(t, http.StatusOK, "")
+	pool := newTestPool(t, FacilitatorEndpoint{URL: failing.URL}, FacilitatorEndpoint{URL: healthy.URL})
+
+	for range 2 {
+		verification, err := pool.Verify(context.Background(), x402.PaymentPayload{}, poolRequirement)
+		if err != nil || !verification.IsValid {
+			t.Fatalf("Verify() = %+v, %v; want valid", verification, err)
+		}
+	}
+
+	// The failing facilitator is in cooldown after the first attempt
+	if got := failing.verifies.Load(); got != 1 {
+		t.Errorf("failing facilitator verified %d times, want 1", got)
+	}
+	if got := healthy.verifies.Load(); got != 2 {
+		t.Errorf("healthy facilitator verified %d times, want 2", got)
+	}
+}
+
+func TestFacilitatorPool_SettleFailover(t *testing.T) {
+	tests := []struct {
+		name        string
+		first       func(t *testing.T) string
+		wantErr     bool
+		wantSettles int32
+	}{
+		{
+			name:        "unreachable facilitator fails over",
+			first:       func(*testing.T) string { return unreachableURL() },
+			wantSettles: 1,
+		},
+		{
+			name: "service unavailable fails over",
+			first: func(t *testing.T) string {
+				return newFakeFacilitator(t, http.StatusServiceUnavailable, "").URL
+			},
+			wantSettles: 1,
+		},
+		{
+			name: "server error after request sent does not fail over",
+			first: func(t *testing.T) string {
+				return newFakeFacilitator(t, http.StatusInternalServerError, "").URL
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			second := newFakeFacilitator(t, http.StatusOK, "")
+			pool := newTestPool(t, FacilitatorEndpoint{URL: tt.first(t)}, FacilitatorEndpoint{URL: second.URL})
+
+			settlement, err := pool.Settle(context.Background(), x402.PaymentPayload{}, poolRequirement)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Settle() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && settlement.Transaction != "tx" {
+				t.Errorf("expected settlement from second facilitator, got %+v", settlement)
+			}
+			if got := second.settles.Load(); got != tt.wantSettles {
+				t.Errorf("second facilitator settled %d times, want %d", got, tt.wantSettles)
+			}
+		})
+	}
+}
+
+func TestFacilitatorPool_FeePayerRouting(t *testing.T) {
+	other := newFakeFacilitator(t, http.StatusOK, "OtherFeePayer")
+	owner := newFakeFacilitator(t, http.StatusOK, "OwnerFeePayer")
+	pool := newTestPool(t, FacilitatorEndpoint{URL: other.URL}, FacilitatorEndpoint{URL: owner.URL})
+
+	requirement := x402.PaymentRequirement{
+		Scheme:  "exact",
+		Network: "solana",
+		Extra:   map[string]any{"feePayer": "OwnerFeePayer"},
+	}
+	if _, err := pool.Settle(context.Background(), x402.PaymentPayload{}, requirement); err != nil {
+		t.Fatalf("Settle() error = %v", err)
+	}
+	if other.settles.Load() != 0 || owner.settles.Load() != 1 {
+		t.Errorf("expected settlement only by the fee payer's facilitator, got other=%d owner=%d",
+			other.settles.Load(), owner.settles.Load())
+	}
+
+	requirement.Extra["feePayer"] = "UnknownFeePayer"
+	_, err := pool.Settle(context.Background(), x402.PaymentPayload{}, requirement)
+	if !errors.Is(err, ErrFacilitatorUnavailable) {
+		t.Errorf("expected ErrFacilitatorUnavailable, got %v", err)
+	}
+}
+
+func TestFacilitatorPool_CheckHealth(t *testing.T) {
+	down := unreachableURL()
+	healthy := newFakeFacilitator(t, http.StatusOK, "")
+	pool := newTestPool(t, FacilitatorEndpoint{URL: down}, FacilitatorEndpoint{URL: healthy.URL})
+
+	pool.CheckHealth(context.Background())
+
+	order := pool.order()
+	if order[0].name != healthy.URL || order[1].name != down {
+		t.Errorf("expected healthy facilitator first, got %s, %s", order[0].name, order[1].name)
+	}
+}
+
+func TestFacilitatorPool_CloseWaitsForHealthChecks(t *testing.T) {
+	var probes, inFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		inFlight.Add(1)
+		defer inFlight.Add(-1)
+		probes.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"kinds":[]}`))
+	}))
+	defer server.Close()
+
+	pool := newTestPool(t, FacilitatorEndpoint{URL: server.URL})
+	pool.StartHealthChecks(50 * time.Millisecond)
+	for probes.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	pool.Close()
+	if n := inFlight.Load(); n != 0 {
+		t.Errorf("%d health checks still in flight after Close", n)
+	}
+	after := probes.Load()
+	time.Sleep(100 * time.Millisecond)
+	if n := probes.Load(); n != after {
+		t.Errorf("%d health checks ran after Close", n-after)
+	}
+	pool.Close()
+}
+
+func TestNewFacilitatorPool_Registry(t *testing.T) {
+	pool := newTestPool(t,
+		FacilitatorEndpoint{ID: "coinbase", Authorizer: facilitator.BearerToken("test")},
+		FacilitatorEndpoint{Enum: x402.FacilitatorIDPayAI},
+	)
+	if got := pool.String(); got != "FacilitatorPool[coinbase, payAI]" {
+		t.Errorf("String() = %s", got)
+	}
+
+	if _, err := NewFacilitatorPool([]FacilitatorEndpoint{{ID: "coinbase"}}); !errors.Is(err, ErrMissingAuthorizer) {
+		t.Errorf("expected ErrMissingAuthorizer without authorizer, got %v", err)
+	}
+
+	if _, err := NewFacilitatorPool([]FacilitatorEndpoint{{ID: "unknown"}}); !errors.Is(err, ErrMissingFacilitator) {
+		t.Errorf("expected ErrMissingFacilitator for unknown ID, got %v", err)
+	}
+}
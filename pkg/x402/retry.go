@@ -0,0 +1,87 @@
+package x402
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+)
+
+const (
+	// defaultMaxAttempts is the default number of attempts per facilitator request.
+	defaultMaxAttempts = 3
+
+	// defaultInitialBackoff is the default delay cap before the first retry.
+	defaultInitialBackoff = 100 * time.Millisecond
+
+	// defaultMaxBackoff is the default upper bound of the delay between retries.
+	defaultMaxBackoff = 2 * time.Second
+)
+
+// RetryPolicy configures retries of failed facilitator requests.
+//
+// Retries use exponential backoff with full jitter: before retry n the client
+// sleeps a random duration up to min(InitialBackoff*2^n, MaxBackoff).
+// /supported and /verify are retried on transport errors and 5xx responses.
+// /settle is only retried when the request was never sent or the facilitator
+// answered 503, so a payment is never submitted twice.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Set to 1 to disable retries. Defaults to 3.
+	MaxAttempts int
+	// InitialBackoff caps the delay before the first retry. Defaults to 100ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between any two attempts. Defaults to 2s.
+	MaxBackoff time.Duration
+}
+
+// withDefaults returns the policy with zero fields set to their defaults.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultMaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = defaultInitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultMaxBackoff
+	}
+	return p
+}
+
+// backoff returns the jittered delay before retry number retry (starting at 0).
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	limit := p.MaxBackoff
+	if shifted := p.InitialBackoff << retry; retry < 32 && shifted > 0 && shifted < limit {
+		limit = shifted
+	}
+	return rand.N(limit + 1) //nolint:gosec // jitter, not security sensitive
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// timeoutsWithDefaults returns timeouts with zero fields set to x402.NewDefaultTimeouts.
+func timeoutsWithDefaults(timeouts x402.TimeoutConfig) x402.TimeoutConfig {
+	defaults := x402.NewDefaultTimeouts()
+	if timeouts.VerifyTimeout <= 0 {
+		timeouts.VerifyTimeout = defaults.VerifyTimeout
+	}
+	if timeouts.SettleTimeout <= 0 {
+		timeouts.SettleTimeout = defaults.SettleTimeout
+	}
+	if timeouts.RequestTimeout <= 0 {
+		timeouts.RequestTimeout = defaults.RequestTimeout
+	}
+	return timeouts
+}
@@ -0,0 +1,55 @@
+package x402
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation name of the middleware's tracer.
+const TracerName = "github.com/dexfra-fun/x402-go"
+
+// Span attribute keys set by the middleware.
+const (
+	AttrRoute       = attribute.Key("x402.route")
+	AttrNetwork     = attribute.Key("x402.network")
+	AttrScheme      = attribute.Key("x402.scheme")
+	AttrAsset       = attribute.Key("x402.asset")
+	AttrAmount      = attribute.Key("x402.amount")
+	AttrPayer       = attribute.Key("x402.payer")
+	AttrTransaction = attribute.Key("x402.transaction")
+	AttrFacilitator = attribute.Key("x402.facilitator.url")
+	AttrEndpoint    = attribute.Key("x402.facilitator.endpoint")
+	AttrErrorCode   = attribute.Key("x402.error.code")
+)
+
+// Span event names.
+const (
+	EventCacheHit   = "cache hit"
+	EventCacheMiss  = "cache miss"
+	EventCacheStale = "cache stale"
+)
+
+// Tracer returns the middleware's tracer, for adapters adding spans of their own.
+func (m *Middleware) Tracer() trace.Tracer {
+	return m.tracer
+}
+
+// cacheEvent adds a cache hit, miss or stale event to the current span.
+func cacheEvent(ctx context.Context, event, cache, key string) {
+	trace.SpanFromContext(ctx).AddEvent(event, trace.WithAttributes(
+		attribute.String("x402.cache", cache),
+		attribute.String("x402.cache.key", key),
+	))
+}
+
+// endSpan records err, if any, on span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
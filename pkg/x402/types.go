@@ -2,15 +2,24 @@ package x402
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"time"
 
 	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/pkg/facilitator"
 	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	// defaultCacheTTL is the default cache time-to-live duration.
 	defaultCacheTTL = 5 * time.Minute
+
+	// defaultSettleStatusThreshold is the default status below which deferred payments settle.
+	defaultSettleStatusThreshold = 400
 )
 
 // PricingStrategy defines how to price API resources.
@@ -36,9 +45,19 @@ type Resource struct {
 	Path   string
 	Method string
 	Params map[string]string
+	// RequestID is the request's X-Request-ID header, if any.
+	RequestID string
+	// AccessToken is the request's X-Payment-Token header, if any.
+	AccessToken string
+	// CreditAuthorization is the request's X-Credit-Authorization header, if any.
+	CreditAuthorization string
 }
 
 // Config holds the configuration for x402 middleware.
+//
+// Network is the primary payment option and is listed first in 402 responses.
+// Networks adds further options (other chains or assets); either may be omitted
+// as long as at least one network is configured.
 type Config struct {
 	// Required fields
 	RecipientAddress string
@@ -49,17 +68,149 @@ type Config struct {
 	// Optional fields
 	SchemaProvider   SchemaProvider   // Optional: provides schema for API endpoints
 	ResourceProvider ResourceProvider // Optional: provides resource URL and description for payment requirements
-	FeePayer         string           // Optional: fallback fee payer if facilitator doesn't provide one
+	FeePayer         string           // Optional: fallback fee payer if facilitator doesn't provide one (Solana only)
 	CacheTTL         time.Duration
 	Networks         map[string]NetworkConfig
-	Logger           Logger
+
+	// Slog receives the middleware's structured logs (optional). Signatures,
+	// signed transactions and payment headers are redacted unless LogPayloads
+	// is set. Logs are discarded if neither Slog nor Logger is set.
+	Slog *slog.Logger
+	// Logger receives the logs through LoggerHandler if Slog is not set.
+	//
+	// Deprecated: use Slog, e.g. slog.New(NewLoggerHandler(logger, nil)).
+	Logger Logger
+	// LogPayloads logs facilitator request bodies unredacted at debug level,
+	// including signed transactions. Never enable it in production.
+	LogPayloads bool
+
+	// SettleAfterHandler defers settlement until the protected handler has run.
+	// The handler writes to a buffered response; the payment is settled and the
+	// response sent only if its status is below SettleStatusThreshold. Otherwise
+	// the handler's response is returned and no transfer happens.
+	SettleAfterHandler    bool
+	SettleStatusThreshold int // Optional: defaults to 400
+
+	// Facilitator verifies and settles payments instead of an HTTP facilitator at
+	// FacilitatorURL, e.g. an in-process, mocked or proxied implementation.
+	Facilitator facilitator.Interface
+
+	// Facilitators configures a failover pool instead of a single facilitator.
+	// If FacilitatorURL is also set, it is tried first. Members are health-checked
+	// every FacilitatorHealthInterval when it is positive.
+	Facilitators              []FacilitatorEndpoint
+	FacilitatorHealthInterval time.Duration
+
+	// Timeouts bounds facilitator requests; zero fields use x402.NewDefaultTimeouts.
+	// VerifyTimeout and SettleTimeout apply to each attempt, RequestTimeout to a
+	// whole call including retries.
+	Timeouts x402.TimeoutConfig
+
+	// Retry configures retries of failed requests to FacilitatorURL; zero fields
+	// use the defaults. A pool fails over to the next facilitator instead.
+	Retry RetryPolicy
+
+	// CircuitBreaker stops calling a facilitator that keeps failing (optional).
+	// Each facilitator in a pool gets its own breaker.
+	CircuitBreaker *BreakerConfig
+
+	// Degraded decides how paid routes are served while the facilitator is unavailable.
+	Degraded DegradedPolicy
+
+	// Recorder keeps a ledger of issued requirements, verifications and
+	// settlements (optional), e.g. a pkg/ledger JSONL file or SQL table.
+	Recorder PaymentRecorder
+
+	// Events receives payment verified, settled and failed events (optional)
+	// and delivers them to Go handlers and webhooks. The caller shuts it down.
+	Events *EventDispatcher
+
+	// AccessTokens issues pay-once access tokens after settlement (optional),
+	// so clients can make further requests without paying each time.
+	AccessTokens *AccessTokenConfig
+
+	// Credits enables prepaid credit balances topped up through x402 (optional).
+	Credits *CreditsConfig
+
+	// FeePayerCheck controls how fee payers reported by a registry facilitator
+	// are checked against its registered addresses (default FeePayerCheckWarn).
+	FeePayerCheck FeePayerCheck
+
+	// FeePayerRefreshInterval is how often fee payers are fetched in the
+	// background (optional, e.g. CacheTTL/2). Zero or a negative value disables
+	// background refresh, so fee payers are fetched on the request path when
+	// they expire. Set Context or call Close to stop the refresh.
+	FeePayerRefreshInterval time.Duration
+
+	// Context bounds the middleware's background work (optional). When it is
+	// done the middleware is closed, which stops middlewares built by an
+	// adapter's NewMiddleware, e.g. when a tenant is removed.
+	Context context.Context //nolint:containedctx // lifetime of the middleware's background work
+
+	// Metrics receives payment and facilitator events (optional), e.g. from
+	// pkg/metrics/prometheus.
+	Metrics Metrics
+
+	// TracerProvider creates the spans of the payment pipeline and of facilitator
+	// requests (optional). The global OpenTelemetry provider is used by default;
+	// trace context is propagated to facilitators with the global propagator.
+	TracerProvider trace.TracerProvider
+
+	// SupportedCache caches /supported responses (optional). Share one cache
+	// between middlewares talking to the same facilitators, e.g. one per tenant;
+	// the caller then closes it. By default each middleware has its own cache
+	// with CacheTTL, closed by Middleware.Close.
+	SupportedCache *SupportedCache
+
+	// HTTPClient sends facilitator requests, e.g. with a proxy or custom TLS
+	// configuration (optional). Its Timeout should be zero or exceed Timeouts.
+	HTTPClient *http.Client
+
+	// FacilitatorAuthorizer authenticates requests to FacilitatorURL, e.g.
+	// cdp.NewAuthorizer for the Coinbase facilitator. Pool members set their
+	// own FacilitatorEndpoint.Authorizer.
+	FacilitatorAuthorizer facilitator.RequestAuthorizer
+
+	// NonceStore rejects replayed and concurrently duplicated payments (optional).
+	// Use a shared store such as nonce.SQLStore when running several replicas.
+	NonceStore NonceStore
 }
 
 // NetworkConfig defines blockchain network configuration.
+// When used in Config.Networks, the map key is the network identifier.
 type NetworkConfig struct {
 	ChainID     string
 	Name        string
 	ChainConfig x402.ChainConfig
+
+	// Optional fields
+	RecipientAddress string        // Optional: overrides Config.RecipientAddress for this network
+	FeePayer         string        // Optional: fallback fee payer for this network (Solana only)
+	Assets           []AssetConfig // Optional: accepted tokens, defaults to the chain's USDC
+	Priority         int           // Optional: ordering in the accepts list (lower first)
+}
+
+// AssetConfig describes a token accepted as payment.
+// Prices are interpreted in whole units of the asset.
+type AssetConfig struct {
+	Address  string
+	Symbol   string
+	Decimals int
+
+	// EIP712Name and EIP712Version are the token's EIP-712 domain (EVM only).
+	EIP712Name    string
+	EIP712Version string
+}
+
+// NewUSDCAsset returns the USDC asset configuration for a chain.
+func NewUSDCAsset(chain x402.ChainConfig) AssetConfig {
+	return AssetConfig{
+		Address:       chain.USDCAddress,
+		Symbol:        "USDC",
+		Decimals:      int(chain.Decimals),
+		EIP712Name:    chain.EIP3009Name,
+		EIP712Version: chain.EIP3009Version,
+	}
 }
 
 // Logger defines the logging interface.
@@ -79,13 +230,13 @@ func (*DefaultLogger) Errorf(string, ...any) {}
 
 // Validate checks if the configuration is valid.
 func (c *Config) Validate() error {
-	if c.RecipientAddress == "" {
+	if c.RecipientAddress == "" && len(c.Networks) == 0 {
 		return ErrMissingRecipient
 	}
-	if c.Network == "" {
+	if c.Network == "" && len(c.Networks) == 0 {
 		return ErrMissingNetwork
 	}
-	if c.FacilitatorURL == "" {
+	if c.FacilitatorURL == "" && len(c.Facilitators) == 0 && c.Facilitator == nil {
 		return ErrMissingFacilitator
 	}
 	if c.PricingStrategy == nil {
@@ -96,17 +247,66 @@ func (c *Config) Validate() error {
 	if c.CacheTTL == 0 {
 		c.CacheTTL = defaultCacheTTL
 	}
+	if c.Metrics == nil {
+		c.Metrics = NopMetrics{}
+	}
+	if c.TracerProvider == nil {
+		c.TracerProvider = otel.GetTracerProvider()
+	}
+	c.Slog = newLogger(c.Slog, c.Logger, c.LogPayloads)
 	if c.Logger == nil {
 		c.Logger = &DefaultLogger{}
 	}
+	if c.SettleStatusThreshold == 0 {
+		c.SettleStatusThreshold = defaultSettleStatusThreshold
+	}
+	c.Timeouts = timeoutsWithDefaults(c.Timeouts)
+	if err := c.Timeouts.Validate(); err != nil {
+		return fmt.Errorf("x402: invalid timeouts: %w", err)
+	}
+	if err := c.Degraded.validate(); err != nil {
+		return err
+	}
+	if c.AccessTokens != nil {
+		if err := c.AccessTokens.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Credits != nil {
+		if err := c.Credits.validate(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// clientOptions returns the FacilitatorClient options shared by all facilitators.
+func (c *Config) clientOptions() []ClientOption {
+	opts := []ClientOption{
+		WithTimeouts(c.Timeouts),
+		WithFeePayerCheck(c.FeePayerCheck),
+		WithMetrics(c.Metrics),
+		WithTracerProvider(c.TracerProvider),
+		WithSlog(c.Slog),
+		WithLogPayloads(c.LogPayloads),
+	}
+	if c.CircuitBreaker != nil {
+		opts = append(opts, WithCircuitBreaker(*c.CircuitBreaker))
+	}
+	if c.HTTPClient != nil {
+		opts = append(opts, WithHTTPClient(c.HTTPClient))
+	}
+	return opts
+}
+
 // PaymentInfo contains payment metadata.
+// For paid requests it describes the payment option the client used.
 type PaymentInfo struct {
-	Amount    decimal.Decimal
-	Currency  string
-	Recipient string
-	FeePayer  string
+	Amount    decimal.Decimal `json:"amount"`
+	Currency  string          `json:"currency,omitempty"`
+	Recipient string          `json:"recipient,omitempty"`
+	FeePayer  string          `json:"feePayer,omitempty"`
+	Network   string          `json:"network,omitempty"`
+	Asset     string          `json:"asset,omitempty"`
 }
@@ -0,0 +1,116 @@
+package x402
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+const (
+	// HeaderWebhookEvent is the webhook header carrying the event type.
+	HeaderWebhookEvent = "X-X402-Event"
+	// HeaderWebhookID is the webhook header carrying the event ID.
+	HeaderWebhookID = "X-X402-Event-Id"
+	// HeaderWebhookTimestamp is the webhook header carrying the Unix time of the delivery.
+	HeaderWebhookTimestamp = "X-X402-Timestamp"
+	// HeaderWebhookSignature is the webhook header carrying "sha256=" and the
+	// hex HMAC-SHA256 of the timestamp, a dot and the body.
+	HeaderWebhookSignature = "X-X402-Signature"
+
+	// defaultWebhookTimeout bounds a webhook delivery attempt.
+	defaultWebhookTimeout = 10 * time.Second
+
+	// webhookSignaturePrefix prefixes the hex signature.
+	webhookSignaturePrefix = "sha256="
+)
+
+// Webhook delivers events as signed HTTP POST requests with a JSON Event body.
+// 2xx responses are successes; 4xx responses other than 408 and 429 are not retried.
+type Webhook struct {
+	URL string
+	// Secret signs the deliveries (see VerifyWebhook).
+	Secret []byte
+	// Types are the event types to deliver; all types if empty.
+	Types []EventType
+	// Client sends the requests (default: a client with a 10 second timeout).
+	Client *http.Client
+}
+
+// Deliver posts an event to the webhook. It is the webhook's EventHandler.
+func (w Webhook) Deliver(ctx context.Context, event Event) error {
+	body, err := sonic.Marshal(event)
+	if err != nil {
+		return Permanent(fmt.Errorf("marshal event: %w", err))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(fmt.Errorf("create webhook request: %w", err))
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookEvent, string(event.Type))
+	req.Header.Set(HeaderWebhookID, event.ID)
+	req.Header.Set(HeaderWebhookTimestamp, timestamp)
+	req.Header.Set(HeaderWebhookSignature, SignWebhook(w.Secret, timestamp, body))
+
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultWebhookTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	if resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+	return err
+}
+
+// SignWebhook returns the signature header value of a webhook body sent at timestamp.
+func SignWebhook(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook checks the signature of a received webhook, and that it was
+// sent within tolerance of now to limit replays. Receivers should also drop
+// event IDs they have already processed, since deliveries may be retried.
+func VerifyWebhook(secret []byte, header http.Header, body []byte, tolerance time.Duration) error {
+	timestamp := header.Get(HeaderWebhookTimestamp)
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing or malformed timestamp", ErrInvalidWebhookSignature)
+	}
+	if age := time.Since(time.Unix(sent, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidWebhookSignature)
+	}
+
+	signature := header.Get(HeaderWebhookSignature)
+	if !strings.HasPrefix(signature, webhookSignaturePrefix) ||
+		!hmac.Equal([]byte(signature), []byte(SignWebhook(secret, timestamp, body))) {
+		return ErrInvalidWebhookSignature
+	}
+	return nil
+}
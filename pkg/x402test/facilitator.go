@@ -0,0 +1,294 @@
+// Package x402test provides an in-process facilitator and payment helpers for
+// testing x402-protected handlers without network access.
+//
+//	f := x402test.NewFacilitator(t)
+//	handler := x402http.NewMiddleware(&x402.Config{FacilitatorURL: f.URL, ...})(next)
+//
+//	req := httptest.NewRequest(http.MethodGet, "/api", nil)
+//	req.Header.Set(x402.HeaderPayment, x402test.PaymentHeader(t, requirement))
+package x402test
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/pkg/facilitator"
+	"github.com/mr-tron/base58"
+)
+
+const (
+	// DefaultFeePayer is the fee payer advertised for Solana networks.
+	DefaultFeePayer = "5D3u1dWP8KiBM46Y8pZLYmR2RGnSVbeQqxvMQCumHWGg"
+
+	// DefaultPayer is returned by /verify when the payer cannot be read from the payment.
+	DefaultPayer = "payer"
+)
+
+// Facilitator endpoint paths.
+const (
+	PathSupported = "/supported"
+	PathVerify    = "/verify"
+	PathSettle    = "/settle"
+)
+
+// defaultNetworks are the networks advertised by /supported unless overridden.
+var defaultNetworks = []string{
+	"solana", "solana-devnet",
+	"base", "base-sepolia",
+	"polygon", "polygon-amoy",
+	"avalanche", "avalanche-fuji",
+}
+
+// Behavior controls how the facilitator responds. The zero value accepts and
+// settles every payment immediately.
+type Behavior struct {
+	// InvalidReason, if set, makes /verify report the payment as invalid.
+	InvalidReason string
+	// SettleErrorReason, if set, makes /settle report success=false.
+	SettleErrorReason string
+	// Latency delays every response.
+	Latency time.Duration
+	// SupportedStatus, VerifyStatus and SettleStatus, if set to a non-200 code,
+	// make the endpoint respond with that status and no body.
+	SupportedStatus int
+	VerifyStatus    int
+	SettleStatus    int
+}
+
+// Request is a request received by the facilitator.
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	// Payment and Requirement are decoded from /verify and /settle bodies.
+	Payment     *x402.PaymentPayload
+	Requirement *x402.PaymentRequirement
+}
+
+// Facilitator is an httptest.Server implementing the facilitator HTTP API.
+type Facilitator struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	behavior    Behavior
+	kinds       []facilitator.SupportedKind
+	requests    []Request
+	settlements int
+}
+
+// Option configures a Facilitator.
+type Option func(*Facilitator)
+
+// WithBehavior sets the initial behavior.
+func WithBehavior(behavior Behavior) Option {
+	return func(f *Facilitator) {
+		f.behavior = behavior
+	}
+}
+
+// WithKinds replaces the kinds advertised by /supported.
+func WithKinds(kinds ...facilitator.SupportedKind) Option {
+	return func(f *Facilitator) {
+		f.kinds = kinds
+	}
+}
+
+// NewFacilitator starts a facilitator server that is closed when the test ends.
+// By default it supports the "exact" scheme on all built-in networks, with
+// DefaultFeePayer on Solana.
+func NewFacilitator(tb testing.TB, opts ...Option) *Facilitator {
+	tb.Helper()
+
+	f := &Facilitator{kinds: DefaultKinds()}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	tb.Cleanup(f.Close)
+	return f
+}
+
+// DefaultKinds returns the kinds advertised by a new Facilitator.
+func DefaultKinds() []facilitator.SupportedKind {
+	kinds := make([]facilitator.SupportedKind, 0, len(defaultNetworks))
+	for _, network := range defaultNetworks {
+		kind := facilitator.SupportedKind{X402Version: 1, Scheme: x402.DefaultScheme, Network: network}
+		if chain, ok := x402.GetChainConfig(network); ok && !chain.IsEVM() {
+			kind.Extra = map[string]any{"feePayer": DefaultFeePayer}
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+// SetBehavior changes how subsequent requests are answered.
+func (f *Facilitator) SetBehavior(behavior Behavior) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.behavior = behavior
+}
+
+// Requests returns all requests received so far.
+func (f *Facilitator) Requests() []Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Request(nil), f.requests...)
+}
+
+// Count returns the number of requests received for an endpoint path.
+func (f *Facilitator) Count(path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	count := 0
+	for _, r := range f.requests {
+		if r.Path == path {
+			count++
+		}
+	}
+	return count
+}
+
+// Reset clears the recorded requests.
+func (f *Facilitator) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = nil
+}
+
+func (f *Facilitator) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	request := Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone()}
+	if r.Method == http.MethodPost {
+		var body struct {
+			PaymentPayload      *x402.PaymentPayload     `json:"paymentPayload"`
+			PaymentRequirements *x402.PaymentRequirement `json:"paymentRequirements"`
+		}
+		raw, _ := io.ReadAll(r.Body)
+		if err := sonic.Unmarshal(raw, &body); err == nil {
+			request.Payment = body.PaymentPayload
+			request.Requirement = body.PaymentRequirements
+		}
+	}
+
+	f.mu.Lock()
+	f.requests = append(f.requests, request)
+	behavior := f.behavior
+	f.mu.Unlock()
+
+	if behavior.Latency > 0 {
+		select {
+		case <-time.After(behavior.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch request.Path {
+	case PathSupported:
+		f.serveSupported(w, behavior)
+	case PathVerify:
+		f.serveVerify(w, request, behavior)
+	case PathSettle:
+		f.serveSettle(w, request, behavior)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *Facilitator) serveSupported(w http.ResponseWriter, behavior Behavior) {
+	if failed(w, behavior.SupportedStatus) {
+		return
+	}
+	f.mu.Lock()
+	kinds := f.kinds
+	f.mu.Unlock()
+	writeJSON(w, facilitator.SupportedResponse{Kinds: kinds})
+}
+
+func (*Facilitator) serveVerify(w http.ResponseWriter, request Request, behavior Behavior) {
+	if failed(w, behavior.VerifyStatus) {
+		return
+	}
+	if request.Payment == nil || request.Requirement == nil {
+		writeJSON(w, facilitator.VerifyResponse{InvalidReason: "invalid_payload"})
+		return
+	}
+
+	response := facilitator.VerifyResponse{IsValid: true, Payer: payerOf(*request.Payment)}
+	switch {
+	case behavior.InvalidReason != "":
+		response = facilitator.VerifyResponse{InvalidReason: behavior.InvalidReason, Payer: response.Payer}
+	case request.Payment.Network != request.Requirement.Network:
+		response = facilitator.VerifyResponse{InvalidReason: "invalid_network", Payer: response.Payer}
+	}
+	writeJSON(w, response)
+}
+
+func (f *Facilitator) serveSettle(w http.ResponseWriter, request Request, behavior Behavior) {
+	if failed(w, behavior.SettleStatus) {
+		return
+	}
+	if request.Payment == nil {
+		writeJSON(w, x402.SettlementResponse{ErrorReason: "invalid_payload"})
+		return
+	}
+
+	response := x402.SettlementResponse{
+		Network: request.Payment.Network,
+		Payer:   payerOf(*request.Payment),
+	}
+	if behavior.SettleErrorReason != "" {
+		response.ErrorReason = behavior.SettleErrorReason
+		writeJSON(w, response)
+		return
+	}
+
+	f.mu.Lock()
+	f.settlements++
+	n := f.settlements
+	f.mu.Unlock()
+
+	response.Success = true
+	response.Transaction = transactionHash(request.Payment.Network, n)
+	writeJSON(w, response)
+}
+
+// failed writes status and reports true if it is set and not 200.
+func failed(w http.ResponseWriter, status int) bool {
+	if status == 0 || status == http.StatusOK {
+		return false
+	}
+	w.WriteHeader(status)
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = sonic.ConfigDefault.NewEncoder(w).Encode(v)
+}
+
+// transactionHash returns a unique, correctly formatted transaction hash for a network.
+func transactionHash(network string, n int) string {
+	if chain, ok := x402.GetChainConfig(network); ok && !chain.IsEVM() {
+		var signature [64]byte
+		binary.BigEndian.PutUint64(signature[56:], uint64(n)) //nolint:gosec // counter is positive
+		return base58.Encode(signature[:])
+	}
+	var hash [32]byte
+	binary.BigEndian.PutUint64(hash[24:], uint64(n)) //nolint:gosec // counter is positive
+	return "0x" + hex.EncodeToString(hash[:])
+}
+
+// String describes the facilitator for test failure messages.
+func (f *Facilitator) String() string {
+	return fmt.Sprintf("x402test.Facilitator(%s)", f.URL)
+}
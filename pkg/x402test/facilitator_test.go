@@ -0,0 +1,152 @@
+package x402test_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	x402 "github.com/dexfra-fun/x402-go"
+	x402http "github.com/dexfra-fun/x402-go/pkg/adapters/http"
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+	"github.com/dexfra-fun/x402-go/pkg/x402test"
+	"github.com/shopspring/decimal"
+)
+
+type fixedPrice decimal.Decimal
+
+func (p fixedPrice) GetPrice(context.Context, localx402.Resource) (decimal.Decimal, error) {
+	return decimal.Decimal(p), nil
+}
+
+func newHandler(f *x402test.Facilitator, network, recipient string) http.Handler {
+	return x402http.NewMiddleware(&localx402.Config{
+		RecipientAddress: recipient,
+		Network:          network,
+		FacilitatorURL:   f.URL,
+		PricingStrategy:  fixedPrice(decimal.RequireFromString("0.01")),
+	})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("content"))
+	}))
+}
+
+// pay requests the resource unpaid, then pays the first accepted requirement.
+func pay(t *testing.T, handler http.Handler) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api", nil))
+	if rec.Code != http.StatusPaymentRequired {
+		t.Fatalf("unpaid request: status = %d, want 402", rec.Code)
+	}
+	requirements := x402test.PaymentRequirements(t, rec.Body)
+
+	req := httptest.NewRequest(http.MethodGet, "/api", nil)
+	req.Header.Set(localx402.HeaderPayment, x402test.PaymentHeader(t, requirements[0]))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestFacilitator_EndToEnd(t *testing.T) {
+	tests := []struct {
+		name      string
+		network   string
+		recipient string
+	}{
+		{"evm", "base-sepolia", "0x209693Bc6afc0C5328bA36FaF03C514EF312287C"},
+		{"svm", "solana-devnet", "9xQeWvG816bUx9EPjHmaT23yvVM2ZWbrrpZb9PusVFin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := x402test.NewFacilitator(t)
+			rec := pay(t, newHandler(f, tt.network, tt.recipient))
+
+			if rec.Code != http.StatusOK || rec.Body.String() != "content" {
+				t.Fatalf("paid request: status = %d body = %q", rec.Code, rec.Body.String())
+			}
+			settlement, err := localx402.DecodeSettlement(rec.Header().Get(localx402.HeaderPaymentResponse))
+			if err != nil || !settlement.Success || settlement.Transaction == "" {
+				t.Errorf("settlement = %+v, %v", settlement, err)
+			}
+			if f.Count(x402test.PathVerify) != 1 || f.Count(x402test.PathSettle) != 1 {
+				t.Errorf("expected one verify and one settle, got %d and %d",
+					f.Count(x402test.PathVerify), f.Count(x402test.PathSettle))
+			}
+
+			for _, r := range f.Requests() {
+				if r.Path == x402test.PathSettle && r.Requirement.PayTo != tt.recipient {
+					t.Errorf("settle payTo = %s, want %s", r.Requirement.PayTo, tt.recipient)
+				}
+			}
+		})
+	}
+}
+
+// checkErrorResponse asserts the error code in a failed response and that 402
+// responses advertise the requirements again.
+func checkErrorResponse(t *testing.T, rec *httptest.ResponseRecorder, want x402.ErrorCode) {
+	t.Helper()
+
+	var body localx402.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode error response %q: %v", rec.Body.String(), err)
+	}
+	if body.Error != string(want) {
+		t.Errorf("error = %q, want %q", body.Error, want)
+	}
+	if hasAccepts := len(body.Accepts) > 0; hasAccepts != (rec.Code == http.StatusPaymentRequired) {
+		t.Errorf("accepts = %d requirements for status %d", len(body.Accepts), rec.Code)
+	}
+}
+
+func TestFacilitator_Behavior(t *testing.T) {
+	tests := []struct {
+		name       string
+		behavior   x402test.Behavior
+		wantStatus int
+		wantSettle int
+		wantCode   x402.ErrorCode
+	}{
+		{
+			"reject", x402test.Behavior{InvalidReason: "invalid_signature"},
+			http.StatusPaymentRequired, 0, x402.ErrCodeInvalidSignature,
+		},
+		{
+			"settle failure", x402test.Behavior{SettleErrorReason: "insufficient_funds"},
+			http.StatusPaymentRequired, 1, x402.ErrCodeInsufficientFunds,
+		},
+		{
+			"verify unavailable", x402test.Behavior{VerifyStatus: http.StatusBadGateway},
+			http.StatusServiceUnavailable, 0, x402.ErrCodeFacilitatorUnavailable,
+		},
+		{"latency", x402test.Behavior{Latency: 20 * time.Millisecond}, http.StatusOK, 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := x402test.NewFacilitator(t)
+			handler := newHandler(f, "base-sepolia", "0x209693Bc6afc0C5328bA36FaF03C514EF312287C")
+			f.SetBehavior(tt.behavior)
+
+			start := time.Now()
+			rec := pay(t, handler)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := f.Count(x402test.PathSettle); got != tt.wantSettle {
+				t.Errorf("settle calls = %d, want %d", got, tt.wantSettle)
+			}
+			if elapsed := time.Since(start); elapsed < tt.behavior.Latency {
+				t.Errorf("elapsed %v, want at least %v", elapsed, tt.behavior.Latency)
+			}
+			if tt.wantCode != "" {
+				checkErrorResponse(t, rec, tt.wantCode)
+			}
+		})
+	}
+}
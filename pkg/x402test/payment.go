@@ -0,0 +1,103 @@
+package x402test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	x402 "github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/internal/svm"
+	evmsigner "github.com/dexfra-fun/x402-go/pkg/signers/evm"
+	svmsigner "github.com/dexfra-fun/x402-go/pkg/signers/svm"
+	localx402 "github.com/dexfra-fun/x402-go/pkg/x402"
+)
+
+// blockhash is the recent blockhash used for Solana test payments.
+const blockhash = "EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N"
+
+// Payment signs a payment for requirement with a freshly generated key.
+// EVM payments carry a valid EIP-3009 signature and Solana payments a
+// partially signed TransferChecked transaction, so they pass local matching
+// and replay checks; only a real facilitator would reject them on-chain.
+func Payment(tb testing.TB, requirement x402.PaymentRequirement) x402.PaymentPayload {
+	tb.Helper()
+
+	chain, ok := x402.GetChainConfig(requirement.Network)
+	if !ok {
+		tb.Fatalf("x402test: unsupported network %q", requirement.Network)
+	}
+	token := x402.TokenConfig{Address: requirement.Asset, Symbol: "TOKEN", Decimals: int(chain.Decimals)}
+
+	var (
+		signer x402.Signer
+		err    error
+	)
+	if chain.IsEVM() {
+		key := make([]byte, 32) //nolint:mnd // secp256k1 private key length
+		_, _ = rand.Read(key)
+		signer, err = evmsigner.NewSigner(requirement.Network, key, evmsigner.WithToken(token))
+	} else {
+		_, key, _ := ed25519.GenerateKey(rand.Reader)
+		signer, err = svmsigner.NewSigner(requirement.Network, key,
+			svmsigner.WithToken(token),
+			svmsigner.WithBlockhashProvider(svmsigner.BlockhashFunc(func(context.Context) (string, error) {
+				return blockhash, nil
+			})),
+		)
+	}
+	if err != nil {
+		tb.Fatalf("x402test: create signer: %v", err)
+	}
+
+	payment, err := signer.Sign(context.Background(), &requirement)
+	if err != nil {
+		tb.Fatalf("x402test: sign payment: %v", err)
+	}
+	return *payment
+}
+
+// PaymentHeader returns an X-Payment header value for a payment signed by Payment.
+func PaymentHeader(tb testing.TB, requirement x402.PaymentRequirement) string {
+	tb.Helper()
+
+	header, err := localx402.EncodePaymentPayload(Payment(tb, requirement))
+	if err != nil {
+		tb.Fatalf("x402test: encode payment: %v", err)
+	}
+	return header
+}
+
+// PaymentRequirements decodes the accepts list of a 402 response body.
+func PaymentRequirements(tb testing.TB, body io.Reader) []x402.PaymentRequirement {
+	tb.Helper()
+
+	var response x402.PaymentRequirementsResponse
+	if err := sonic.ConfigDefault.NewDecoder(body).Decode(&response); err != nil {
+		tb.Fatalf("x402test: decode 402 response: %v", err)
+	}
+	if len(response.Accepts) == 0 {
+		tb.Fatal("x402test: 402 response has no accepted payments")
+	}
+	return response.Accepts
+}
+
+// payerOf returns the paying address of a payment, or DefaultPayer.
+func payerOf(payment x402.PaymentPayload) string {
+	if payload, err := payment.DecodeEVMPayload(); err == nil && payload.Authorization.From != "" {
+		return payload.Authorization.From
+	}
+	if payload, err := payment.DecodeSVMPayload(); err == nil {
+		raw, err := base64.StdEncoding.DecodeString(payload.Transaction)
+		var tx svm.Transaction
+		if err == nil && tx.UnmarshalBinary(raw) == nil {
+			if transfer, ok := tx.Message.FindTransferChecked(); ok {
+				return transfer.Owner.String()
+			}
+		}
+	}
+	return DefaultPayer
+}
@@ -0,0 +1,63 @@
+package x402
+
+import "strings"
+
+// reasonCodes maps facilitator invalidReason and errorReason values to error codes.
+var reasonCodes = map[string]ErrorCode{
+	"insufficient_funds":   ErrCodeInsufficientFunds,
+	"invalid_scheme":       ErrCodeUnsupportedScheme,
+	"unsupported_scheme":   ErrCodeUnsupportedScheme,
+	"invalid_network":      ErrCodeNetworkMismatch,
+	"network_mismatch":     ErrCodeNetworkMismatch,
+	"invalid_payload":      ErrCodeInvalidPayment,
+	"invalid_x402_version": ErrCodeInvalidPayment,
+
+	"invalid_exact_evm_payload_signature":                   ErrCodeInvalidSignature,
+	"invalid_exact_evm_payload_recipient_mismatch":          ErrCodeRecipientMismatch,
+	"invalid_exact_evm_payload_authorization_value":         ErrCodeAmountMismatch,
+	"invalid_exact_evm_payload_authorization_valid_after":   ErrCodeExpired,
+	"invalid_exact_evm_payload_authorization_valid_before":  ErrCodeExpired,
+	"invalid_exact_evm_payload_authorization_nonce_used":    ErrCodePaymentReplayed,
+	"invalid_exact_svm_payload_transaction":                 ErrCodeInvalidPayment,
+	"invalid_exact_svm_payload_transaction_instructions":    ErrCodeInvalidPayment,
+	"invalid_exact_svm_payload_transaction_signature":       ErrCodeInvalidSignature,
+	"invalid_exact_svm_payload_transaction_recipient":       ErrCodeRecipientMismatch,
+	"invalid_exact_svm_payload_transaction_amount":          ErrCodeAmountMismatch,
+	"invalid_exact_svm_payload_transaction_amount_mismatch": ErrCodeAmountMismatch,
+	"transaction_failed":                                    ErrCodeSettlementFailed,
+}
+
+// reasonKeywords maps fragments of unknown reasons to error codes, in order of precedence.
+var reasonKeywords = []struct {
+	keyword string
+	code    ErrorCode
+}{
+	{"insufficient", ErrCodeInsufficientFunds},
+	{"signature", ErrCodeInvalidSignature},
+	{"expired", ErrCodeExpired},
+	{"valid_before", ErrCodeExpired},
+	{"valid_after", ErrCodeExpired},
+	{"nonce", ErrCodePaymentReplayed},
+	{"recipient", ErrCodeRecipientMismatch},
+	{"amount", ErrCodeAmountMismatch},
+	{"value", ErrCodeAmountMismatch},
+	{"network", ErrCodeNetworkMismatch},
+	{"scheme", ErrCodeUnsupportedScheme},
+	{"payload", ErrCodeInvalidPayment},
+}
+
+// ErrorCodeForReason returns the error code for a facilitator reason such as
+// "insufficient_funds" or "invalid_exact_evm_payload_signature".
+// Unknown reasons are classified by keyword, falling back to fallback.
+func ErrorCodeForReason(reason string, fallback ErrorCode) ErrorCode {
+	normalized := strings.ToLower(strings.TrimSpace(reason))
+	if code, ok := reasonCodes[normalized]; ok {
+		return code
+	}
+	for _, k := range reasonKeywords {
+		if strings.Contains(normalized, k.keyword) {
+			return k.code
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,33 @@
+package x402
+
+import "testing"
+
+func TestErrorCodeForReason(t *testing.T) {
+	tests := []struct {
+		reason string
+		want   ErrorCode
+	}{
+		{"insufficient_funds", ErrCodeInsufficientFunds},
+		{"invalid_exact_evm_payload_signature", ErrCodeInvalidSignature},
+		{"invalid_exact_evm_payload_authorization_valid_before", ErrCodeExpired},
+		{"invalid_exact_svm_payload_transaction_recipient", ErrCodeRecipientMismatch},
+		{"invalid_exact_svm_payload_transaction_amount", ErrCodeAmountMismatch},
+		{"invalid_exact_evm_payload_authorization_nonce_used", ErrCodePaymentReplayed},
+		{"invalid_network", ErrCodeNetworkMismatch},
+		{" Insufficient_Funds ", ErrCodeInsufficientFunds},
+		{"invalid_signature", ErrCodeInvalidSignature},
+		{"payment_expired", ErrCodeExpired},
+		{"invalid_exact_svm_payload_transaction_fee_payer", ErrCodeInvalidPayment},
+		{"transaction_failed", ErrCodeSettlementFailed},
+		{"something else", ErrCodeVerificationFailed},
+		{"", ErrCodeVerificationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			if got := ErrorCodeForReason(tt.reason, ErrCodeVerificationFailed); got != tt.want {
+				t.Errorf("ErrorCodeForReason(%q) = %s, want %s", tt.reason, got, tt.want)
+			}
+		})
+	}
+}
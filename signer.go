@@ -0,0 +1,32 @@
+package x402
+
+import (
+	"context"
+	"math/big"
+)
+
+// Signer creates signed payment payloads for a single network and scheme.
+// Implementations live in the signers packages (e.g., pkg/signers/svm).
+type Signer interface {
+	// Network returns the x402 network identifier the signer pays on (e.g., "solana").
+	Network() string
+
+	// Scheme returns the payment scheme the signer produces (e.g., "exact").
+	Scheme() string
+
+	// CanSign reports whether the signer can satisfy the given requirement.
+	CanSign(requirement *PaymentRequirement) bool
+
+	// Sign creates a signed payment payload for the given requirement.
+	Sign(ctx context.Context, requirement *PaymentRequirement) (*PaymentPayload, error)
+
+	// GetPriority returns the signer's priority.
+	// Lower numbers indicate higher priority (1 > 2 > 3).
+	GetPriority() int
+
+	// GetTokens returns the tokens the signer can pay with.
+	GetTokens() []TokenConfig
+
+	// GetMaxAmount returns the per-call limit in atomic units, or nil if unlimited.
+	GetMaxAmount() *big.Int
+}
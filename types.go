@@ -1,7 +1,10 @@
 package x402
 
 import (
+	"fmt"
 	"math/big"
+
+	"github.com/bytedance/sonic"
 )
 
 const (
@@ -74,6 +77,53 @@ type PaymentPayload struct {
 	Payload any `json:"payload"`
 }
 
+// DecodeEVMPayload decodes the payload as an EVMPayload.
+// It accepts both typed payloads and the generic maps produced by JSON decoding.
+func (p PaymentPayload) DecodeEVMPayload() (*EVMPayload, error) {
+	var payload EVMPayload
+	if err := decodePayload(p.Payload, &payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
+
+// DecodeSVMPayload decodes the payload as an SVMPayload.
+// It accepts both typed payloads and the generic maps produced by JSON decoding.
+func (p PaymentPayload) DecodeSVMPayload() (*SVMPayload, error) {
+	var payload SVMPayload
+	if err := decodePayload(p.Payload, &payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
+
+// decodePayload converts a payload of any shape into out via JSON.
+func decodePayload(payload any, out any) error {
+	switch v := payload.(type) {
+	case nil:
+		return fmt.Errorf("%w: payload is empty", ErrMalformedHeader)
+	case EVMPayload:
+		if target, ok := out.(*EVMPayload); ok {
+			*target = v
+			return nil
+		}
+	case SVMPayload:
+		if target, ok := out.(*SVMPayload); ok {
+			*target = v
+			return nil
+		}
+	}
+
+	raw, err := sonic.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformedHeader, err)
+	}
+	if err := sonic.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformedHeader, err)
+	}
+	return nil
+}
+
 // TokenConfig represents configuration for a supported token.
 type TokenConfig struct {
 	// Address is the token contract address (EVM) or mint address (Solana).
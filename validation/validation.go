@@ -7,6 +7,7 @@ import (
 	"regexp"
 
 	"github.com/dexfra-fun/x402-go"
+	"github.com/dexfra-fun/x402-go/internal/evm"
 )
 
 var (
@@ -40,7 +41,9 @@ func ValidateAmount(amount string) error {
 	return nil
 }
 
-// ValidateAddress validates a Solana address format.
+// ValidateAddress validates an address format for the given network.
+// Solana addresses must be base58; EVM addresses must be 0x-prefixed hex
+// with a valid EIP-55 checksum when mixed-case.
 // It uses ValidateNetwork to confirm the network is supported.
 func ValidateAddress(address string, network string) error {
 	if address == "" {
@@ -51,15 +54,18 @@ func ValidateAddress(address string, network string) error {
 		return fmt.Errorf("cannot validate address: %w", err)
 	}
 
-	// Validate based on network (currently only Solana supported)
-	if network == "solana" || network == "solana-devnet" {
-		if !solanaAddressRegex.MatchString(address) {
-			return fmt.Errorf("invalid Solana address format: %s (expected base58 string 32-44 chars)", address)
+	chain, _ := x402.GetChainConfig(network)
+	if chain.IsEVM() {
+		if _, err := evm.ParseAddress(address); err != nil {
+			return fmt.Errorf("invalid EVM address %s: %w", address, err)
 		}
 		return nil
 	}
 
-	return fmt.Errorf("unsupported network for address validation: %s", network)
+	if !solanaAddressRegex.MatchString(address) {
+		return fmt.Errorf("invalid Solana address format: %s (expected base58 string 32-44 chars)", address)
+	}
+	return nil
 }
 
 // validateRequirementNetwork validates the network field of a payment requirement.
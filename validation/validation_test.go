@@ -0,0 +1,51 @@
+package validation
+
+import (
+	"testing"
+
+	x402 "github.com/dexfra-fun/x402-go"
+)
+
+func TestValidateAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		network string
+		wantErr bool
+	}{
+		{"solana address", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", "solana", false},
+		{"evm address on solana", "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913", "solana", true},
+		{"evm checksummed", "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913", "base", false},
+		{"evm lowercase", "0x833589fcd6edb6e08f4c7c32d4f71b54bda02913", "base", false},
+		{"evm bad checksum", "0x833589FCD6eDb6E08f4c7C32D4f71b54bdA02913", "base", true},
+		{"solana address on evm", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", "base", true},
+		{"unsupported network", "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913", "ethereum", true},
+		{"empty", "", "base", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAddress(tt.address, tt.network)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAddress_BuiltInChains(t *testing.T) {
+	chains := []x402.ChainConfig{
+		x402.SolanaMainnet, x402.SolanaDevnet,
+		x402.BaseMainnet, x402.BaseSepolia,
+		x402.PolygonMainnet, x402.PolygonAmoy,
+		x402.AvalancheMainnet, x402.AvalancheFuji,
+	}
+
+	for _, chain := range chains {
+		t.Run(chain.NetworkID, func(t *testing.T) {
+			if err := ValidateAddress(chain.USDCAddress, chain.NetworkID); err != nil {
+				t.Errorf("USDC address for %s is invalid: %v", chain.NetworkID, err)
+			}
+		})
+	}
+}